@@ -2,8 +2,11 @@ package analytics
 
 import (
 	"fmt"
+	"maps"
 	"math"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,48 +14,238 @@ import (
 	"github.com/burritocatai/activitycat/internal/github"
 )
 
-// Metrics holds computed analytics for a date range
+// Metrics holds computed analytics for a date range. In JSON, durations are
+// in nanoseconds.
 type Metrics struct {
 	// PR lifecycle
-	PRsOpened    int
-	PRsMerged    int
-	PRsClosed    int // closed without merge
-	PRsOpen      int
-	MergeRate    float64       // percentage of closed PRs that were merged
-	AvgMergeTime time.Duration // average time from creation to merge
-	MedMergeTime time.Duration // median time from creation to merge
+	PRsOpened    int           `json:"prsOpened"`
+	PRsMerged    int           `json:"prsMerged"`
+	PRsClosed    int           `json:"prsClosed"` // closed without merge
+	PRsOpen      int           `json:"prsOpen"`
+	MergeRate    float64       `json:"mergeRate"`    // percentage of closed PRs that were merged
+	AvgMergeTime time.Duration `json:"avgMergeTime"` // average time from creation to merge
+	MedMergeTime time.Duration `json:"medMergeTime"` // median time from creation to merge
+
+	// Merge time distribution, nil when no merged PR has a known merge time
+	MergeTimeBuckets []MergeTimeBucket `json:"mergeTimeBuckets,omitempty"`
+
+	// Merge times over Options.MergeTimeCap, clamped or excluded
+	MergeTimesCapped int `json:"mergeTimesCapped"`
+	mergeTimeCap     time.Duration
+	clampMergeTimes  bool
 
 	// Totals
-	TotalCommits        int
-	TotalReviews        int
-	TotalCommentedItems int
-	TotalIssuesClosed   int
+	TotalCommits        int `json:"totalCommits"`
+	TotalReviews        int `json:"totalReviews"`
+	TotalCommentedItems int `json:"totalCommentedItems"`
+	TotalIssuesClosed   int `json:"totalIssuesClosed"`
+	// TotalDiscussions is zero unless discussions were fetched
+	TotalDiscussions int `json:"totalDiscussions"`
+	// Lines added and deleted by the commits with stats, of which there are
+	// CommitsWithStats. Commits whose stats weren't looked up or whose repo
+	// couldn't be read are left out, and their repos are listed in
+	// CommitStatsMissingRepos.
+	CommitAdditions         int      `json:"commitAdditions"`
+	CommitDeletions         int      `json:"commitDeletions"`
+	CommitsWithStats        int      `json:"commitsWithStats"`
+	CommitStatsMissingRepos []string `json:"commitStatsMissingRepos,omitempty"`
+	// IssuesAssignedClosed counts the closed issues that were assigned to
+	// the user rather than authored, included in TotalIssuesClosed
+	IssuesAssignedClosed int `json:"issuesAssignedClosed"`
+
+	// Reviews by the outcome in their state. Reviews with no outcome, or
+	// anything else like DISMISSED, count as other.
+	ReviewsApproved         int `json:"reviewsApproved"`
+	ReviewsChangesRequested int `json:"reviewsChangesRequested"`
+	ReviewsCommented        int `json:"reviewsCommented"`
+	ReviewsOther            int `json:"reviewsOther"`
 
 	// Rates
-	PRsPerWeek     float64
-	CommitsPerDay  float64
-	ReviewsPerWeek float64
+	PRsPerWeek        float64 `json:"prsPerWeek"`
+	CommitsPerDay     float64 `json:"commitsPerDay"`
+	ReviewsPerWeek    float64 `json:"reviewsPerWeek"`
+	CommitsPerWorkday float64 `json:"commitsPerWorkday"`
+	PRsPerWorkday     float64 `json:"prsPerWorkday"`
 
-	// Repo breakdown
-	RepoStats []RepoStats
+	// Repo breakdown, all repos regardless of MinRepoActivity, in RepoSort order
+	RepoStats       []RepoStats `json:"repoStats,omitempty"`
+	RepoSort        string      `json:"repoSort"`
+	minRepoActivity int
+
+	// Per-member breakdown, nil unless the activity was fetched for a team
+	MemberStats []MemberStats `json:"memberStats,omitempty"`
+
+	// DailyActivity counts the activity on each of the last days of the
+	// range, oldest first, up to maxSparklineDays of them
+	DailyActivity []int `json:"dailyActivity,omitempty"`
 
 	// Most active day
-	MostActiveDay   string
-	MostActiveCount int
+	MostActiveDay   string `json:"mostActiveDay"`
+	MostActiveCount int    `json:"mostActiveCount"`
+
+	// Commits and PRs made on days off or outside working hours, and their
+	// share of all commits and PRs
+	OffHoursActivity int     `json:"offHoursActivity"`
+	OffHoursShare    float64 `json:"offHoursShare"`
+
+	// Most productive day, scoring each activity by Options.Weights
+	MostProductiveDay   string  `json:"mostProductiveDay"`
+	MostProductiveScore float64 `json:"mostProductiveScore"`
+
+	// Top PRs ranked by TopPRsBy, highest first, nil unless Options.TopPRs is set
+	TopPRs   []github.PullRequest `json:"topPRs,omitempty"`
+	TopPRsBy string               `json:"topPRsBy"`
+
+	// Trends compare the second half of the range against the first, nil
+	// for ranges shorter than two days
+	PRTrend     *Trend `json:"prTrend,omitempty"`
+	CommitTrend *Trend `json:"commitTrend,omitempty"`
+	ReviewTrend *Trend `json:"reviewTrend,omitempty"`
+
+	// Year-to-date comparison, nil unless CompareYTD was called
+	YTD *YTDShare `json:"ytd,omitempty"`
+
+	// Prior range comparison, nil unless ComparePrior was called
+	Prior *Comparison `json:"prior,omitempty"`
 
 	// Date range for rate calculations
-	days float64
+	days     float64
+	workdays int
+
+	// dateFormat is the layout Format shows days in, and repoAliases label
+	// the repositories shown
+	dateFormat  string
+	repoAliases map[string]string
+}
+
+// Options tunes how metrics are computed
+type Options struct {
+	// WorkingDays are the days counted for per-workday rates, Mon–Fri if empty
+	WorkingDays []time.Weekday
+	// MergeTimeCap, if set, excludes longer merge times from merge time
+	// stats, or clamps them to the cap if ClampMergeTimes is set
+	MergeTimeCap    time.Duration
+	ClampMergeTimes bool
+	// MinRepoActivity hides repos with less total activity from the
+	// breakdown; they still count towards totals
+	MinRepoActivity int
+	// Weights score each activity type for the most productive day,
+	// DefaultActivityWeights if nil
+	Weights *ActivityWeights
+	// RepoSort orders the repo breakdown, SortByTotal if empty
+	RepoSort string
+	// TopPRs is how many PRs to highlight, ranked by TopPRsBy
+	// (TopPRsByComments if empty)
+	TopPRs   int
+	TopPRsBy string
+	// WorkdayStart and WorkdayEnd bound working hours on working days, as
+	// offsets from midnight in Location (time.Local if nil), for the
+	// off-hours share. DefaultWorkdayStart to DefaultWorkdayEnd if both zero.
+	WorkdayStart time.Duration
+	WorkdayEnd   time.Duration
+	Location     *time.Location
+	// DateFormat is the Go layout days are shown in,
+	// daterange.DefaultDateFormat if empty
+	DateFormat string
+	// RepoAliases are labels repositories are shown by in place of
+	// "owner/name", see github.RepoLabel
+	RepoAliases map[string]string
+}
+
+// ActivityWeights scores each activity type when finding the most productive day
+type ActivityWeights struct {
+	PRs     float64
+	Issues  float64
+	Reviews float64
+	Commits float64
+}
+
+// DefaultActivityWeights counts every activity once
+var DefaultActivityWeights = ActivityWeights{PRs: 1, Issues: 1, Reviews: 1, Commits: 1}
+
+// defaultWorkingDays is the Monday to Friday working week
+var defaultWorkingDays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday,
 }
 
 // RepoStats groups all activity types for a single repository
 type RepoStats struct {
-	Repo           string
-	PRs            int
-	Issues         int
-	Reviews        int
-	Commits        int
-	CommentedItems int
-	Total          int
+	Repo           string `json:"repo"`
+	PRs            int    `json:"prs"`
+	Issues         int    `json:"issues"`
+	Reviews        int    `json:"reviews"`
+	Commits        int    `json:"commits"`
+	CommentedItems int    `json:"commentedItems"`
+	Total          int    `json:"total"`
+}
+
+// MergeTimeBucket counts merged PRs whose time to merge is below Max
+type MergeTimeBucket struct {
+	Label string        `json:"label"`
+	Max   time.Duration `json:"max"` // exclusive upper bound, zero for the last bucket
+	Count int           `json:"count"`
+}
+
+// mergeTimeBuckets are the histogram buckets, in ascending order
+var mergeTimeBuckets = []MergeTimeBucket{
+	{Label: "<1d", Max: 24 * time.Hour},
+	{Label: "1–3d", Max: 3 * 24 * time.Hour},
+	{Label: "3–7d", Max: 7 * 24 * time.Hour},
+	{Label: "1–2wk", Max: 14 * 24 * time.Hour},
+	{Label: ">2wk"},
+}
+
+// Trend direction values
+const (
+	TrendUp   = "up"
+	TrendDown = "down"
+	TrendFlat = "flat"
+)
+
+// Trend compares activity counts in the two halves of a range
+type Trend struct {
+	First     int     `json:"first"`
+	Second    int     `json:"second"`
+	Direction string  `json:"direction"` // TrendUp, TrendDown, or TrendFlat
+	Change    float64 `json:"change"`    // percent change from First to Second, 0 if First is zero
+}
+
+// newTrend builds a Trend from the counts for each half
+func newTrend(first, second int) *Trend {
+	t := &Trend{First: first, Second: second, Direction: TrendFlat}
+	switch {
+	case second > first:
+		t.Direction = TrendUp
+	case second < first:
+		t.Direction = TrendDown
+	}
+	if first > 0 {
+		t.Change = float64(second-first) / float64(first) * 100
+	}
+	return t
+}
+
+// String renders the trend as an arrow and percent change, e.g. "▲ 50%"
+func (t *Trend) String() string {
+	switch t.Direction {
+	case TrendUp:
+		if t.First == 0 {
+			return "▲ new"
+		}
+		return fmt.Sprintf("▲ %.0f%%", t.Change)
+	case TrendDown:
+		return fmt.Sprintf("▼ %.0f%%", -t.Change)
+	default:
+		return "– flat"
+	}
+}
+
+// YTDShare compares the range's activity against year-to-date totals
+type YTDShare struct {
+	PRs           int     `json:"prs"`
+	Commits       int     `json:"commits"`
+	PRPercent     float64 `json:"prPercent"`     // range PRs as a percentage of YTD PRs
+	CommitPercent float64 `json:"commitPercent"` // range commits as a percentage of YTD commits
 }
 
 // Compute calculates metrics from all fetched data
@@ -62,9 +255,11 @@ func Compute(
 	reviews []github.Review,
 	commits []github.Commit,
 	commentedItems []github.CommentedItem,
+	discussions []github.Discussion,
 	dr daterange.Range,
+	opts Options,
 ) *Metrics {
-	m := &Metrics{}
+	m := &Metrics{minRepoActivity: opts.MinRepoActivity, dateFormat: opts.DateFormat, repoAliases: opts.RepoAliases}
 
 	days := dr.End.Sub(dr.Start).Hours() / 24
 	if days < 1 {
@@ -100,6 +295,12 @@ func Compute(
 		m.MergeRate = float64(m.PRsMerged) / float64(resolved) * 100
 	}
 
+	if opts.MergeTimeCap > 0 {
+		m.mergeTimeCap = opts.MergeTimeCap
+		m.clampMergeTimes = opts.ClampMergeTimes
+		mergeTimes, m.MergeTimesCapped = capMergeTimes(mergeTimes, opts.MergeTimeCap, opts.ClampMergeTimes)
+	}
+
 	if len(mergeTimes) > 0 {
 		sort.Slice(mergeTimes, func(i, j int) bool { return mergeTimes[i] < mergeTimes[j] })
 
@@ -108,20 +309,79 @@ func Compute(
 			total += d
 		}
 		m.AvgMergeTime = total / time.Duration(len(mergeTimes))
-		m.MedMergeTime = mergeTimes[len(mergeTimes)/2]
+		m.MedMergeTime = median(mergeTimes)
+		m.MergeTimeBuckets = bucketMergeTimes(mergeTimes)
 	}
 
 	// Totals
 	m.TotalCommits = len(commits)
 	m.TotalReviews = len(reviews)
 	m.TotalCommentedItems = len(commentedItems)
+	m.TotalDiscussions = len(discussions)
+	m.sumCommitStats(commits)
 	m.TotalIssuesClosed = len(issues)
+	for _, issue := range issues {
+		if issue.Assigned {
+			m.IssuesAssignedClosed++
+		}
+	}
+	for _, r := range reviews {
+		switch r.Outcome() {
+		case github.ReviewApproved:
+			m.ReviewsApproved++
+		case github.ReviewChangesRequested:
+			m.ReviewsChangesRequested++
+		case github.ReviewCommented:
+			m.ReviewsCommented++
+		default:
+			m.ReviewsOther++
+		}
+	}
 
 	// Rates
 	m.PRsPerWeek = float64(len(prs)) / weeks
 	m.CommitsPerDay = float64(len(commits)) / days
 	m.ReviewsPerWeek = float64(len(reviews)) / weeks
 
+	workingDays := opts.WorkingDays
+	if len(workingDays) == 0 {
+		workingDays = defaultWorkingDays
+	}
+	m.workdays = countWorkdays(dr, workingDays)
+	if m.workdays > 0 {
+		m.CommitsPerWorkday = float64(len(commits)) / float64(m.workdays)
+		m.PRsPerWorkday = float64(len(prs)) / float64(m.workdays)
+	}
+
+	if off, total := offHours(prs, commits, workingDays, opts); total > 0 {
+		m.OffHoursActivity = off
+		m.OffHoursShare = float64(off) / float64(total)
+	}
+
+	// Trends need at least a day in each half to mean anything
+	if days >= 2 {
+		mid := dr.Start.Add(dr.End.Sub(dr.Start) / 2)
+		var prHalves, commitHalves, reviewHalves [2]int
+		half := func(t time.Time) int {
+			if t.Before(mid) {
+				return 0
+			}
+			return 1
+		}
+		for _, pr := range prs {
+			prHalves[half(pr.CreatedAt)]++
+		}
+		for _, c := range commits {
+			commitHalves[half(c.Commit.Author.Date)]++
+		}
+		for _, r := range reviews {
+			reviewHalves[half(r.CreatedAt)]++
+		}
+		m.PRTrend = newTrend(prHalves[0], prHalves[1])
+		m.CommitTrend = newTrend(commitHalves[0], commitHalves[1])
+		m.ReviewTrend = newTrend(reviewHalves[0], reviewHalves[1])
+	}
+
 	// Repo breakdown
 	repoMap := make(map[string]*RepoStats)
 	getRepo := func(name string) *RepoStats {
@@ -153,41 +413,186 @@ func Compute(
 		rs.Total = rs.PRs + rs.Issues + rs.Reviews + rs.Commits + rs.CommentedItems
 		m.RepoStats = append(m.RepoStats, *rs)
 	}
-	sort.Slice(m.RepoStats, func(i, j int) bool {
-		return m.RepoStats[i].Total > m.RepoStats[j].Total
-	})
+	m.SortRepoStats(opts.RepoSort)
 
-	// Most active day
+	m.MemberStats = memberStats(prs, issues, reviews, commits, commentedItems)
+
+	// Most active day by count, and most productive day by weighted score
+	weights := DefaultActivityWeights
+	if opts.Weights != nil {
+		weights = *opts.Weights
+	}
 	dayCount := make(map[string]int)
-	addDay := func(t time.Time) {
+	dayScore := make(map[string]float64)
+	addDay := func(t time.Time, weight float64) {
 		key := t.Format("2006-01-02")
 		dayCount[key]++
+		dayScore[key] += weight
 	}
 	for _, pr := range prs {
-		addDay(pr.CreatedAt)
+		addDay(pr.CreatedAt, weights.PRs)
 	}
 	for _, issue := range issues {
 		if issue.ClosedAt != nil {
-			addDay(*issue.ClosedAt)
+			addDay(*issue.ClosedAt, weights.Issues)
 		}
 	}
 	for _, r := range reviews {
-		addDay(r.CreatedAt)
+		addDay(r.CreatedAt, weights.Reviews)
 	}
 	for _, c := range commits {
-		addDay(c.Commit.Author.Date)
+		addDay(c.Commit.Author.Date, weights.Commits)
 	}
 
-	for day, count := range dayCount {
-		if count > m.MostActiveCount {
-			m.MostActiveDay = day
-			m.MostActiveCount = count
-		}
+	m.MostActiveDay, m.MostActiveCount = busiestDay(dayCount)
+	m.DailyActivity = recentDays(dayCount, dr, min(int(math.Ceil(days)), maxSparklineDays))
+	m.MostProductiveDay, m.MostProductiveScore = busiestDay(dayScore)
+
+	m.TopPRsBy = opts.TopPRsBy
+	if !ValidTopPRsBy(m.TopPRsBy) {
+		m.TopPRsBy = TopPRsByComments
 	}
+	m.TopPRs = topPRs(prs, opts.TopPRs, m.TopPRsBy)
 
 	return m
 }
 
+// maxSparklineDays is the most days DailyActivity covers, keeping the
+// sparkline short enough for the header
+const maxSparklineDays = 30
+
+// sparkBars are the sparkline characters, from lowest to highest
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// recentDays returns the counts for the n days ending on the range's last
+// day, oldest first
+func recentDays(dayCount map[string]int, dr daterange.Range, n int) []int {
+	counts := make([]int, n)
+	for i := range counts {
+		counts[i] = dayCount[dr.End.AddDate(0, 0, i-n+1).Format("2006-01-02")]
+	}
+	return counts
+}
+
+// Sparkline renders counts as a row of bars scaled to the largest, so the
+// largest is █ and zero is ▁
+func Sparkline(counts []int) string {
+	highest := 0
+	for _, c := range counts {
+		highest = max(highest, c)
+	}
+
+	bars := make([]rune, len(counts))
+	for i, c := range counts {
+		level := 0
+		if highest > 0 {
+			level = int(math.Round(float64(c) / float64(highest) * float64(len(sparkBars)-1)))
+		}
+		bars[i] = sparkBars[level]
+	}
+	return string(bars)
+}
+
+// busiestDay returns the day with the highest positive total, the earliest
+// on a tie, or "" if there is none
+func busiestDay[N int | float64](totals map[string]N) (string, N) {
+	var best string
+	var bestTotal N
+	for day, total := range totals {
+		if total > bestTotal || (total == bestTotal && total > 0 && day < best) {
+			best, bestTotal = day, total
+		}
+	}
+	return best, bestTotal
+}
+
+// Repo breakdown sort keys
+const (
+	SortByTotal   = "total"
+	SortByPRs     = "prs"
+	SortByCommits = "commits"
+	SortByReviews = "reviews"
+	SortByIssues  = "issues"
+)
+
+// RepoSortKeys are the repo breakdown sort keys, in the order they are cycled
+var RepoSortKeys = []string{SortByTotal, SortByPRs, SortByCommits, SortByReviews, SortByIssues}
+
+// ValidRepoSort reports whether key is a repo breakdown sort key
+func ValidRepoSort(key string) bool {
+	return slices.Contains(RepoSortKeys, key)
+}
+
+// NextRepoSort returns the sort key after key in RepoSortKeys, wrapping around
+func NextRepoSort(key string) string {
+	i := slices.Index(RepoSortKeys, key)
+	return RepoSortKeys[(i+1)%len(RepoSortKeys)]
+}
+
+// SortRepoStats orders the repo breakdown by key, highest first, with ties
+// broken by total activity and then name. An empty or unknown key sorts by
+// total.
+func (m *Metrics) SortRepoStats(key string) {
+	if !ValidRepoSort(key) {
+		key = SortByTotal
+	}
+	value := func(rs RepoStats) int {
+		switch key {
+		case SortByPRs:
+			return rs.PRs
+		case SortByCommits:
+			return rs.Commits
+		case SortByReviews:
+			return rs.Reviews
+		case SortByIssues:
+			return rs.Issues
+		}
+		return rs.Total
+	}
+
+	m.RepoSort = key
+	sort.Slice(m.RepoStats, func(i, j int) bool {
+		a, b := m.RepoStats[i], m.RepoStats[j]
+		if value(a) != value(b) {
+			return value(a) > value(b)
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Repo < b.Repo
+	})
+}
+
+// ShownRepoStats returns the repos with at least MinRepoActivity activity,
+// and how many repos were hidden
+func (m *Metrics) ShownRepoStats() ([]RepoStats, int) {
+	var shown []RepoStats
+	for _, rs := range m.RepoStats {
+		if rs.Total >= m.minRepoActivity {
+			shown = append(shown, rs)
+		}
+	}
+	return shown, len(m.RepoStats) - len(shown)
+}
+
+// sumCommitStats totals the lines changed by the commits with stats, noting
+// the repos of those without when only some have them
+func (m *Metrics) sumCommitStats(commits []github.Commit) {
+	missing := make(map[string]bool)
+	for _, c := range commits {
+		if c.Stats == nil {
+			missing[c.Repository.FullName] = true
+			continue
+		}
+		m.CommitAdditions += c.Stats.Additions
+		m.CommitDeletions += c.Stats.Deletions
+		m.CommitsWithStats++
+	}
+	if m.CommitsWithStats > 0 {
+		m.CommitStatsMissingRepos = slices.Sorted(maps.Keys(missing))
+	}
+}
+
 // Format returns a human-readable summary of the metrics
 func (m *Metrics) Format() string {
 	var sb strings.Builder
@@ -201,23 +606,263 @@ func (m *Metrics) Format() string {
 	sb.WriteString("\n")
 
 	if m.AvgMergeTime > 0 {
-		sb.WriteString(fmt.Sprintf("Time to merge: avg %s, median %s\n",
+		sb.WriteString(fmt.Sprintf("Time to merge: avg %s, median %s",
 			formatDuration(m.AvgMergeTime), formatDuration(m.MedMergeTime)))
+		if m.MergeTimesCapped > 0 {
+			verb := "excluded"
+			if m.clampMergeTimes {
+				verb = "capped"
+			}
+			sb.WriteString(fmt.Sprintf(" (%d over %s %s)", m.MergeTimesCapped, formatDuration(m.mergeTimeCap), verb))
+		}
+		sb.WriteString("\n")
+	}
+
+	issuesClosed := strconv.Itoa(m.TotalIssuesClosed)
+	if m.IssuesAssignedClosed > 0 {
+		issuesClosed += fmt.Sprintf(" (%d assigned)", m.IssuesAssignedClosed)
+	}
+	sb.WriteString(fmt.Sprintf("Commits: %d  |  Reviews: %d  |  Issues closed: %s  |  Commented on: %d",
+		m.TotalCommits, m.TotalReviews, issuesClosed, m.TotalCommentedItems))
+	if m.TotalDiscussions > 0 {
+		sb.WriteString(fmt.Sprintf("  |  Discussions: %d", m.TotalDiscussions))
 	}
+	sb.WriteString("\n")
 
-	sb.WriteString(fmt.Sprintf("Commits: %d  |  Reviews: %d  |  Issues closed: %d  |  Commented on: %d\n",
-		m.TotalCommits, m.TotalReviews, m.TotalIssuesClosed, m.TotalCommentedItems))
+	if m.CommitsWithStats > 0 {
+		sb.WriteString(fmt.Sprintf("Lines changed by commits: +%d/-%d, net %+d",
+			m.CommitAdditions, m.CommitDeletions, m.CommitAdditions-m.CommitDeletions))
+		if m.CommitsWithStats < m.TotalCommits {
+			missing := make([]string, len(m.CommitStatsMissingRepos))
+			for i, repo := range m.CommitStatsMissingRepos {
+				missing[i] = github.RepoLabel(m.repoAliases, repo)
+			}
+			sb.WriteString(fmt.Sprintf(" (partial: %d of %d commits; no stats for %s)",
+				m.CommitsWithStats, m.TotalCommits, strings.Join(missing, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Only worth a line when some reviews have an outcome
+	if m.ReviewsOther < m.TotalReviews {
+		sb.WriteString(fmt.Sprintf("Review outcomes: %d approved, %d changes requested, %d commented, %d other\n",
+			m.ReviewsApproved, m.ReviewsChangesRequested, m.ReviewsCommented, m.ReviewsOther))
+	}
 
 	sb.WriteString(fmt.Sprintf("Rates: %.1f PRs/wk, %.1f commits/day, %.1f reviews/wk\n",
 		m.PRsPerWeek, m.CommitsPerDay, m.ReviewsPerWeek))
 
+	if m.workdays > 0 {
+		sb.WriteString(fmt.Sprintf("Per workday (%d days): %.1f commits, %.1f PRs\n",
+			m.workdays, m.CommitsPerWorkday, m.PRsPerWorkday))
+	}
+
+	if m.PRTrend != nil {
+		sb.WriteString(fmt.Sprintf("Trend (2nd half vs 1st): PRs %s, commits %s, reviews %s\n",
+			m.PRTrend, m.CommitTrend, m.ReviewTrend))
+	}
+
+	if m.YTD != nil {
+		var parts []string
+		if m.YTD.PRs > 0 {
+			parts = append(parts, fmt.Sprintf("%.0f%% of your year-to-date PRs (%d)", m.YTD.PRPercent, m.YTD.PRs))
+		}
+		if m.YTD.Commits > 0 {
+			parts = append(parts, fmt.Sprintf("%.0f%% of your year-to-date commits (%d)", m.YTD.CommitPercent, m.YTD.Commits))
+		}
+		if len(parts) > 0 {
+			sb.WriteString(strings.Join(parts, "  |  ") + "\n")
+		}
+	}
+
+	if m.Prior != nil {
+		sb.WriteString(m.Prior.Format())
+	}
+
 	if m.MostActiveDay != "" {
-		sb.WriteString(fmt.Sprintf("Most active day: %s (%d activities)", m.MostActiveDay, m.MostActiveCount))
+		day := m.MostActiveDay
+		if t, err := time.Parse("2006-01-02", day); err == nil {
+			day = daterange.FormatDate(t, m.dateFormat)
+		}
+		sb.WriteString(fmt.Sprintf("Most active day: %s (%d activities)", day, m.MostActiveCount))
+	}
+	// Only worth showing when the weights pick a different day
+	if m.MostProductiveDay != "" && m.MostProductiveDay != m.MostActiveDay {
+		day := m.MostProductiveDay
+		if t, err := time.Parse("2006-01-02", day); err == nil {
+			day = daterange.FormatDate(t, m.dateFormat)
+		}
+		sb.WriteString(fmt.Sprintf("\nMost productive day: %s (score %g)", day, m.MostProductiveScore))
+	}
+	if m.OffHoursActivity > 0 {
+		sb.WriteString(fmt.Sprintf("\nOff-hours activity: %.0f%% of commits and PRs", m.OffHoursShare*100))
+	}
+
+	return sb.String()
+}
+
+// median returns the middle of sorted durations, or the average of the two
+// middle ones when there is an even number
+func median(sorted []time.Duration) time.Duration {
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return sorted[mid-1] + (sorted[mid]-sorted[mid-1])/2
+}
+
+// capMergeTimes drops merge times over limit, or clamps them to limit if
+// clamp is set, and returns the result with how many were over
+func capMergeTimes(mergeTimes []time.Duration, limit time.Duration, clamp bool) ([]time.Duration, int) {
+	var kept []time.Duration
+	over := 0
+	for _, d := range mergeTimes {
+		if d > limit {
+			over++
+			if !clamp {
+				continue
+			}
+			d = limit
+		}
+		kept = append(kept, d)
+	}
+	return kept, over
+}
+
+// bucketMergeTimes counts each merge time into the first bucket it fits
+func bucketMergeTimes(mergeTimes []time.Duration) []MergeTimeBucket {
+	buckets := make([]MergeTimeBucket, len(mergeTimeBuckets))
+	copy(buckets, mergeTimeBuckets)
+	for _, d := range mergeTimes {
+		for i := range buckets {
+			if buckets[i].Max == 0 || d < buckets[i].Max {
+				buckets[i].Count++
+				break
+			}
+		}
+	}
+	return buckets
+}
+
+// FormatMergeHistogram renders MergeTimeBuckets as an ASCII bar chart, or
+// returns "" if there are no merge times
+func (m *Metrics) FormatMergeHistogram() string {
+	maxCount := 0
+	for _, b := range m.MergeTimeBuckets {
+		maxCount = max(maxCount, b.Count)
+	}
+	if maxCount == 0 {
+		return ""
+	}
+
+	const barWidth = 20
+	var sb strings.Builder
+	sb.WriteString("Time to merge distribution:")
+	for _, b := range m.MergeTimeBuckets {
+		bar := int(math.Round(float64(b.Count) / float64(maxCount) * barWidth))
+		if b.Count > 0 && bar == 0 {
+			bar = 1
+		}
+		sb.WriteString(fmt.Sprintf("\n  %-6s %s %d", b.Label, strings.Repeat("█", bar), b.Count))
+	}
+	return sb.String()
+}
+
+// FormatSummary returns Format followed by the merge time histogram, top
+// PRs, and per-member breakdown where there are any, as shown in the
+// analytics box
+func (m *Metrics) FormatSummary() string {
+	summary := m.Format()
+	for _, section := range []string{m.FormatMergeHistogram(), m.FormatTopPRs(), m.FormatMemberStats()} {
+		if section != "" {
+			summary += "\n\n" + section
+		}
+	}
+	return summary
+}
+
+// FormatReport renders the metrics and repo breakdown as a Markdown report,
+// for when no narrative is wanted
+func (m *Metrics) FormatReport(dr daterange.Range) string {
+	return "# Activity Summary\n\n" + dr.Format(m.dateFormat) + "\n\n" + m.FormatMarkdown()
+}
+
+// FormatMarkdown renders the analytics box as a code block followed by the
+// repo and team breakdowns as Markdown tables
+func (m *Metrics) FormatMarkdown() string {
+	var sb strings.Builder
+
+	sb.WriteString("```\n")
+	sb.WriteString(m.Format())
+	if histogram := m.FormatMergeHistogram(); histogram != "" {
+		sb.WriteString("\n\n" + histogram)
+	}
+	if top := m.FormatTopPRs(); top != "" {
+		sb.WriteString("\n\n" + top)
+	}
+	sb.WriteString("\n```\n")
+
+	shown, hidden := m.ShownRepoStats()
+	if len(shown) > 0 {
+		sb.WriteString("\n## Repository Breakdown\n\n")
+		sb.WriteString("| Repository | PRs | Issues | Reviews | Commits | Comments | Total |\n")
+		sb.WriteString("|---|---:|---:|---:|---:|---:|---:|\n")
+		for _, rs := range shown {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %d | %d |\n",
+				github.RepoLabel(m.repoAliases, rs.Repo), rs.PRs, rs.Issues, rs.Reviews, rs.Commits, rs.CommentedItems, rs.Total))
+		}
+	}
+	if hidden > 0 {
+		sb.WriteString(fmt.Sprintf("\n+%d more repos with less activity\n", hidden))
+	}
+
+	if len(m.MemberStats) > 0 {
+		sb.WriteString("\n## Team Breakdown\n\n")
+		sb.WriteString("| Member | PRs | Issues | Reviews | Commits | Comments | Total |\n")
+		sb.WriteString("|---|---:|---:|---:|---:|---:|---:|\n")
+		for _, ms := range m.MemberStats {
+			sb.WriteString(fmt.Sprintf("| @%s | %d | %d | %d | %d | %d | %d |\n",
+				ms.Member, ms.PRs, ms.Issues, ms.Reviews, ms.Commits, ms.CommentedItems, ms.Total))
+		}
 	}
 
 	return sb.String()
 }
 
+// countWorkdays counts the days in [dr.Start, dr.End) that fall on a working day
+func countWorkdays(dr daterange.Range, workingDays []time.Weekday) int {
+	working := make(map[time.Weekday]bool)
+	for _, d := range workingDays {
+		working[d] = true
+	}
+
+	count := 0
+	for t := dr.Start; t.Before(dr.End); t = t.AddDate(0, 0, 1) {
+		if working[t.Weekday()] {
+			count++
+		}
+	}
+	return count
+}
+
+// CompareYTD records the range's share of the given year-to-date totals
+func (m *Metrics) CompareYTD(ytdPRs, ytdCommits int) {
+	m.YTD = &YTDShare{
+		PRs:           ytdPRs,
+		Commits:       ytdCommits,
+		PRPercent:     percentOf(m.PRsOpened, ytdPRs),
+		CommitPercent: percentOf(m.TotalCommits, ytdCommits),
+	}
+}
+
+// percentOf returns part as a percentage of whole, or 0 if whole is zero
+func percentOf(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole) * 100
+}
+
 func formatDuration(d time.Duration) string {
 	hours := d.Hours()
 	if hours < 1 {
@@ -0,0 +1,463 @@
+package analytics
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/github"
+)
+
+func TestCompareYTD(t *testing.T) {
+	m := &Metrics{PRsOpened: 5, TotalCommits: 30}
+	m.CompareYTD(20, 120)
+
+	if m.YTD.PRPercent != 25 {
+		t.Errorf("PRPercent = %v, want 25", m.YTD.PRPercent)
+	}
+	if m.YTD.CommitPercent != 25 {
+		t.Errorf("CommitPercent = %v, want 25", m.YTD.CommitPercent)
+	}
+	if out := m.Format(); !strings.Contains(out, "25% of your year-to-date PRs (20)") {
+		t.Errorf("Format() is missing the YTD PR share:\n%s", out)
+	}
+}
+
+func TestCompareYTDZero(t *testing.T) {
+	m := &Metrics{PRsOpened: 0, TotalCommits: 0}
+	m.CompareYTD(0, 0)
+
+	if m.YTD.PRPercent != 0 || m.YTD.CommitPercent != 0 {
+		t.Errorf("percentages with zero YTD totals = %v, %v, want 0, 0", m.YTD.PRPercent, m.YTD.CommitPercent)
+	}
+	if out := m.Format(); strings.Contains(out, "year-to-date") {
+		t.Errorf("Format() shows a YTD share with zero YTD totals:\n%s", out)
+	}
+}
+
+func TestWorkdaysAcrossTwoWeekends(t *testing.T) {
+	// Friday the 7th to Sunday the 16th, with the weekends of the 8th and 15th
+	dr := daterange.Range{
+		Start: time.Date(2025, time.March, 7, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, time.March, 16, 0, 0, 0, 0, time.UTC),
+	}
+	commits := make([]github.Commit, 12)
+	for i := range commits {
+		commits[i].Commit.Author.Date = dr.Start.Add(time.Duration(i) * time.Hour)
+	}
+
+	m := Compute(nil, nil, nil, commits, nil, nil, dr, Options{})
+	if m.workdays != 6 {
+		t.Errorf("workdays = %d, want 6", m.workdays)
+	}
+	if m.CommitsPerWorkday != 2 {
+		t.Errorf("CommitsPerWorkday = %v, want 2", m.CommitsPerWorkday)
+	}
+
+	sunToThu := []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday}
+	m = Compute(nil, nil, nil, commits, nil, nil, dr, Options{WorkingDays: sunToThu})
+	if m.workdays != 5 {
+		t.Errorf("workdays for a Sunday to Thursday week = %d, want 5", m.workdays)
+	}
+	if m.CommitsPerWorkday != 2.4 {
+		t.Errorf("CommitsPerWorkday for a Sunday to Thursday week = %v, want 2.4", m.CommitsPerWorkday)
+	}
+}
+
+func TestBucketMergeTimes(t *testing.T) {
+	day := 24 * time.Hour
+	buckets := bucketMergeTimes([]time.Duration{
+		time.Hour, 23 * time.Hour, // <1d
+		day, 2 * day, // 1–3d, a full day is no longer under one
+		3 * day, 6 * day, 6*day + 23*time.Hour, // 3–7d
+		7 * day,            // 1–2wk
+		14 * day, 90 * day, // >2wk
+	})
+
+	want := map[string]int{"<1d": 2, "1–3d": 2, "3–7d": 3, "1–2wk": 1, ">2wk": 2}
+	if len(buckets) != len(want) {
+		t.Fatalf("got %d buckets, want %d", len(buckets), len(want))
+	}
+	for _, b := range buckets {
+		if b.Count != want[b.Label] {
+			t.Errorf("bucket %s has %d merge times, want %d", b.Label, b.Count, want[b.Label])
+		}
+	}
+}
+
+func TestMergeHistogramEmpty(t *testing.T) {
+	m := &Metrics{MergeTimeBuckets: bucketMergeTimes(nil)}
+	if out := m.FormatMergeHistogram(); out != "" {
+		t.Errorf("FormatMergeHistogram() with no merge times = %q, want \"\"", out)
+	}
+
+	m = &Metrics{MergeTimeBuckets: bucketMergeTimes([]time.Duration{time.Hour, 2 * time.Hour, 48 * time.Hour})}
+	out := m.FormatMergeHistogram()
+	if !strings.Contains(out, "<1d    "+strings.Repeat("█", 20)+" 2") || !strings.Contains(out, "1–3d   "+strings.Repeat("█", 10)+" 1") {
+		t.Errorf("histogram bars are off:\n%s", out)
+	}
+}
+
+// date returns midnight UTC on the given day
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestTrends(t *testing.T) {
+	// March 1st to 28th splits at noon on the 14th
+	dr := daterange.Range{Start: date(2025, time.March, 1), End: date(2025, time.March, 28)}
+	prsOn := func(days ...int) []github.PullRequest {
+		var prs []github.PullRequest
+		for _, d := range days {
+			prs = append(prs, github.PullRequest{State: "open", CreatedAt: date(2025, time.March, d)})
+		}
+		return prs
+	}
+
+	tests := []struct {
+		name      string
+		days      []int
+		direction string
+		change    float64
+		rendered  string
+	}{
+		{"increasing", []int{3, 20, 21, 22}, TrendUp, 200, "▲ 200%"},
+		{"decreasing", []int{2, 3, 4, 5, 25}, TrendDown, -75, "▼ 75%"},
+		{"flat", []int{5, 6, 20, 27}, TrendFlat, 0, "– flat"},
+		{"new", []int{20}, TrendUp, 0, "▲ new"},
+	}
+	for _, tt := range tests {
+		m := Compute(prsOn(tt.days...), nil, nil, nil, nil, nil, dr, Options{})
+		if m.PRTrend == nil {
+			t.Fatalf("%s: no PR trend", tt.name)
+		}
+		if m.PRTrend.Direction != tt.direction || m.PRTrend.Change != tt.change {
+			t.Errorf("%s: trend = %s %v%%, want %s %v%%", tt.name, m.PRTrend.Direction, m.PRTrend.Change, tt.direction, tt.change)
+		}
+		if got := m.PRTrend.String(); got != tt.rendered {
+			t.Errorf("%s: String() = %q, want %q", tt.name, got, tt.rendered)
+		}
+		if !strings.Contains(m.Format(), "PRs "+tt.rendered) {
+			t.Errorf("%s: Format() is missing the trend:\n%s", tt.name, m.Format())
+		}
+	}
+}
+
+func TestTrendsOmittedForShortRanges(t *testing.T) {
+	day := date(2025, time.March, 3)
+	m := Compute(nil, nil, nil, nil, nil, nil, daterange.Range{Start: day, End: day}, Options{})
+	if m.PRTrend != nil || m.CommitTrend != nil || m.ReviewTrend != nil {
+		t.Error("a one-day range has trends")
+	}
+	if strings.Contains(m.Format(), "Trend") {
+		t.Errorf("Format() shows trends for a one-day range:\n%s", m.Format())
+	}
+}
+
+func TestMergeTimeCap(t *testing.T) {
+	day := 24 * time.Hour
+	created := date(2025, time.January, 1)
+	var prs []github.PullRequest
+	for _, d := range []time.Duration{day, 3 * day, 200 * day} {
+		merged := created.Add(d)
+		prs = append(prs, github.PullRequest{State: "merged", CreatedAt: created, MergedAt: &merged})
+	}
+	dr := daterange.Range{Start: created, End: date(2025, time.December, 31)}
+
+	tests := []struct {
+		name      string
+		opts      Options
+		wantAvg   time.Duration
+		wantCount int
+		note      string
+	}{
+		{"untouched", Options{}, 68 * day, 0, ""},
+		{"excluded", Options{MergeTimeCap: 10 * day}, 2 * day, 1, "(1 over 10d excluded)"},
+		{"clamped", Options{MergeTimeCap: 10 * day, ClampMergeTimes: true}, 14 * day / 3, 1, "(1 over 10d capped)"},
+	}
+	for _, tt := range tests {
+		m := Compute(prs, nil, nil, nil, nil, nil, dr, tt.opts)
+		if m.AvgMergeTime != tt.wantAvg {
+			t.Errorf("%s: AvgMergeTime = %v, want %v", tt.name, m.AvgMergeTime, tt.wantAvg)
+		}
+		if m.MergeTimesCapped != tt.wantCount {
+			t.Errorf("%s: MergeTimesCapped = %d, want %d", tt.name, m.MergeTimesCapped, tt.wantCount)
+		}
+		if out := m.Format(); tt.note != "" && !strings.Contains(out, tt.note) {
+			t.Errorf("%s: Format() is missing %q:\n%s", tt.name, tt.note, out)
+		}
+	}
+}
+
+func TestMedianMergeTime(t *testing.T) {
+	created := date(2025, time.January, 1)
+	dr := daterange.Range{Start: created, End: date(2025, time.December, 31)}
+	tests := []struct {
+		hours []time.Duration
+		want  time.Duration
+	}{
+		{[]time.Duration{1, 4}, 150 * time.Minute},
+		{[]time.Duration{8, 1, 2, 5}, 210 * time.Minute},
+		{[]time.Duration{8, 1, 2, 5, 3}, 3 * time.Hour},
+	}
+	for _, tt := range tests {
+		var prs []github.PullRequest
+		for _, h := range tt.hours {
+			merged := created.Add(h * time.Hour)
+			prs = append(prs, github.PullRequest{State: "merged", CreatedAt: created, MergedAt: &merged})
+		}
+		if got := Compute(prs, nil, nil, nil, nil, nil, dr, Options{}).MedMergeTime; got != tt.want {
+			t.Errorf("MedMergeTime of %d merges = %v, want %v", len(prs), got, tt.want)
+		}
+	}
+}
+
+func TestNullAuthorCounts(t *testing.T) {
+	created := date(2025, time.March, 3)
+	dr := daterange.Range{Start: date(2025, time.March, 1), End: date(2025, time.March, 31)}
+	repo := github.Repository{Name: "web", NameWithOwner: "acme/web"}
+	prs := []github.PullRequest{
+		{Number: 1, State: "open", CreatedAt: created, Repository: repo},
+		{Number: 2, State: "open", CreatedAt: created, Repository: repo, Author: github.Author{Login: "octocat"}},
+	}
+
+	m := Compute(prs, nil, nil, nil, nil, nil, dr, Options{})
+	if m.PRsOpened != 2 {
+		t.Errorf("PRsOpened = %d, want 2 with the authorless PR", m.PRsOpened)
+	}
+	if len(m.RepoStats) != 1 || m.RepoStats[0].PRs != 2 {
+		t.Errorf("RepoStats = %+v, want both PRs in acme/web", m.RepoStats)
+	}
+}
+
+func TestReviewOutcomes(t *testing.T) {
+	dr := daterange.Range{Start: date(2025, time.March, 1), End: date(2025, time.March, 31)}
+	var reviews []github.Review
+	for _, state := range []string{"APPROVED", "approved", "CHANGES_REQUESTED", "COMMENTED", "COMMENTED", "COMMENTED", "DISMISSED", ""} {
+		reviews = append(reviews, github.Review{State: state, CreatedAt: date(2025, time.March, 3)})
+	}
+
+	m := Compute(nil, nil, reviews, nil, nil, nil, dr, Options{})
+	if m.ReviewsApproved != 2 || m.ReviewsChangesRequested != 1 || m.ReviewsCommented != 3 || m.ReviewsOther != 2 {
+		t.Errorf("outcomes = %d approved, %d changes requested, %d commented, %d other, want 2, 1, 3, 2",
+			m.ReviewsApproved, m.ReviewsChangesRequested, m.ReviewsCommented, m.ReviewsOther)
+	}
+	if out := m.Format(); !strings.Contains(out, "Review outcomes: 2 approved, 1 changes requested, 3 commented, 2 other") {
+		t.Errorf("Format() is missing the review outcomes:\n%s", out)
+	}
+	if got, want := github.ReviewOutcomes(reviews), "2 approved, 1 changes requested, 3 commented, 2 other"; got != want {
+		t.Errorf("ReviewOutcomes() = %q, want %q", got, want)
+	}
+}
+
+func TestIssuesAssignedClosed(t *testing.T) {
+	dr := daterange.Range{Start: date(2025, time.March, 1), End: date(2025, time.March, 31)}
+	issues := []github.Issue{{Number: 1}, {Number: 2}, {Number: 3, Assigned: true}}
+
+	m := Compute(nil, issues, nil, nil, nil, nil, dr, Options{})
+	if m.TotalIssuesClosed != 3 || m.IssuesAssignedClosed != 1 {
+		t.Errorf("TotalIssuesClosed = %d, IssuesAssignedClosed = %d, want 3 and 1", m.TotalIssuesClosed, m.IssuesAssignedClosed)
+	}
+	if out := m.Format(); !strings.Contains(out, "(1 assigned)") {
+		t.Errorf("Format() is missing the assigned count:\n%s", out)
+	}
+}
+
+func TestMinRepoActivity(t *testing.T) {
+	dr := daterange.Range{Start: date(2025, time.March, 1), End: date(2025, time.March, 31)}
+	day := date(2025, time.March, 3)
+	commit := func(repo string) github.Commit {
+		var c github.Commit
+		c.Repository.FullName = repo
+		c.Commit.Author.Date = day
+		return c
+	}
+	commits := []github.Commit{commit("acme/web"), commit("acme/web"), commit("acme/web"), commit("acme/docs"), commit("other/tool")}
+
+	m := Compute(nil, nil, nil, commits, nil, nil, dr, Options{MinRepoActivity: 2})
+
+	shown, hidden := m.ShownRepoStats()
+	if len(shown) != 1 || shown[0].Repo != "acme/web" {
+		t.Errorf("shown repos = %+v, want only acme/web", shown)
+	}
+	if hidden != 2 {
+		t.Errorf("hidden = %d, want 2", hidden)
+	}
+	if len(m.RepoStats) != 3 {
+		t.Errorf("RepoStats has %d repos, want all 3", len(m.RepoStats))
+	}
+	if m.TotalCommits != 5 {
+		t.Errorf("TotalCommits = %d, want 5 including the hidden repos", m.TotalCommits)
+	}
+	report := m.FormatReport(dr)
+	if !strings.Contains(report, "+2 more repos with less activity") || strings.Contains(report, "acme/docs") {
+		t.Errorf("FormatReport() should list acme/web and summarize the other two:\n%s", report)
+	}
+
+	// The default threshold of 1 shows every repo
+	if shown, hidden := Compute(nil, nil, nil, commits, nil, nil, dr, Options{MinRepoActivity: 1}).ShownRepoStats(); len(shown) != 3 || hidden != 0 {
+		t.Errorf("with a threshold of 1, %d repos shown and %d hidden, want 3 and 0", len(shown), hidden)
+	}
+}
+
+func TestExcludedForkRepoStats(t *testing.T) {
+	dr := daterange.Range{Start: date(2025, time.March, 1), End: date(2025, time.March, 31)}
+	day := date(2025, time.March, 3)
+	msg := github.ActivityLoadedMsg{PRs: []github.PullRequest{
+		{Number: 1, State: "open", CreatedAt: day, Repository: github.Repository{Name: "web", NameWithOwner: "acme/web"}},
+		{Number: 2, State: "open", CreatedAt: day, Repository: github.Repository{Name: "web", NameWithOwner: "me/web"}},
+	}}
+
+	// excludeRepos drops the repos the lookup reports as forks
+	msg.ExcludeRepos(map[string]bool{"me/web": true})
+	m := Compute(msg.PRs, nil, nil, nil, nil, nil, dr, Options{})
+
+	if len(m.RepoStats) != 1 || m.RepoStats[0].Repo != "acme/web" {
+		t.Errorf("RepoStats = %+v, want only acme/web", m.RepoStats)
+	}
+	if m.PRsOpened != 1 {
+		t.Errorf("PRsOpened = %d, want 1 without the fork", m.PRsOpened)
+	}
+}
+
+func TestWeightedMostProductiveDay(t *testing.T) {
+	dr := daterange.Range{Start: date(2025, time.March, 1), End: date(2025, time.March, 31)}
+	// Five commits on the 3rd, one merged PR and a review on the 4th
+	busy, important := date(2025, time.March, 3), date(2025, time.March, 4)
+	var commits []github.Commit
+	for range 5 {
+		var c github.Commit
+		c.Commit.Author.Date = busy
+		c.Repository.FullName = "acme/web"
+		commits = append(commits, c)
+	}
+	prs := []github.PullRequest{{Number: 1, State: "merged", CreatedAt: important, Repository: github.Repository{NameWithOwner: "acme/web"}}}
+	reviews := []github.Review{{Number: 2, CreatedAt: important, Repository: github.Repository{NameWithOwner: "acme/api"}}}
+
+	unweighted := Compute(prs, nil, reviews, commits, nil, nil, dr, Options{})
+	if unweighted.MostActiveDay != "2025-03-03" || unweighted.MostActiveCount != 5 {
+		t.Errorf("most active day = %s (%d), want 2025-03-03 (5)", unweighted.MostActiveDay, unweighted.MostActiveCount)
+	}
+	if unweighted.MostProductiveDay != unweighted.MostActiveDay {
+		t.Errorf("with default weights, most productive day = %s, want the most active day %s", unweighted.MostProductiveDay, unweighted.MostActiveDay)
+	}
+
+	weights := ActivityWeights{PRs: 5, Issues: 1, Reviews: 2, Commits: 0.5}
+	weighted := Compute(prs, nil, reviews, commits, nil, nil, dr, Options{Weights: &weights})
+	if weighted.MostProductiveDay != "2025-03-04" || weighted.MostProductiveScore != 7 {
+		t.Errorf("most productive day = %s (%v), want 2025-03-04 (7)", weighted.MostProductiveDay, weighted.MostProductiveScore)
+	}
+	// Weights don't change the raw count
+	if weighted.MostActiveDay != "2025-03-03" {
+		t.Errorf("weighted most active day = %s, want 2025-03-03", weighted.MostActiveDay)
+	}
+}
+
+func TestSortRepoStats(t *testing.T) {
+	stats := []RepoStats{
+		{Repo: "acme/web", PRs: 1, Commits: 9, Reviews: 0, Issues: 2, Total: 12},
+		{Repo: "acme/api", PRs: 4, Commits: 1, Reviews: 2, Issues: 0, Total: 7},
+		{Repo: "acme/docs", PRs: 0, Commits: 2, Reviews: 5, Issues: 1, Total: 8},
+		{Repo: "acme/cli", PRs: 0, Commits: 0, Reviews: 0, Issues: 3, Total: 3},
+	}
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{SortByTotal, []string{"acme/web", "acme/docs", "acme/api", "acme/cli"}},
+		{SortByPRs, []string{"acme/api", "acme/web", "acme/docs", "acme/cli"}},
+		{SortByCommits, []string{"acme/web", "acme/docs", "acme/api", "acme/cli"}},
+		{SortByReviews, []string{"acme/docs", "acme/api", "acme/web", "acme/cli"}},
+		{SortByIssues, []string{"acme/cli", "acme/web", "acme/docs", "acme/api"}},
+		{"", []string{"acme/web", "acme/docs", "acme/api", "acme/cli"}},
+		{"unknown", []string{"acme/web", "acme/docs", "acme/api", "acme/cli"}},
+	}
+	for _, tt := range tests {
+		m := &Metrics{RepoStats: append([]RepoStats(nil), stats...)}
+		m.SortRepoStats(tt.key)
+		var got []string
+		for _, rs := range m.RepoStats {
+			got = append(got, rs.Repo)
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("sorted by %q = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSumCommitStatsWithMissingStats(t *testing.T) {
+	dr := daterange.Range{Start: date(2025, time.March, 1), End: date(2025, time.March, 31)}
+	commit := func(repo string, stats *github.CommitStats) github.Commit {
+		var c github.Commit
+		c.Repository.FullName = repo
+		c.Commit.Author.Date = date(2025, time.March, 3)
+		c.Stats = stats
+		return c
+	}
+	commits := []github.Commit{
+		commit("acme/web", &github.CommitStats{Additions: 10, Deletions: 4}),
+		commit("acme/web", &github.CommitStats{Additions: 5, Deletions: 1}),
+		commit("acme/secret", nil),
+	}
+
+	m := Compute(nil, nil, nil, commits, nil, nil, dr, Options{})
+	if m.CommitAdditions != 15 || m.CommitDeletions != 5 || m.CommitsWithStats != 2 {
+		t.Errorf("commit stats = +%d/-%d over %d commits, want +15/-5 over 2", m.CommitAdditions, m.CommitDeletions, m.CommitsWithStats)
+	}
+	if len(m.CommitStatsMissingRepos) != 1 || m.CommitStatsMissingRepos[0] != "acme/secret" {
+		t.Errorf("CommitStatsMissingRepos = %v, want [acme/secret]", m.CommitStatsMissingRepos)
+	}
+	if out := m.Format(); !strings.Contains(out, "+15/-5, net +10 (partial: 2 of 3 commits; no stats for acme/secret)") {
+		t.Errorf("Format() is missing the partial line totals:\n%s", out)
+	}
+
+	// Without any stats, nothing is reported missing
+	m = Compute(nil, nil, nil, commits[2:], nil, nil, dr, Options{})
+	if m.CommitsWithStats != 0 || m.CommitStatsMissingRepos != nil {
+		t.Errorf("with no stats, %d commits with stats and missing %v, want 0 and none", m.CommitsWithStats, m.CommitStatsMissingRepos)
+	}
+	if out := m.Format(); strings.Contains(out, "Lines changed") {
+		t.Errorf("Format() shows line totals with no stats:\n%s", out)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		counts []int
+		want   string
+	}{
+		{[]int{0, 0, 0}, "▁▁▁"},
+		{[]int{0, 7}, "▁█"},
+		{[]int{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+		{[]int{2, 4}, "▅█"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := Sparkline(tt.counts); got != tt.want {
+			t.Errorf("Sparkline(%v) = %q, want %q", tt.counts, got, tt.want)
+		}
+	}
+}
+
+func TestDailyActivity(t *testing.T) {
+	repo := github.Repository{Name: "web", NameWithOwner: "acme/web"}
+	pr := func(day int) github.PullRequest {
+		return github.PullRequest{State: "open", CreatedAt: date(2025, time.March, day).Add(10 * time.Hour), Repository: repo}
+	}
+	prs := []github.PullRequest{pr(1), pr(3), pr(3), pr(5)}
+
+	dr := daterange.Range{Start: date(2025, time.March, 1), End: date(2025, time.March, 5)}
+	m := Compute(prs, nil, nil, nil, nil, nil, dr, Options{})
+	if want := []int{0, 2, 0, 1}; !slices.Equal(m.DailyActivity, want) {
+		t.Errorf("DailyActivity = %v, want %v for the 2nd to the 5th", m.DailyActivity, want)
+	}
+
+	// Long ranges keep only the most recent days
+	dr = daterange.Range{Start: date(2025, time.January, 1), End: date(2025, time.March, 5)}
+	m = Compute(prs, nil, nil, nil, nil, nil, dr, Options{})
+	if len(m.DailyActivity) != maxSparklineDays || m.DailyActivity[maxSparklineDays-1] != 1 {
+		t.Errorf("DailyActivity = %v, want %d days ending with the 5th", m.DailyActivity, maxSparklineDays)
+	}
+}
@@ -0,0 +1,56 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// badgeFont is the font stack used for all badge text
+const badgeFont = "-apple-system, Segoe UI, Helvetica, Arial, sans-serif"
+
+// badgeColors are the fill colors for one badge variant
+type badgeColors struct {
+	background string
+	border     string
+	title      string
+	label      string
+	value      string
+}
+
+var (
+	lightBadge = badgeColors{background: "#ffffff", border: "#d0d7de", title: "#1f2328", label: "#656d76", value: "#8250df"}
+	darkBadge  = badgeColors{background: "#0d1117", border: "#30363d", title: "#e6edf3", label: "#8b949e", value: "#ff5fd2"}
+)
+
+// Badge renders PRs merged, commits, and merge rate as a small SVG card for
+// embedding in a README. dark selects colors for dark backgrounds.
+func (m *Metrics) Badge(dark bool) string {
+	c := lightBadge
+	if dark {
+		c = darkBadge
+	}
+
+	mergeRate := "–"
+	if m.PRsMerged+m.PRsClosed > 0 {
+		mergeRate = fmt.Sprintf("%.0f%%", m.MergeRate)
+	}
+
+	stats := []struct{ label, value string }{
+		{"PRs merged", fmt.Sprintf("%d", m.PRsMerged)},
+		{"Commits", fmt.Sprintf("%d", m.TotalCommits)},
+		{"Merge rate", mergeRate},
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="360" height="110" viewBox="0 0 360 110" role="img" aria-label="GitHub activity">` + "\n")
+	sb.WriteString(fmt.Sprintf(`  <rect x="0.5" y="0.5" width="359" height="109" rx="6" fill="%s" stroke="%s"/>`+"\n", c.background, c.border))
+	sb.WriteString(fmt.Sprintf(`  <text x="20" y="30" font-family="%s" font-size="14" font-weight="600" fill="%s">GitHub Activity</text>`+"\n", badgeFont, c.title))
+	for i, s := range stats {
+		x := 20 + i*115
+		sb.WriteString(fmt.Sprintf(`  <text x="%d" y="70" font-family="%s" font-size="24" font-weight="700" fill="%s">%s</text>`+"\n", x, badgeFont, c.value, s.value))
+		sb.WriteString(fmt.Sprintf(`  <text x="%d" y="92" font-family="%s" font-size="12" fill="%s">%s</text>`+"\n", x, badgeFont, c.label, s.label))
+	}
+	sb.WriteString("</svg>\n")
+
+	return sb.String()
+}
@@ -0,0 +1,41 @@
+package analytics
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestBadge(t *testing.T) {
+	m := &Metrics{PRsMerged: 12, PRsClosed: 4, TotalCommits: 87, MergeRate: 75}
+
+	for _, dark := range []bool{false, true} {
+		svg := m.Badge(dark)
+
+		var root struct {
+			XMLName xml.Name
+			Texts   []string `xml:"text"`
+		}
+		if err := xml.Unmarshal([]byte(svg), &root); err != nil {
+			t.Fatalf("dark=%v: badge is not valid XML: %v", dark, err)
+		}
+		if root.XMLName.Local != "svg" || root.XMLName.Space != "http://www.w3.org/2000/svg" {
+			t.Errorf("dark=%v: root element is %v, want svg", dark, root.XMLName)
+		}
+		want := []string{"GitHub Activity", "12", "PRs merged", "87", "Commits", "75%", "Merge rate"}
+		if strings.Join(root.Texts, "|") != strings.Join(want, "|") {
+			t.Errorf("dark=%v: texts = %q, want %q", dark, root.Texts, want)
+		}
+	}
+
+	if light, dark := m.Badge(false), m.Badge(true); !strings.Contains(light, lightBadge.background) || !strings.Contains(dark, darkBadge.background) {
+		t.Error("the light and dark badges don't use their own background")
+	}
+}
+
+func TestBadgeNoClosedPRs(t *testing.T) {
+	svg := (&Metrics{}).Badge(false)
+	if !strings.Contains(svg, ">–</text>") {
+		t.Errorf("badge without merged or closed PRs should show – for the merge rate:\n%s", svg)
+	}
+}
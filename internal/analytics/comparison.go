@@ -0,0 +1,104 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+)
+
+// Comparison holds key metrics for the range next to a prior range
+type Comparison struct {
+	Range daterange.Range `json:"range"` // the prior range
+	Rows  []ComparisonRow `json:"rows,omitempty"`
+}
+
+// ComparisonRow compares one metric between the range and the prior range
+type ComparisonRow struct {
+	Label string  `json:"label"`
+	This  float64 `json:"this"`
+	Prior float64 `json:"prior"`
+	// Unit is "" for counts, "%" for rates, and "d" for durations in days
+	Unit string `json:"unit"`
+}
+
+// Delta returns the change from the prior range
+func (r ComparisonRow) Delta() float64 {
+	return r.This - r.Prior
+}
+
+// PercentChange returns the change as a percentage of the prior value. It
+// reports false when the prior value is zero, where a percentage is undefined.
+func (r ComparisonRow) PercentChange() (float64, bool) {
+	if r.Prior == 0 {
+		return 0, false
+	}
+	return r.Delta() / r.Prior * 100, true
+}
+
+// ComparePrior records key metrics from prior, computed for the prior range
+// r, alongside the range's own
+func (m *Metrics) ComparePrior(prior *Metrics, r daterange.Range) {
+	days := func(mt *Metrics) float64 { return mt.AvgMergeTime.Hours() / 24 }
+	m.Prior = &Comparison{
+		Range: r,
+		Rows: []ComparisonRow{
+			{Label: "PRs opened", This: float64(m.PRsOpened), Prior: float64(prior.PRsOpened)},
+			{Label: "PRs merged", This: float64(m.PRsMerged), Prior: float64(prior.PRsMerged)},
+			{Label: "Merge rate", This: m.MergeRate, Prior: prior.MergeRate, Unit: "%"},
+			{Label: "Avg time to merge", This: days(m), Prior: days(prior), Unit: "d"},
+			{Label: "Commits", This: float64(m.TotalCommits), Prior: float64(prior.TotalCommits)},
+			{Label: "Reviews", This: float64(m.TotalReviews), Prior: float64(prior.TotalReviews)},
+			{Label: "Issues closed", This: float64(m.TotalIssuesClosed), Prior: float64(prior.TotalIssuesClosed)},
+			{Label: "Commented on", This: float64(m.TotalCommentedItems), Prior: float64(prior.TotalCommentedItems)},
+		},
+	}
+	if m.TotalDiscussions > 0 || prior.TotalDiscussions > 0 {
+		m.Prior.Rows = append(m.Prior.Rows, ComparisonRow{Label: "Discussions", This: float64(m.TotalDiscussions), Prior: float64(prior.TotalDiscussions)})
+	}
+}
+
+// formatValue formats a comparison value with its unit
+func formatValue(v float64, unit string) string {
+	switch unit {
+	case "%":
+		return fmt.Sprintf("%.0f%%", v)
+	case "d":
+		return fmt.Sprintf("%.1fd", v)
+	}
+	return fmt.Sprintf("%.0f", v)
+}
+
+// formatChange formats a row's delta and percent change, e.g. "+4 (+50%)".
+// Rate changes are in percentage points, so no percent change is added.
+func formatChange(r ComparisonRow) string {
+	delta := r.Delta()
+	var s string
+	switch r.Unit {
+	case "%":
+		return fmt.Sprintf("%+.0f pts", delta)
+	case "d":
+		s = fmt.Sprintf("%+.1fd", delta)
+	default:
+		s = fmt.Sprintf("%+.0f", delta)
+	}
+	if pct, ok := r.PercentChange(); ok {
+		return s + fmt.Sprintf(" (%+.0f%%)", pct)
+	}
+	if r.This > 0 {
+		return s + " (new)"
+	}
+	return s
+}
+
+// Format renders the comparison as an aligned this / prior / change table
+func (c *Comparison) Format() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Compared with %s:\n", c.Range))
+	sb.WriteString(fmt.Sprintf("  %-18s %8s %8s  %s\n", "", "This", "Prior", "Change"))
+	for _, r := range c.Rows {
+		sb.WriteString(fmt.Sprintf("  %-18s %8s %8s  %s\n",
+			r.Label, formatValue(r.This, r.Unit), formatValue(r.Prior, r.Unit), formatChange(r)))
+	}
+	return sb.String()
+}
@@ -0,0 +1,67 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/github"
+)
+
+func TestComparisonRowChange(t *testing.T) {
+	tests := []struct {
+		row     ComparisonRow
+		delta   float64
+		pct     float64
+		pctOK   bool
+		display string
+	}{
+		{ComparisonRow{This: 12, Prior: 8}, 4, 50, true, "+4 (+50%)"},
+		{ComparisonRow{This: 3, Prior: 6}, -3, -50, true, "-3 (-50%)"},
+		// No percentage of nothing
+		{ComparisonRow{This: 5, Prior: 0}, 5, 0, false, "+5 (new)"},
+		{ComparisonRow{This: 0, Prior: 0}, 0, 0, false, "+0"},
+		// Rates change by points
+		{ComparisonRow{This: 75, Prior: 50, Unit: "%"}, 25, 50, true, "+25 pts"},
+		{ComparisonRow{This: 1.5, Prior: 2, Unit: "d"}, -0.5, -25, true, "-0.5d (-25%)"},
+	}
+	for _, tt := range tests {
+		if got := tt.row.Delta(); math.Abs(got-tt.delta) > 1e-9 {
+			t.Errorf("%+v: Delta = %v, want %v", tt.row, got, tt.delta)
+		}
+		pct, ok := tt.row.PercentChange()
+		if ok != tt.pctOK || math.Abs(pct-tt.pct) > 1e-9 {
+			t.Errorf("%+v: PercentChange = %v, %v, want %v, %v", tt.row, pct, ok, tt.pct, tt.pctOK)
+		}
+		if math.IsNaN(pct) || math.IsInf(pct, 0) {
+			t.Errorf("%+v: PercentChange = %v", tt.row, pct)
+		}
+		if got := formatChange(tt.row); got != tt.display {
+			t.Errorf("%+v: formatChange = %q, want %q", tt.row, got, tt.display)
+		}
+	}
+}
+
+func TestComparePriorWithEmptyPrior(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, time.March, d, 0, 0, 0, 0, time.UTC) }
+	dr := daterange.Range{Start: day(1), End: day(31)}
+	prior := daterange.Range{Start: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC)}
+	merged := day(5)
+	prs := []github.PullRequest{{Number: 1, State: "merged", CreatedAt: day(3), ClosedAt: &merged, MergedAt: &merged}}
+
+	m := Compute(prs, nil, nil, nil, nil, nil, dr, Options{})
+	m.ComparePrior(Compute(nil, nil, nil, nil, nil, nil, prior, Options{}), prior)
+
+	if m.Prior == nil || m.Prior.Range != prior {
+		t.Fatalf("Prior = %+v, want a comparison with %v", m.Prior, prior)
+	}
+	for _, r := range m.Prior.Rows {
+		if r.Label == "PRs opened" && (r.This != 1 || r.Prior != 0) {
+			t.Errorf("PRs opened = %v / %v, want 1 / 0", r.This, r.Prior)
+		}
+		if _, ok := r.PercentChange(); ok {
+			t.Errorf("%s has a percent change against an empty prior range", r.Label)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/burritocatai/activitycat/internal/github"
+)
+
+// Top PR ranking keys
+const (
+	TopPRsByComments = "comments"
+	TopPRsBySize     = "size"
+)
+
+// ValidTopPRsBy reports whether by is a top PR ranking key
+func ValidTopPRsBy(by string) bool {
+	return by == TopPRsByComments || by == TopPRsBySize
+}
+
+// prScore returns the value PRs are ranked on: lines changed for
+// TopPRsBySize, comments otherwise
+func prScore(pr github.PullRequest, by string) int {
+	if by == TopPRsBySize {
+		return pr.LinesChanged()
+	}
+	return pr.Comments
+}
+
+// topPRs returns up to n PRs with the highest score, highest first, with
+// ties broken by repository and number. PRs scoring zero are left out, so
+// sizes that were never looked up don't produce a list.
+func topPRs(prs []github.PullRequest, n int, by string) []github.PullRequest {
+	if n <= 0 {
+		return nil
+	}
+	var ranked []github.PullRequest
+	for _, pr := range prs {
+		if prScore(pr, by) > 0 {
+			ranked = append(ranked, pr)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if prScore(a, by) != prScore(b, by) {
+			return prScore(a, by) > prScore(b, by)
+		}
+		if a.Repository.NameWithOwner != b.Repository.NameWithOwner {
+			return a.Repository.NameWithOwner < b.Repository.NameWithOwner
+		}
+		return a.Number < b.Number
+	})
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// DescribePR renders a PR on one line with the value it was ranked on, e.g.
+// "Add caching (acme/web#12, +120/-30 lines)", with the repository labelled
+// from aliases
+func DescribePR(pr github.PullRequest, by string, aliases map[string]string) string {
+	detail := fmt.Sprintf("%d comments", pr.Comments)
+	if by == TopPRsBySize {
+		detail = fmt.Sprintf("+%d/-%d lines", pr.Additions, pr.Deletions)
+	}
+	return fmt.Sprintf("%s (%s#%d, %s)", pr.Title, github.RepoLabel(aliases, pr.Repository.NameWithOwner), pr.Number, detail)
+}
+
+// FormatTopPRs renders TopPRs as a numbered list, or returns "" if there are none
+func (m *Metrics) FormatTopPRs() string {
+	if len(m.TopPRs) == 0 {
+		return ""
+	}
+	label := "most discussed"
+	if m.TopPRsBy == TopPRsBySize {
+		label = "largest"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Top PRs (%s):", label))
+	for i, pr := range m.TopPRs {
+		sb.WriteString(fmt.Sprintf("\n  %d. %s", i+1, DescribePR(pr, m.TopPRsBy, m.repoAliases)))
+	}
+	return sb.String()
+}
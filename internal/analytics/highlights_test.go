@@ -0,0 +1,50 @@
+package analytics
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/github"
+)
+
+func TestTopPRs(t *testing.T) {
+	dr := daterange.Range{Start: date(2025, time.March, 1), End: date(2025, time.March, 31)}
+	day := date(2025, time.March, 3)
+	pr := func(repo string, number, comments, additions, deletions int) github.PullRequest {
+		return github.PullRequest{
+			Number: number, State: "open", CreatedAt: day,
+			Repository: github.Repository{NameWithOwner: repo},
+			Comments:   comments, Additions: additions, Deletions: deletions,
+		}
+	}
+	prs := []github.PullRequest{
+		pr("acme/web", 1, 2, 500, 100),
+		pr("acme/web", 2, 9, 10, 5),
+		pr("acme/api", 3, 4, 0, 0),
+		pr("acme/api", 4, 9, 80, 20),
+		pr("acme/web", 5, 0, 1000, 0),
+	}
+	tests := []struct {
+		n    int
+		by   string
+		want []int
+	}{
+		// Ties are broken by repository, then number
+		{3, TopPRsByComments, []int{4, 2, 3}},
+		{10, TopPRsByComments, []int{4, 2, 3, 1}},
+		{2, TopPRsBySize, []int{5, 1}},
+		{0, TopPRsByComments, nil},
+	}
+	for _, tt := range tests {
+		m := Compute(prs, nil, nil, nil, nil, nil, dr, Options{TopPRs: tt.n, TopPRsBy: tt.by})
+		var got []int
+		for _, pr := range m.TopPRs {
+			got = append(got, pr.Number)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("top %d by %s = %v, want %v", tt.n, tt.by, got, tt.want)
+		}
+	}
+}
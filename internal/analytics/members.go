@@ -0,0 +1,99 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/burritocatai/activitycat/internal/github"
+)
+
+// MemberStats groups all activity types for a single team member
+type MemberStats struct {
+	Member         string `json:"member"`
+	PRs            int    `json:"prs"`
+	Issues         int    `json:"issues"`
+	Reviews        int    `json:"reviews"`
+	Commits        int    `json:"commits"`
+	CommentedItems int    `json:"commentedItems"`
+	Total          int    `json:"total"`
+}
+
+// memberStats counts activity per team member, most active first with ties
+// broken by login. It returns nil unless the activity was fetched for a team.
+func memberStats(
+	prs []github.PullRequest,
+	issues []github.Issue,
+	reviews []github.Review,
+	commits []github.Commit,
+	commentedItems []github.CommentedItem,
+) []MemberStats {
+	memberMap := make(map[string]*MemberStats)
+	getMember := func(login string) *MemberStats {
+		if ms, ok := memberMap[login]; ok {
+			return ms
+		}
+		ms := &MemberStats{Member: login}
+		memberMap[login] = ms
+		return ms
+	}
+
+	for _, pr := range prs {
+		if pr.Member != "" {
+			getMember(pr.Member).PRs++
+		}
+	}
+	for _, issue := range issues {
+		if issue.Member != "" {
+			getMember(issue.Member).Issues++
+		}
+	}
+	for _, r := range reviews {
+		if r.Member != "" {
+			getMember(r.Member).Reviews++
+		}
+	}
+	for _, c := range commits {
+		if c.Member != "" {
+			getMember(c.Member).Commits++
+		}
+	}
+	for _, ci := range commentedItems {
+		if ci.Member != "" {
+			getMember(ci.Member).CommentedItems++
+		}
+	}
+
+	var stats []MemberStats
+	for _, ms := range memberMap {
+		ms.Total = ms.PRs + ms.Issues + ms.Reviews + ms.Commits + ms.CommentedItems
+		stats = append(stats, *ms)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total != stats[j].Total {
+			return stats[i].Total > stats[j].Total
+		}
+		return stats[i].Member < stats[j].Member
+	})
+	return stats
+}
+
+// FormatMemberStats renders the per-member breakdown, or returns "" if the
+// activity wasn't fetched for a team
+func (m *Metrics) FormatMemberStats() string {
+	if len(m.MemberStats) == 0 {
+		return ""
+	}
+	width := 0
+	for _, ms := range m.MemberStats {
+		width = max(width, len(ms.Member)+1)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("By member:")
+	for _, ms := range m.MemberStats {
+		sb.WriteString(fmt.Sprintf("\n  %-*s %d PRs, %d issues, %d reviews, %d commits, %d comments",
+			width, "@"+ms.Member, ms.PRs, ms.Issues, ms.Reviews, ms.Commits, ms.CommentedItems))
+	}
+	return sb.String()
+}
@@ -0,0 +1,33 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/burritocatai/activitycat/internal/github"
+)
+
+func TestMemberStats(t *testing.T) {
+	prs := []github.PullRequest{{Member: "alice"}, {Member: "alice"}, {Member: "bob"}}
+	reviews := []github.Review{{Member: "bob"}, {Member: "bob"}, {Member: "bob"}}
+	commits := []github.Commit{{Member: "alice"}}
+
+	got := memberStats(prs, nil, reviews, commits, nil)
+	want := []MemberStats{
+		{Member: "bob", PRs: 1, Reviews: 3, Total: 4},
+		{Member: "alice", PRs: 2, Commits: 1, Total: 3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("memberStats = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("memberStats[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMemberStatsWithoutTeam(t *testing.T) {
+	if got := memberStats([]github.PullRequest{{Number: 1}}, nil, nil, nil, nil); got != nil {
+		t.Errorf("memberStats without members = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,55 @@
+package analytics
+
+import (
+	"slices"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/github"
+)
+
+// Default working hours, as offsets from midnight
+const (
+	DefaultWorkdayStart = 9 * time.Hour
+	DefaultWorkdayEnd   = 18 * time.Hour
+)
+
+// isOffHours reports whether t falls on a day off, or before start or from
+// end onwards on a working day, on the clock in loc
+func isOffHours(t time.Time, loc *time.Location, workingDays []time.Weekday, start, end time.Duration) bool {
+	t = t.In(loc)
+	if !slices.Contains(workingDays, t.Weekday()) {
+		return true
+	}
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	return clock < start || clock >= end
+}
+
+// offHours counts the commits and PRs made outside working hours, and how
+// many commits and PRs had a time to check
+func offHours(prs []github.PullRequest, commits []github.Commit, workingDays []time.Weekday, opts Options) (off, total int) {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	start, end := opts.WorkdayStart, opts.WorkdayEnd
+	if start == 0 && end == 0 {
+		start, end = DefaultWorkdayStart, DefaultWorkdayEnd
+	}
+
+	count := func(t time.Time) {
+		if t.IsZero() {
+			return
+		}
+		total++
+		if isOffHours(t, loc, workingDays, start, end) {
+			off++
+		}
+	}
+	for _, pr := range prs {
+		count(pr.CreatedAt)
+	}
+	for _, c := range commits {
+		count(c.Commit.Author.Date)
+	}
+	return off, total
+}
@@ -0,0 +1,64 @@
+package analytics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/github"
+)
+
+func TestIsOffHours(t *testing.T) {
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	// Monday the 3rd and Saturday the 8th of March 2025
+	at := func(day, hour, min, sec int) time.Time {
+		return time.Date(2025, time.March, day, hour, min, sec, 0, time.UTC)
+	}
+
+	tests := []struct {
+		t    time.Time
+		want bool
+	}{
+		{at(3, 8, 59, 59), true},
+		{at(3, 9, 0, 0), false},
+		{at(3, 17, 59, 59), false},
+		{at(3, 18, 0, 0), true},
+		{at(8, 12, 0, 0), true},
+	}
+	for _, tt := range tests {
+		if got := isOffHours(tt.t, time.UTC, weekdays, DefaultWorkdayStart, DefaultWorkdayEnd); got != tt.want {
+			t.Errorf("isOffHours(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+
+	// 14:00 UTC is 09:00 five hours behind, so inside working hours there,
+	// and 13:59 is before they start
+	behind := time.FixedZone("UTC-5", -5*60*60)
+	if isOffHours(at(3, 14, 0, 0), behind, weekdays, DefaultWorkdayStart, DefaultWorkdayEnd) {
+		t.Error("09:00 in the configured zone is off hours")
+	}
+	if !isOffHours(at(3, 13, 59, 0), behind, weekdays, DefaultWorkdayStart, DefaultWorkdayEnd) {
+		t.Error("08:59 in the configured zone is working hours")
+	}
+}
+
+func TestOffHoursShare(t *testing.T) {
+	dr := daterange.Range{Start: date(2025, time.March, 1), End: date(2025, time.March, 31)}
+	prs := []github.PullRequest{
+		{CreatedAt: time.Date(2025, time.March, 3, 8, 0, 0, 0, time.UTC)},
+		{CreatedAt: time.Date(2025, time.March, 3, 10, 0, 0, 0, time.UTC)},
+	}
+	commits := []github.Commit{
+		{Commit: github.CommitDetail{Author: github.CommitAuthor{Date: time.Date(2025, time.March, 3, 18, 0, 0, 0, time.UTC)}}},
+		{Commit: github.CommitDetail{Author: github.CommitAuthor{Date: time.Date(2025, time.March, 4, 17, 0, 0, 0, time.UTC)}}},
+	}
+
+	m := Compute(prs, nil, nil, commits, nil, nil, dr, Options{Location: time.UTC})
+	if m.OffHoursActivity != 2 || m.OffHoursShare != 0.5 {
+		t.Errorf("OffHoursActivity = %d, OffHoursShare = %v, want 2 and 0.5", m.OffHoursActivity, m.OffHoursShare)
+	}
+	if out := m.Format(); !strings.Contains(out, "Off-hours activity: 50% of commits and PRs") {
+		t.Errorf("Format() is missing the off-hours share:\n%s", out)
+	}
+}
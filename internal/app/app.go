@@ -1,15 +1,28 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
 	"github.com/burritocatai/activitycat/internal/analytics"
 	"github.com/burritocatai/activitycat/internal/config"
 	"github.com/burritocatai/activitycat/internal/daterange"
 	"github.com/burritocatai/activitycat/internal/github"
+	"github.com/burritocatai/activitycat/internal/keys"
 	"github.com/burritocatai/activitycat/internal/llm"
 	"github.com/burritocatai/activitycat/internal/ui/dateselect"
 	"github.com/burritocatai/activitycat/internal/ui/loading"
+	"github.com/burritocatai/activitycat/internal/ui/messageedit"
+	"github.com/burritocatai/activitycat/internal/ui/modelselect"
 	"github.com/burritocatai/activitycat/internal/ui/prlist"
 	"github.com/burritocatai/activitycat/internal/ui/promptselect"
 	"github.com/burritocatai/activitycat/internal/ui/report"
@@ -24,9 +37,20 @@ const (
 	StateLoading
 	StatePRList
 	StatePromptSelect
+	StateEditMessage
 	StateGenerating
 	StateReport
 	StateError
+	StateSelectModel
+	StateConfirmCost
+)
+
+// operation identifies the step that produced an error, so it can be retried
+type operation int
+
+const (
+	opFetch operation = iota
+	opGenerate
 )
 
 // Model is the main application model
@@ -40,11 +64,19 @@ type Model struct {
 	loading      loading.Model
 	prList       prlist.Model
 	promptSelect promptselect.Model
+	messageEdit  messageedit.Model
 	reportView   report.Model
+	modelSelect  modelselect.Model
 
 	// LLM provider
 	llmProvider  llm.Provider
 	providerName string
+	modelName    string
+	redactor     *llm.Redactor
+	// modelOptions are offered by the model switcher, from the startup config
+	modelOptions []modelselect.Option
+
+	cfg config.Config
 
 	// Shared data
 	selectedRange   daterange.Range
@@ -53,29 +85,169 @@ type Model struct {
 	reviews         []github.Review
 	commits         []github.Commit
 	commentedItems  []github.CommentedItem
+	discussions     []github.Discussion
+	warnings        []string
+	startupWarnings []string
 	metrics         *analytics.Metrics
 	prompts         []config.Prompt
 	selectedPrompt  config.Prompt
+	systemPrompt    string
+	imported        *github.ActivityLoadedMsg
+	activityCache   map[string]github.ActivityLoadedMsg // loaded activity by rangeKey
+	extraPrompt     *config.Prompt
+	darkBadge       bool
+	darkBackground  bool
+	anonymize       bool
+	focusRepos      []string // cfg.FocusRepos as placeholders when anonymizing
+	presetPrompt    string
 	generatedReport string
 	err             error
+	failedOp        operation
+	estimateID      int   // latest costEstimatesCmd, see costEstimatesMsg
+	returnState     State // screen to go back to from switching the model
+
+	// checkID is the latest checkProviderCmd, see providerCheckedMsg, and
+	// cancelCheck stops it when the switcher is left
+	checkID     int
+	cancelCheck context.CancelFunc
+
+	// generateCmd is the last generation request, kept for retries, and
+	// cancelGenerate stops it, keeping what was generated so far.
+	// lastRequest is the prompt selection or edited message that started
+	// it, replayed to regenerate a partial report.
+	generateCmd    func(context.Context) tea.Cmd
+	cancelGenerate context.CancelFunc
+	lastRequest    tea.Msg
+	partial        bool // the report was cut short by cancelling
+
+	// pendingRequest is a request waiting on confirmation of its estimated
+	// pendingCost, and costConfirmed lets it through once it is sent again
+	pendingRequest tea.Msg
+	pendingCost    float64
+	costConfirmed  bool
+
+	// Combined reports generate one section per prompt, in order
+	sectionPrompts    []config.Prompt
+	sections          []string
+	sectionRedactions int
+	sectionUsage      llm.Usage
+}
+
+// Options holds command-line overrides for the application
+type Options struct {
+	// Prompt is pre-selected on the prompt selection screen. It replaces a
+	// loaded prompt of the same name, or is added to the front of the list.
+	Prompt *config.Prompt
+	// Range skips date selection and starts fetching immediately
+	Range *daterange.Range
+	// Activity, when set, is used instead of fetching for Range
+	Activity *github.ActivityLoadedMsg
+	// Warnings from startup checks are shown alongside fetch warnings
+	Warnings []string
+	// Anonymize replaces repository names and logins with placeholders as
+	// soon as activity is loaded
+	Anonymize bool
+	// ConfirmCost is asked whether to go ahead with a report generated
+	// without the TUI whose estimated cost is above confirm_cost_above.
+	// Without it such reports are generated without asking.
+	ConfirmCost func(cost float64) bool
 }
 
+// fetchingMessage is shown while activity is fetched
+const fetchingMessage = "Fetching activity data (PRs, issues, reviews, commits, comments)..."
+
+// newProvider creates the provider reports are generated with, replaced in
+// tests
+var newProvider = llm.NewProvider
+
 // New creates a new application model
-func New(cfg config.Config) Model {
-	prompts, _ := config.LoadPrompts()
-	provider, _ := llm.NewProvider(cfg)
+func New(cfg config.Config, opts Options) Model {
+	provider, _ := newProvider(cfg)
+	// Patterns were checked by Validate at startup
+	redactor, _ := llm.NewRedactor(cfg.Redactions)
+
+	m := Model{
+		state:           StateSelectDate,
+		dateSelect:      dateselect.New(cfg.DefaultRange, cfg.DateFormat),
+		llmProvider:     provider,
+		redactor:        redactor,
+		providerName:    cfg.Provider,
+		modelName:       cfg.Model,
+		modelOptions:    modelselect.Options(cfg),
+		cfg:             cfg,
+		extraPrompt:     opts.Prompt,
+		startupWarnings: opts.Warnings,
+		anonymize:       opts.Anonymize,
+		activityCache:   make(map[string]github.ActivityLoadedMsg),
+	}
+	// Prompt files sharing a name are already in opts.Warnings
+	m.prompts, _ = m.loadPrompts()
+
+	// Detect the background now, as querying the terminal once the program
+	// is running would race with its input handling
+	m.darkBackground = lipgloss.HasDarkBackground()
+	m.darkBadge = cfg.BadgeTheme == "dark"
+	if cfg.BadgeTheme == "" || cfg.BadgeTheme == "auto" {
+		m.darkBadge = m.darkBackground
+	}
+
+	if opts.Prompt != nil {
+		m.presetPrompt = opts.Prompt.Name
+	}
+
+	if opts.Range != nil {
+		m.selectedRange = *opts.Range
+		m.imported = opts.Activity
+		m.loading = loading.New(fetchingMessage, cfg)
+		m.state = StateLoading
+	}
 
-	return Model{
-		state:        StateSelectDate,
-		dateSelect:   dateselect.New(),
-		prompts:      prompts,
-		llmProvider:  provider,
-		providerName: cfg.Provider,
+	return m
+}
+
+// loadPrompts returns the user's prompts with any command-line prompt and the
+// built-in Metrics Only option, and warnings naming prompt files skipped
+// because another has the same name
+func (m Model) loadPrompts() ([]config.Prompt, []string) {
+	// With --summary the provider wasn't checked at startup, so none of the
+	// prompts needing it are offered
+	if m.extraPrompt != nil && m.extraPrompt.MetricsOnly {
+		return []config.Prompt{*m.extraPrompt}, nil
+	}
+	prompts, warnings := config.LoadPrompts()
+	if m.extraPrompt != nil {
+		prompts = withPrompt(prompts, *m.extraPrompt)
+	}
+	return withPrompt(prompts, config.MetricsOnlyPrompt), warnings
+}
+
+// withPrompt returns prompts with p substituted for any prompt of the same name,
+// or prepended if there is none
+func withPrompt(prompts []config.Prompt, p config.Prompt) []config.Prompt {
+	for i := range prompts {
+		if prompts[i].Name == p.Name {
+			out := append([]config.Prompt(nil), prompts...)
+			out[i] = p
+			return out
+		}
 	}
+	if p.MetricsOnly {
+		return append(prompts, p)
+	}
+	return append([]config.Prompt{p}, prompts...)
 }
 
 // Init initializes the application
 func (m Model) Init() tea.Cmd {
+	if m.imported != nil {
+		imported := *m.imported
+		return func() tea.Msg {
+			return imported
+		}
+	}
+	if m.state == StateLoading {
+		return m.fetchCmd()
+	}
 	return m.dateSelect.Init()
 }
 
@@ -86,46 +258,152 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 
-		if m.state == StatePRList {
+		// A screen left open under the model switcher is resized too
+		screen := m.state
+		if screen == StateSelectModel {
+			screen = m.returnState
+		}
+		if screen == StatePRList {
 			m.prList.SetSize(msg.Width, msg.Height)
 		}
-		if m.state == StateReport {
+		if screen == StateReport {
 			m.reportView.SetSize(msg.Width, msg.Height)
 		}
+		if screen == StateEditMessage {
+			m.messageEdit.SetSize(msg.Width, msg.Height)
+		}
+		if screen == StatePromptSelect {
+			m.promptSelect.SetSize(msg.Width, msg.Height)
+		}
+
+	case progressMsg:
+		if m.state == StateGenerating {
+			m.loading, _ = m.loading.Update(loading.ProgressMsg{Fraction: msg.fraction})
+		}
+		return m, waitForProgress(msg.progress)
 
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
 		}
+		if m.state == StateGenerating && (msg.String() == "esc" || key.Matches(msg, keys.Map.Back)) {
+			m.cancelGenerate()
+			m.loading.SetMessage("Cancelling...")
+			m.loading.SetHint("")
+			return m, nil
+		}
+		if key.Matches(msg, keys.Map.Settings) && m.canSwitchModel() {
+			active := m.cfg
+			active.Provider, active.Model = m.providerName, m.modelName
+			m.modelSelect = modelselect.New(m.modelOptions, active)
+			m.returnState = m.state
+			m.state = StateSelectModel
+			return m, nil
+		}
+
+	case modelselect.SelectedMsg:
+		cfg := m.cfg
+		cfg.Provider, cfg.Model = msg.Option.Provider, msg.Option.Model
+		cmd := m.checkProviderCmd(cfg)
+		return m, cmd
+
+	case providerCheckedMsg:
+		// The check was abandoned by leaving the switcher
+		if msg.id != m.checkID || m.state != StateSelectModel {
+			return m, nil
+		}
+		if msg.Error != nil {
+			m.modelSelect.SetError(msg.Error)
+			return m, nil
+		}
+		provider, err := newProvider(msg.Config)
+		if err != nil {
+			m.modelSelect.SetError(err)
+			return m, nil
+		}
+		m.cfg = msg.Config
+		m.llmProvider, m.providerName, m.modelName = provider, m.cfg.Provider, m.cfg.Model
+		// The failed request closed over the old provider, so a retry
+		// starts it again from lastRequest with the new one
+		m.generateCmd = nil
+		m.state = m.returnState
+		if m.state == StatePromptSelect {
+			return m, m.costEstimatesCmd()
+		}
+		return m, nil
+
+	case modelselect.BackMsg:
+		if m.cancelCheck != nil {
+			m.cancelCheck()
+		}
+		m.checkID++
+		m.state = m.returnState
+		return m, nil
 
 	case dateselect.DateSelectedMsg:
 		m.selectedRange = msg.Range
-		m.loading = loading.New("Fetching activity data (PRs, issues, reviews, commits, comments)...")
+		m.loading = loading.New(fetchingMessage, m.cfg)
 		m.state = StateLoading
-		return m, tea.Batch(
-			m.loading.Init(),
-			github.FetchActivityCmd(m.selectedRange),
-		)
+		return m, m.fetchCmd()
 
 	case github.ActivityLoadedMsg:
 		if msg.Error != nil {
-			m.err = msg.Error
-			m.state = StateError
-			return m, nil
+			return m.showError(msg.Error, opFetch)
+		}
+		if m.anonymize {
+			m.focusRepos = msg.Anonymize(m.cfg.FocusRepos)
 		}
+		m.activityCache[rangeKey(m.selectedRange)] = msg
 		m.prs = msg.PRs
 		m.issues = msg.Issues
 		m.reviews = msg.Reviews
 		m.commits = msg.Commits
 		m.commentedItems = msg.CommentedItems
-		m.metrics = analytics.Compute(m.prs, m.issues, m.reviews, m.commits, m.commentedItems, m.selectedRange)
-		m.prList = prlist.New(m.prs, m.issues, m.reviews, m.commits, m.commentedItems, m.metrics, m.width, m.height)
-		m.state = StatePRList
-		return m, nil
+		m.discussions = msg.Discussions
+		m.warnings = append(append([]string(nil), m.startupWarnings...), msg.Warnings...)
+		m.metrics = analytics.Compute(m.prs, m.issues, m.reviews, m.commits, m.commentedItems, m.discussions, m.selectedRange, m.analyticsOptions())
+
+		// A range reaching into last year isn't part of this year to date
+		if ytd, ok := m.selectedRange.YearToDate(); ok && m.cfg.CompareYTD {
+			m.loading.SetMessage("Fetching year-to-date activity for comparison...")
+			return m, github.FetchComparisonCmd(ytd, m.fetchOptions())
+		}
+
+		return m.comparePrevious()
+
+	case github.ComparisonLoadedMsg:
+		// The comparison is supplementary, so a failed fetch just omits it
+		if msg.Error == nil {
+			m.metrics.CompareYTD(len(msg.PRs), len(msg.Commits))
+		}
+		// The main fetch usually hit the same problem, so say it once
+		for _, w := range msg.Warnings {
+			if !slices.Contains(m.warnings, w) {
+				m.warnings = append(m.warnings, w)
+			}
+		}
+		return m.comparePrevious()
+
+	case github.PriorActivityLoadedMsg:
+		// Like the YTD comparison, a failed fetch just omits it
+		if msg.Activity.Error != nil {
+			m.warnings = append(m.warnings, fmt.Sprintf("Could not fetch the previous period for comparison: %v", msg.Activity.Error))
+			return m.showPRList()
+		}
+		m.activityCache[rangeKey(msg.Range)] = msg.Activity
+		m.comparePrior(msg.Range, msg.Activity)
+		return m.showPRList()
 
 	case prlist.ContinueMsg:
-		m.promptSelect = promptselect.New(m.prompts)
+		m.promptSelect = promptselect.New(m.prompts, m.width, m.height)
+		m.promptSelect.Select(m.presetPrompt)
 		m.state = StatePromptSelect
+		return m, m.costEstimatesCmd()
+
+	case costEstimatesMsg:
+		if msg.id == m.estimateID {
+			m.promptSelect.SetEstimates(msg.estimates)
+		}
 		return m, nil
 
 	case prlist.BackMsg:
@@ -133,47 +411,647 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case promptselect.PromptSelectedMsg:
+		m.lastRequest = msg
 		m.selectedPrompt = msg.Prompt
-		providerLabels := map[string]string{
-			"claude": "Claude AI",
-			"ollama": "Ollama",
-			"openai": "OpenAI",
+		m.sectionPrompts = nil
+		if msg.Prompt.MetricsOnly {
+			return m.showReport(m.metrics.FormatReport(m.selectedRange), "", true)
 		}
-		label, ok := providerLabels[m.providerName]
-		if !ok {
-			label = m.providerName
+		var err error
+		if m, err = m.usePromptModel(msg.Prompt); err != nil {
+			return m.showError(err, opGenerate)
+		}
+		if msg.Edit || m.cfg.EditBeforeGenerate {
+			systemPrompt, userMessage := llm.BuildMessages(m.prs, m.issues, m.reviews, m.commits, m.commentedItems, m.discussions, m.metrics, m.selectedPrompt.Content, m.formatOptions(m.selectedPrompt), m.cfg.SystemPrompt)
+			m.systemPrompt = systemPrompt
+			m.messageEdit = messageedit.New(userMessage, m.width, m.height)
+			m.state = StateEditMessage
+			return m, m.messageEdit.Init()
+		}
+		var asked bool
+		if m, asked = m.confirmCost(m.promptCost(msg.Prompt), msg); asked {
+			return m, nil
 		}
-		loadingMsg := "Generating report with " + label + "..."
-		m.loading = loading.New(loadingMsg)
-		m.state = StateGenerating
-		return m, tea.Batch(
-			m.loading.Init(),
-			llm.GenerateReportCmd(m.llmProvider, m.prs, m.issues, m.reviews, m.commits, m.commentedItems, m.metrics, m.selectedPrompt.Content),
-		)
+		return m.startGenerating(m.reportRequest())
+
+	case promptselect.PromptsSelectedMsg:
+		m.lastRequest = msg
+		var cost float64
+		for _, p := range msg.Prompts {
+			cost += m.promptCost(p)
+		}
+		var asked bool
+		if m, asked = m.confirmCost(cost, msg); asked {
+			return m, nil
+		}
+		m.sectionPrompts = msg.Prompts
+		m.sections = nil
+		m.sectionRedactions = 0
+		m.sectionUsage = llm.Usage{}
+		return m.generateNextSection()
+
+	case messageedit.SubmitMsg:
+		m.lastRequest = msg
+		var cost float64
+		if price, ok := m.modelPrice(); ok {
+			cost = llm.EstimateCost(price, m.systemPrompt, msg.Message)
+		}
+		var asked bool
+		if m, asked = m.confirmCost(cost, msg); asked {
+			return m, nil
+		}
+		provider, redactor, systemPrompt := m.llmProvider, m.redactor, m.systemPrompt
+		return m.startGenerating(func(ctx context.Context) tea.Cmd {
+			return llm.SendMessageCmd(ctx, provider, redactor, systemPrompt, msg.Message)
+		})
+
+	case messageedit.BackMsg:
+		m.state = StatePromptSelect
+		return m, nil
+
+	case promptselect.PromptsChangedMsg:
+		var warnings []string
+		m.prompts, warnings = m.loadPrompts()
+		m.promptSelect = promptselect.New(m.prompts, m.width, m.height)
+		m.promptSelect.Select(msg.Select)
+		m.promptSelect.SetWarnings(warnings)
+		if msg.Error != nil {
+			m.promptSelect.SetError(msg.Error)
+		}
+		return m, m.costEstimatesCmd()
 
 	case promptselect.BackMsg:
 		m.state = StatePRList
 		return m, nil
 
 	case llm.ReportGeneratedMsg:
+		// Release the finished request's context
+		if m.cancelGenerate != nil {
+			m.cancelGenerate()
+		}
+		if msg.Cancelled {
+			return m.generationCancelled(msg)
+		}
 		if msg.Error != nil {
-			m.err = msg.Error
-			m.state = StateError
-			return m, nil
+			return m.showError(msg.Error, opGenerate)
 		}
-		m.generatedReport = msg.Report
-		m.reportView = report.New(m.generatedReport, m.width, m.height)
-		m.state = StateReport
-		return m, nil
+		msg.Report = m.normalizeHeadings(msg.Report)
+		if m.sectionPrompts != nil {
+			m.sections = append(m.sections, msg.Report)
+			m.sectionRedactions += msg.Redactions
+			m.sectionUsage.InputTokens += msg.Usage.InputTokens
+			m.sectionUsage.OutputTokens += msg.Usage.OutputTokens
+			return m.generateNextSection()
+		}
+		return m.showReport(msg.Report, m.reportNote(msg.Redactions, msg.Usage), false)
 
 	case report.BackMsg:
 		m.state = StatePromptSelect
 		return m, nil
+
+	case report.RegenerateMsg:
+		return m.Update(m.lastRequest)
 	}
 
 	return m.updateCurrentState(msg)
 }
 
+// providerCheckedMsg is sent once a provider chosen in the model switcher
+// has been checked. Only the latest check's result is used, and only while
+// the switcher is still open.
+type providerCheckedMsg struct {
+	id     int
+	Config config.Config
+	Error  error
+}
+
+// providerCheckTimeout is how long checking a provider may take before the
+// switch is refused, e.g. for an Ollama host that doesn't answer
+const providerCheckTimeout = 15 * time.Second
+
+// checkProviderCmd checks that cfg's provider has credentials and its model
+// is available before it is switched to. Leaving the switcher cancels it.
+func (m *Model) checkProviderCmd(cfg config.Config) tea.Cmd {
+	ctx, cancel := context.WithTimeout(context.Background(), providerCheckTimeout)
+	m.checkID++
+	m.cancelCheck = cancel
+	id := m.checkID
+	return func() tea.Msg {
+		defer cancel()
+		err := llm.CheckProvider(ctx, cfg)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("checking %s timed out after %s", cfg.Provider, providerCheckTimeout)
+		}
+		return providerCheckedMsg{id: id, Config: cfg, Error: err}
+	}
+}
+
+// canSwitchModel reports whether the model switcher can open over the
+// current screen: not while work is in flight, from the switcher itself, or
+// from the message editor, where the key is typed
+func (m Model) canSwitchModel() bool {
+	switch m.state {
+	case StateLoading, StateGenerating, StateSelectModel, StateConfirmCost, StateEditMessage:
+		return false
+	}
+	return true
+}
+
+// fetchCmd starts the loading spinner and fetches activity for the selected range
+func (m Model) fetchCmd() tea.Cmd {
+	return tea.Batch(
+		m.loading.Init(),
+		github.FetchActivityCmd(m.selectedRange, m.fetchOptions()),
+	)
+}
+
+// comparePrevious compares the metrics against the previous period of the
+// same length if compare_previous is set, fetching it unless it was already
+// loaded, then shows the activity list
+func (m Model) comparePrevious() (tea.Model, tea.Cmd) {
+	if !m.cfg.ComparePrevious {
+		return m.showPRList()
+	}
+	prior := m.selectedRange.Previous()
+	if activity, ok := m.activityCache[rangeKey(prior)]; ok {
+		m.comparePrior(prior, activity)
+		return m.showPRList()
+	}
+	m.loading.SetMessage("Fetching " + prior.Format(m.cfg.DateFormat) + " for comparison...")
+	return m, github.FetchPriorActivityCmd(prior, m.fetchOptions())
+}
+
+// comparePrior adds a comparison with activity from the prior range r to the metrics
+func (m *Model) comparePrior(r daterange.Range, activity github.ActivityLoadedMsg) {
+	prior := analytics.Compute(activity.PRs, activity.Issues, activity.Reviews, activity.Commits, activity.CommentedItems, activity.Discussions, r, m.analyticsOptions())
+	m.metrics.ComparePrior(prior, r)
+}
+
+// rangeKey identifies a range by its days, which is all searches use
+func rangeKey(r daterange.Range) string {
+	return r.Start.Format("2006-01-02") + ".." + r.End.Format("2006-01-02")
+}
+
+// reportRequest returns the request for a report from the selected prompt
+func (m Model) reportRequest() func(context.Context) tea.Cmd {
+	return func(ctx context.Context) tea.Cmd {
+		return llm.GenerateReportCmd(ctx, m.llmProvider, m.redactor, m.prs, m.issues, m.reviews, m.commits, m.commentedItems, m.discussions, m.metrics, m.selectedPrompt.Content, m.formatOptions(m.selectedPrompt), m.cfg.SystemPrompt)
+	}
+}
+
+// startGenerating shows the generation spinner while request produces the
+// report, with a context that cancelling generation cancels
+func (m Model) startGenerating(request func(context.Context) tea.Cmd) (tea.Model, tea.Cmd) {
+	providerLabels := map[string]string{
+		"claude": "Claude AI",
+		"ollama": "Ollama",
+		"openai": "OpenAI",
+	}
+	label, ok := providerLabels[m.providerName]
+	if !ok {
+		label = m.providerName
+	}
+	loadingMsg := "Generating report with " + label + "..."
+	if m.sectionPrompts != nil {
+		loadingMsg = fmt.Sprintf("Generating section %d of %d (%s) with %s...",
+			len(m.sections)+1, len(m.sectionPrompts), m.selectedPrompt.Name, label)
+	}
+	m.loading = loading.New(loadingMsg, m.cfg)
+	m.loading.TrackElapsed()
+	m.loading.SetHint("Esc: Cancel, keeping what has been generated so far")
+	m.state = StateGenerating
+	m.generateCmd = request
+	m.partial = false
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelGenerate = cancel
+
+	// Show how far a streaming provider has got. Updates the screen hasn't
+	// caught up with are dropped, as a newer one follows.
+	progress := make(chan float64, 1)
+	ctx = llm.WithProgress(ctx, func(fraction float64) {
+		select {
+		case progress <- fraction:
+		default:
+		}
+	})
+	generate := request(ctx)
+	return m, tea.Batch(
+		m.loading.Init(),
+		func() tea.Msg {
+			defer close(progress)
+			return generate()
+		},
+		waitForProgress(progress),
+	)
+}
+
+// progressMsg carries generation progress received on progress
+type progressMsg struct {
+	progress <-chan float64
+	fraction float64
+}
+
+// waitForProgress waits for the next progress update, until generation
+// finishes and progress is closed
+func waitForProgress(progress <-chan float64) tea.Cmd {
+	return func() tea.Msg {
+		fraction, ok := <-progress
+		if !ok {
+			return nil
+		}
+		return progressMsg{progress: progress, fraction: fraction}
+	}
+}
+
+// generationCancelled shows what was generated before generation was
+// cancelled as a partial report, including any finished sections of a
+// combined report, or goes back to the prompts if nothing was
+func (m Model) generationCancelled(msg llm.ReportGeneratedMsg) (tea.Model, tea.Cmd) {
+	msg.Report = m.normalizeHeadings(msg.Report)
+	if m.sectionPrompts != nil {
+		if msg.Report != "" {
+			m.sections = append(m.sections, msg.Report)
+		}
+		if len(m.sections) > 0 {
+			m.sectionPrompts = m.sectionPrompts[:len(m.sections)]
+			m.sectionRedactions += msg.Redactions
+			m.sectionUsage.InputTokens += msg.Usage.InputTokens
+			m.sectionUsage.OutputTokens += msg.Usage.OutputTokens
+			m.partial = true
+			return m.generateNextSection()
+		}
+		m.sectionPrompts, m.sections = nil, nil
+	}
+	if msg.Report == "" {
+		m.state = StatePromptSelect
+		return m, nil
+	}
+	m.partial = true
+	return m.showReport(msg.Report, m.reportNote(msg.Redactions, msg.Usage), false)
+}
+
+// normalizeHeadings shifts the headings of a generated report to
+// heading_level, or of a combined report's section to sit below its ##
+// heading
+func (m Model) normalizeHeadings(generated string) string {
+	switch {
+	case m.cfg.HeadingLevel == 0:
+		return generated
+	case m.sectionPrompts != nil:
+		return report.NormalizeHeadings(generated, 3)
+	}
+	return report.NormalizeHeadings(generated, m.cfg.HeadingLevel)
+}
+
+// generateNextSection starts generating the next section of a combined
+// report, or shows the report once every section is done
+func (m Model) generateNextSection() (tea.Model, tea.Cmd) {
+	// Metrics Only sections need no provider call
+	for len(m.sections) < len(m.sectionPrompts) && m.sectionPrompts[len(m.sections)].MetricsOnly {
+		m.sections = append(m.sections, m.metrics.FormatReport(m.selectedRange))
+	}
+
+	if len(m.sections) == len(m.sectionPrompts) {
+		var sb strings.Builder
+		for i, p := range m.sectionPrompts {
+			if i > 0 {
+				sb.WriteString("\n\n")
+			}
+			sb.WriteString("## " + p.Name + "\n\n" + strings.TrimSpace(m.sections[i]))
+		}
+		sb.WriteString("\n")
+		note := m.reportNote(m.sectionRedactions, m.sectionUsage)
+		hasMetrics := slices.ContainsFunc(m.sectionPrompts, func(p config.Prompt) bool { return p.MetricsOnly })
+		m.sectionPrompts = nil
+		m.sections = nil
+		return m.showReport(sb.String(), note, hasMetrics)
+	}
+
+	m.selectedPrompt = m.sectionPrompts[len(m.sections)]
+	m, err := m.usePromptModel(m.selectedPrompt)
+	if err != nil {
+		return m.showError(err, opGenerate)
+	}
+	return m.startGenerating(m.reportRequest())
+}
+
+// reportNote describes the redactions made and the cost of generating a
+// report, or "" if there is nothing to say
+func (m Model) reportNote(redactions int, usage llm.Usage) string {
+	var notes []string
+	if redactions > 0 {
+		notes = append(notes, fmt.Sprintf("%d redactions applied before sending", redactions))
+	}
+	if price, ok := m.modelPrice(); ok && usage != (llm.Usage{}) {
+		notes = append(notes, fmt.Sprintf("%s actual cost (%d input, %d output tokens)",
+			llm.FormatCost(llm.Cost(price, usage)), usage.InputTokens, usage.OutputTokens))
+	}
+	return strings.Join(notes, " • ")
+}
+
+// modelPrice returns the price of the Claude model generating the report.
+// Other providers have no known price.
+func (m Model) modelPrice() (config.ModelPrice, bool) {
+	return modelPrice(m.providerName, m.modelName, m.cfg.ModelPrices)
+}
+
+func modelPrice(provider, model string, overrides map[string]config.ModelPrice) (config.ModelPrice, bool) {
+	if provider != "claude" {
+		return config.ModelPrice{}, false
+	}
+	return llm.ModelPrice(model, overrides)
+}
+
+// promptConfig returns the config to generate with for p: the configured
+// provider and model, or those of the [models] entry named by its "model"
+// frontmatter
+func (m Model) promptConfig(p config.Prompt) (config.Config, error) {
+	return promptConfig(m.cfg, p)
+}
+
+func promptConfig(cfg config.Config, p config.Prompt) (config.Config, error) {
+	name := p.Frontmatter["model"]
+	if name == "" {
+		return cfg, nil
+	}
+	selected, err := cfg.SelectModel(name)
+	if err != nil {
+		return cfg, fmt.Errorf("prompt %q: %w", p.Name, err)
+	}
+	return selected, nil
+}
+
+// usePromptModel switches to the provider and model p generates with
+func (m Model) usePromptModel(p config.Prompt) (Model, error) {
+	// Never retry a previous prompt's request if this one can't start
+	m.generateCmd = nil
+	cfg, err := m.promptConfig(p)
+	if err != nil {
+		return m, err
+	}
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return m, err
+	}
+	m.llmProvider, m.providerName, m.modelName = provider, cfg.Provider, cfg.Model
+	return m, nil
+}
+
+// costEstimatesMsg carries the estimated cost of each prompt for the prompt
+// selection screen. id matches the latest request, so older estimates for
+// another model or prompt list are dropped.
+type costEstimatesMsg struct {
+	id        int
+	estimates map[string]string
+}
+
+// costEstimatesCmd estimates the cost of each prompt in the background, as
+// every estimate formats all the activity
+func (m *Model) costEstimatesCmd() tea.Cmd {
+	m.estimateID++
+	id, snapshot := m.estimateID, *m
+	return func() tea.Msg {
+		return costEstimatesMsg{id: id, estimates: snapshot.costEstimates()}
+	}
+}
+
+// costEstimates estimates the cost of generating a report with each prompt,
+// keyed by prompt name, leaving out prompts whose model has no known price
+func (m Model) costEstimates() map[string]string {
+	if m.metrics == nil {
+		return nil
+	}
+	estimates := make(map[string]string)
+	for _, p := range m.prompts {
+		if p.MetricsOnly {
+			continue
+		}
+		if _, ok := m.promptPrice(p); ok {
+			estimates[p.Name] = "~" + llm.FormatCost(m.promptCost(p))
+		}
+	}
+	return estimates
+}
+
+// promptPrice returns the price of the model p generates with, false if it
+// has no known price
+func (m Model) promptPrice(p config.Prompt) (config.ModelPrice, bool) {
+	cfg, err := m.promptConfig(p)
+	if err != nil {
+		return config.ModelPrice{}, false
+	}
+	return modelPrice(cfg.Provider, cfg.Model, cfg.ModelPrices)
+}
+
+// promptCost estimates the cost of generating a report with p, zero for
+// Metrics Only and for models without a known price
+func (m Model) promptCost(p config.Prompt) float64 {
+	price, ok := m.promptPrice(p)
+	if p.MetricsOnly || !ok {
+		return 0
+	}
+	systemPrompt, userMessage := llm.BuildMessages(m.prs, m.issues, m.reviews, m.commits, m.commentedItems, m.discussions, m.metrics, p.Content, m.formatOptions(p), m.cfg.SystemPrompt)
+	return llm.EstimateCost(price, systemPrompt, userMessage)
+}
+
+// confirmCost switches to the confirmation screen for request if its
+// estimated cost is above confirm_cost_above, reporting whether it did. Once
+// confirmed, request is sent again and goes ahead.
+func (m Model) confirmCost(cost float64, request tea.Msg) (Model, bool) {
+	if m.costConfirmed || m.cfg.ConfirmCostAbove <= 0 || cost <= m.cfg.ConfirmCostAbove {
+		m.costConfirmed = false
+		return m, false
+	}
+	m.pendingRequest, m.pendingCost = request, cost
+	m.state = StateConfirmCost
+	return m, true
+}
+
+// updateConfirmCost handles keys on the confirmation screen: y sends the
+// pending request, and n, Esc, or back returns to where it was made
+func (m Model) updateConfirmCost(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "y" || msg.String() == "Y":
+		m.costConfirmed = true
+		request := m.pendingRequest
+		m.pendingRequest = nil
+		return m.Update(request)
+	case msg.String() == "n" || msg.String() == "esc" || key.Matches(msg, keys.Map.Back):
+		m.state = StatePromptSelect
+		if _, ok := m.pendingRequest.(messageedit.SubmitMsg); ok {
+			m.state = StateEditMessage
+		}
+		m.pendingRequest = nil
+	case key.Matches(msg, keys.Map.Quit):
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// showPRList builds the activity list from the loaded data and switches to it
+func (m Model) showPRList() (tea.Model, tea.Cmd) {
+	m.prList = prlist.New(m.prs, m.issues, m.reviews, m.commits, m.commentedItems, m.discussions, m.metrics, m.cfg.DateFormat, m.cfg.RepoAliases, m.width, m.height)
+	m.prList.SetWarnings(m.warnings)
+	m.prList.SetAnonymized(m.anonymize)
+	m.state = StatePRList
+	return m, nil
+}
+
+// showError switches to the error screen for a failed operation
+func (m Model) showError(err error, op operation) (tea.Model, tea.Cmd) {
+	m.err = err
+	m.failedOp = op
+	m.state = StateError
+	return m, nil
+}
+
+// retry re-runs the operation that failed, reusing the stored range, data,
+// and prompt
+func (m Model) retry() (tea.Model, tea.Cmd) {
+	m.err = nil
+	if m.failedOp == opGenerate {
+		if m.generateCmd != nil {
+			return m.startGenerating(m.generateCmd)
+		}
+		// The request failed before it was sent, e.g. on an unknown model
+		// name, so start it again rather than fetching
+		if m.lastRequest != nil {
+			return m.Update(m.lastRequest)
+		}
+	}
+	m.loading = loading.New(fetchingMessage, m.cfg)
+	m.state = StateLoading
+	return m, m.fetchCmd()
+}
+
+// showReport displays a finished report, with an optional note in its
+// header. Unless the report already has the metrics, they are offered for
+// saving above it.
+func (m Model) showReport(generated, note string, hasMetrics bool) (tea.Model, tea.Cmd) {
+	m.generatedReport = generated
+	m.reportView = report.New(m.generatedReport, m.width, m.height)
+	m.reportView.SetNote(note)
+	m.reportView.SetPartial(m.partial)
+	m.reportView.SetOutputDir(m.cfg.OutputDir)
+	m.reportView.SetTOC(m.cfg.SaveTOC)
+	m.reportView.SetRendered(m.cfg.RenderMarkdown, m.darkBackground)
+	if bundle, err := marshalBundle(m.bundle(generated)); err == nil {
+		m.reportView.SetBundle(bundle)
+	}
+	if m.metrics != nil {
+		m.reportView.SetBadge(m.metrics.Badge(m.darkBadge))
+		if !hasMetrics {
+			section := "## Metrics Summary\n\n" + m.selectedRange.Format(m.cfg.DateFormat) + "\n\n" + m.metrics.FormatMarkdown()
+			m.reportView.SetMetrics(section, m.cfg.SaveMetrics)
+		}
+	}
+	m.state = StateReport
+	return m, nil
+}
+
+// analyticsOptions builds the analytics options from config
+func (m Model) analyticsOptions() analytics.Options {
+	return analyticsOptions(m.cfg)
+}
+
+func analyticsOptions(cfg config.Config) analytics.Options {
+	// The cap, hours, and timezone were checked by Validate at startup
+	mergeTimeCap, _ := cfg.MergeTimeCapDuration()
+	workdayStart, workdayEnd, _ := cfg.WorkingHoursRange()
+	location, _ := cfg.Location()
+	return analytics.Options{
+		WorkingDays:     cfg.WorkingWeekdays(),
+		MergeTimeCap:    mergeTimeCap,
+		ClampMergeTimes: cfg.MergeTimeCapMode == "clamp",
+		MinRepoActivity: cfg.MinRepoActivity,
+		RepoSort:        cfg.RepoSort,
+		TopPRs:          cfg.TopPRs,
+		TopPRsBy:        cfg.TopPRsBy,
+		WorkdayStart:    workdayStart,
+		WorkdayEnd:      workdayEnd,
+		Location:        location,
+		DateFormat:      cfg.DateFormat,
+		RepoAliases:     cfg.RepoAliases,
+		Weights: &analytics.ActivityWeights{
+			PRs:     cfg.ActivityWeight("prs"),
+			Issues:  cfg.ActivityWeight("issues"),
+			Reviews: cfg.ActivityWeight("reviews"),
+			Commits: cfg.ActivityWeight("commits"),
+		},
+	}
+}
+
+// formatOptions builds the LLM formatting options from config and p's
+// frontmatter, with the anonymized focus repos when anonymizing
+func (m Model) formatOptions(p config.Prompt) github.FormatOptions {
+	cfg := m.cfg
+	if m.anonymize {
+		cfg.FocusRepos = m.focusRepos
+	}
+	return formatOptions(cfg, p, m.metrics)
+}
+
+func formatOptions(cfg config.Config, prompt config.Prompt, metrics *analytics.Metrics) github.FormatOptions {
+	opts := github.DefaultFormatOptions()
+	opts.FocusRepos = cfg.FocusRepos
+	opts.FullCommitMessages = cfg.FullCommitMessages
+	opts.MaxItemsPerType = cfg.MaxItemsPerType
+	opts.MaxPromptChars = cfg.MaxPromptChars
+	opts.Preamble = cfg.ReportPreamble
+	opts.OldestFirst = cfg.ActivityOrder == "oldest"
+	opts.DateFormat = cfg.DateFormat
+	opts.RepoAliases = cfg.RepoAliases
+	if metrics != nil {
+		for _, pr := range metrics.TopPRs {
+			opts.Highlights = append(opts.Highlights, analytics.DescribePR(pr, metrics.TopPRsBy, cfg.RepoAliases))
+		}
+	}
+
+	for _, heading := range cfg.LabelSectionHeadings() {
+		opts.LabelSections = append(opts.LabelSections, github.LabelSection{Heading: heading, Labels: cfg.LabelSections[heading]})
+	}
+
+	fm := prompt.Frontmatter
+	opts.GroupByLabel = strings.EqualFold(fm["group_by"], "labels")
+	if sections, ok := fm["sections"]; ok {
+		opts = opts.IncludeOnly(strings.Split(sections, ","))
+	}
+	if n, err := strconv.Atoi(fm["max_body_length"]); err == nil && n > 0 {
+		opts.MaxBodyLength = n
+	}
+	return opts
+}
+
+// fetchOptions builds the GitHub fetch options from config
+func (m Model) fetchOptions() github.FetchOptions {
+	opts := fetchOptions(m.cfg)
+	// Imported activity needed no GitHub access, so gh wasn't checked at startup
+	opts.CheckAuth = m.imported != nil
+	return opts
+}
+
+func fetchOptions(cfg config.Config) github.FetchOptions {
+	return github.FetchOptions{
+		Limit:               cfg.GitHubSearchLimit,
+		ResolveMergeStatus:  cfg.ResolveMergeStatus,
+		CommentExcerpts:     cfg.CommentExcerpts,
+		ReviewExcerpts:      cfg.ReviewExcerpts,
+		ResolveLinkedIssues: cfg.ResolveLinkedIssues,
+		ResolvePRSizes:      cfg.TopPRs > 0 && cfg.TopPRsBy == analytics.TopPRsBySize,
+		ResolveCommitStats:  cfg.ResolveCommitStats,
+		AssignedIssues:      cfg.AssignedIssues,
+		ExcludeForks:        cfg.ExcludeForks,
+		ExcludeArchived:     cfg.ExcludeArchived,
+		OwnRepos:            cfg.OwnRepos,
+		Concurrency:         cfg.FetchConcurrency,
+		Retries:             cfg.FetchRetries,
+		Members:             cfg.TeamMembers,
+		OldestFirst:         cfg.ActivityOrder == "oldest",
+		Discussions:         cfg.FetchDiscussions,
+	}
+}
+
 // updateCurrentState delegates update to the current state's model
 func (m Model) updateCurrentState(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
@@ -187,10 +1065,21 @@ func (m Model) updateCurrentState(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.prList, cmd = m.prList.Update(msg)
 	case StatePromptSelect:
 		m.promptSelect, cmd = m.promptSelect.Update(msg)
+	case StateEditMessage:
+		m.messageEdit, cmd = m.messageEdit.Update(msg)
 	case StateReport:
 		m.reportView, cmd = m.reportView.Update(msg)
+	case StateSelectModel:
+		m.modelSelect, cmd = m.modelSelect.Update(msg)
+	case StateConfirmCost:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.updateConfirmCost(msg)
+		}
 	case StateError:
-		if _, ok := msg.(tea.KeyMsg); ok {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			if key.Matches(msg, keys.Map.Retry) {
+				return m.retry()
+			}
 			m.state = StateSelectDate
 			m.err = nil
 		}
@@ -210,8 +1099,14 @@ func (m Model) View() string {
 		return m.prList.View()
 	case StatePromptSelect:
 		return m.promptSelect.View()
+	case StateEditMessage:
+		return m.messageEdit.View()
 	case StateReport:
 		return m.reportView.View()
+	case StateSelectModel:
+		return m.modelSelect.View()
+	case StateConfirmCost:
+		return m.renderConfirmCost()
 	case StateError:
 		return m.renderError()
 	default:
@@ -219,12 +1114,28 @@ func (m Model) View() string {
 	}
 }
 
+// renderConfirmCost renders the confirmation asked for before an expensive report
+func (m Model) renderConfirmCost() string {
+	s := styles.TitleStyle.Render("Confirm Cost")
+	s += "\n\n"
+	s += fmt.Sprintf("Generating this report is estimated to cost ~%s, above your confirm_cost_above of %s.",
+		llm.FormatCost(m.pendingCost), llm.FormatCost(m.cfg.ConfirmCostAbove))
+	s += "\n\n" + styles.SubtleStyle.Render("The estimate covers the input sent to the model. A shorter range, fewer sections, or a cheaper model costs less.")
+	s += "\n\n"
+	s += styles.FooterStyle.Render(fmt.Sprintf("y: Generate • n/esc: Back • %s: Quit", keys.Map.Quit.Help().Key))
+	return s
+}
+
 // renderError renders the error state
 func (m Model) renderError() string {
 	s := styles.TitleStyle.Render("Error")
 	s += "\n\n"
 	s += styles.ErrorStyle.Render(m.err.Error())
+	var rateErr *github.ErrRateLimited
+	if errors.As(m.err, &rateErr) {
+		s += "\n\n" + styles.SubtleStyle.Render("GitHub limits how often activity can be searched. Retry once the limit resets, or try a shorter range.")
+	}
 	s += "\n\n"
-	s += styles.FooterStyle.Render("Press any key to return to date selection")
+	s += styles.FooterStyle.Render(fmt.Sprintf("%s: Retry • Any other key: Return to date selection", keys.Map.Retry.Help().Key))
 	return s
 }
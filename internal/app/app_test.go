@@ -0,0 +1,493 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/burritocatai/activitycat/internal/analytics"
+	"github.com/burritocatai/activitycat/internal/config"
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/github"
+	"github.com/burritocatai/activitycat/internal/llm"
+	"github.com/burritocatai/activitycat/internal/ui/messageedit"
+	"github.com/burritocatai/activitycat/internal/ui/prlist"
+	"github.com/burritocatai/activitycat/internal/ui/promptselect"
+)
+
+// fakeProvider records the messages it is sent and replies with report, or
+// with the next of errs while there are any
+type fakeProvider struct {
+	report   string
+	errs     []error
+	messages []string
+}
+
+func (p *fakeProvider) GenerateReport(ctx context.Context, systemPrompt, userMessage string) (string, llm.Usage, error) {
+	p.messages = append(p.messages, userMessage)
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		return "", llm.Usage{}, err
+	}
+	return p.report, llm.Usage{}, nil
+}
+
+// testConfig returns a config for a Claude model with a known price
+func testConfig() config.Config {
+	return config.Config{Provider: "claude", Model: "claude-sonnet-4-5"}
+}
+
+// testModel returns an app with a little activity loaded, on the prompt
+// selection screen, generating with a fake provider
+func testModel(t *testing.T, cfg config.Config) (Model, *fakeProvider) {
+	t.Helper()
+	// Keep the user's own prompts out of the list
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	provider := &fakeProvider{report: "# Report"}
+	newProvider = func(config.Config) (llm.Provider, error) { return provider, nil }
+	t.Cleanup(func() { newProvider = llm.NewProvider })
+
+	m := New(cfg, Options{})
+	m.width, m.height = 100, 40
+	m.selectedRange = daterange.Range{
+		Start: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC),
+	}
+	m.prs = []github.PullRequest{{
+		Number:     1,
+		Title:      "Add caching",
+		State:      "merged",
+		CreatedAt:  m.selectedRange.Start.AddDate(0, 0, 2),
+		Repository: github.Repository{Name: "web", NameWithOwner: "acme/web"},
+	}}
+	m.metrics = analytics.Compute(m.prs, nil, nil, nil, nil, nil, m.selectedRange, m.analyticsOptions())
+	m.state = StatePromptSelect
+	return m, provider
+}
+
+// update sends msg to m and returns the updated app model
+func update(t *testing.T, m Model, msg tea.Msg) Model {
+	t.Helper()
+	next, _ := m.Update(msg)
+	return next.(Model)
+}
+
+// finishGenerating runs the pending generation request and delivers its
+// result, as the program would once the provider replied
+func finishGenerating(t *testing.T, m Model) Model {
+	t.Helper()
+	if m.state != StateGenerating {
+		t.Fatalf("state = %v, want StateGenerating", m.state)
+	}
+	return update(t, m, m.generateCmd(context.Background())())
+}
+
+func TestEditedMessageIsSent(t *testing.T) {
+	m, provider := testModel(t, testConfig())
+
+	m = update(t, m, messageedit.SubmitMsg{Message: "My edited message"})
+	m = finishGenerating(t, m)
+
+	if len(provider.messages) != 1 || provider.messages[0] != "My edited message" {
+		t.Errorf("provider was sent %q, want the edited message", provider.messages)
+	}
+	if m.state != StateReport {
+		t.Errorf("state = %v, want StateReport", m.state)
+	}
+}
+
+func TestMetricsOnlySkipsProvider(t *testing.T) {
+	m, provider := testModel(t, testConfig())
+
+	m = update(t, m, promptselect.PromptSelectedMsg{Prompt: config.MetricsOnlyPrompt})
+
+	if len(provider.messages) != 0 {
+		t.Errorf("provider was sent %d messages, want none", len(provider.messages))
+	}
+	if m.state != StateReport {
+		t.Errorf("state = %v, want StateReport", m.state)
+	}
+	if !strings.Contains(m.generatedReport, "PRs") {
+		t.Errorf("report doesn't hold the metrics:\n%s", m.generatedReport)
+	}
+}
+
+func TestSummaryOffersOnlyMetricsOnly(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m := New(testConfig(), Options{Prompt: &config.MetricsOnlyPrompt})
+	if len(m.prompts) != 1 || !m.prompts[0].MetricsOnly {
+		t.Errorf("prompts = %v, want only Metrics Only", m.prompts)
+	}
+}
+
+func TestHeadlessMetricsOnlySkipsProvider(t *testing.T) {
+	m, _ := testModel(t, testConfig())
+	// A provider that can't be created fails any report needing one
+	newProvider = func(config.Config) (llm.Provider, error) { return nil, errors.New("no provider") }
+	cfg := testConfig()
+	activity := github.ActivityLoadedMsg{PRs: m.prs}
+
+	report, _, err := GenerateReport(cfg, Options{Range: &m.selectedRange, Prompt: &config.MetricsOnlyPrompt, Activity: &activity})
+	if err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if report == "" {
+		t.Error("GenerateReport returned an empty report")
+	}
+}
+
+func TestHeadlessReadPrompt(t *testing.T) {
+	m, provider := testModel(t, testConfig())
+	prompt, err := config.ReadPrompt(strings.NewReader("List my three biggest wins."), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	activity := github.ActivityLoadedMsg{PRs: m.prs}
+
+	if _, _, err := GenerateReport(testConfig(), Options{Range: &m.selectedRange, Prompt: &prompt, Activity: &activity}); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if len(provider.messages) != 1 || !strings.Contains(provider.messages[0], "List my three biggest wins.") {
+		t.Errorf("provider was sent %q, want the piped prompt", provider.messages)
+	}
+}
+
+// retryKey is the key message for the default retry binding
+var retryKey = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}
+
+func TestRetryFailedFetch(t *testing.T) {
+	m, _ := testModel(t, testConfig())
+	selected := m.selectedRange
+	m.state = StateLoading
+
+	m = update(t, m, github.ActivityLoadedMsg{Error: errors.New("gh command failed: boom")})
+	if m.state != StateError || m.failedOp != opFetch {
+		t.Fatalf("state = %v, failedOp = %v, want StateError after a failed fetch", m.state, m.failedOp)
+	}
+
+	next, cmd := m.Update(retryKey)
+	m = next.(Model)
+	if m.state != StateLoading || cmd == nil {
+		t.Errorf("state = %v after retrying a fetch, want StateLoading with a fetch running", m.state)
+	}
+	if m.selectedRange != selected {
+		t.Errorf("retried fetch is for %v, want the stored range %v", m.selectedRange, selected)
+	}
+}
+
+func TestRetryFailedGeneration(t *testing.T) {
+	m, provider := testModel(t, testConfig())
+	provider.errs = []error{errors.New("overloaded")}
+
+	m = update(t, m, messageedit.SubmitMsg{Message: "Summarize my week."})
+	m = finishGenerating(t, m)
+	if m.state != StateError || m.failedOp != opGenerate {
+		t.Fatalf("state = %v, failedOp = %v, want StateError after a failed generation", m.state, m.failedOp)
+	}
+
+	m = finishGenerating(t, update(t, m, retryKey))
+	if m.state != StateReport || m.generatedReport == "" {
+		t.Errorf("state = %v after retrying generation, want the report", m.state)
+	}
+	if len(provider.messages) != 2 || provider.messages[1] != "Summarize my week." {
+		t.Errorf("retry sent %q, want the same message again", provider.messages)
+	}
+}
+
+func TestPromptModelName(t *testing.T) {
+	cfg := testConfig()
+	cfg.Models = map[string]config.ModelAlias{"fast": {Model: "claude-haiku-4-5"}}
+	m, provider := testModel(t, cfg)
+	var used config.Config
+	newProvider = func(cfg config.Config) (llm.Provider, error) {
+		used = cfg
+		return provider, nil
+	}
+
+	prompt := config.Prompt{Name: "weekly", Content: "Summarize my week.", Frontmatter: map[string]string{"model": "fast"}}
+	m = finishGenerating(t, update(t, m, promptselect.PromptSelectedMsg{Prompt: prompt}))
+	if used.Model != "claude-haiku-4-5" || m.modelName != "claude-haiku-4-5" {
+		t.Errorf("generated with %q, want the fast model claude-haiku-4-5", used.Model)
+	}
+
+	// An unknown name fails generation, and retrying tries it again
+	// rather than fetching
+	prompt.Frontmatter["model"] = "deep"
+	m = update(t, m, promptselect.PromptSelectedMsg{Prompt: prompt})
+	if m.state != StateError || m.failedOp != opGenerate || !strings.Contains(m.err.Error(), `unknown model name "deep"`) {
+		t.Fatalf("state = %v, err = %v, want StateError for the unknown model name", m.state, m.err)
+	}
+	m = update(t, m, retryKey)
+	if m.state != StateError || m.failedOp != opGenerate {
+		t.Errorf("state = %v, failedOp = %v after retrying, want the generation to fail again", m.state, m.failedOp)
+	}
+}
+
+func TestSwitchModel(t *testing.T) {
+	cfg := testConfig()
+	cfg.Models = map[string]config.ModelAlias{"fast": {Model: "claude-haiku-4-5"}}
+	m, provider := testModel(t, cfg)
+	var used []config.Config
+	newProvider = func(cfg config.Config) (llm.Provider, error) {
+		used = append(used, cfg)
+		return provider, nil
+	}
+
+	// Generation fails, then the model is switched before retrying
+	provider.errs = []error{errors.New("overloaded")}
+	m = finishGenerating(t, update(t, m, promptselect.PromptSelectedMsg{Prompt: config.Prompt{Name: "weekly", Content: "Summarize my week."}}))
+	if m.state != StateError {
+		t.Fatalf("state = %v, want StateError", m.state)
+	}
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlP})
+	if m.state != StateSelectModel {
+		t.Fatalf("state = %v after ctrl+p, want StateSelectModel", m.state)
+	}
+	switched := cfg
+	switched.Model = "claude-haiku-4-5"
+	m = update(t, m, providerCheckedMsg{Config: switched})
+	if m.state != StateError || m.modelName != "claude-haiku-4-5" {
+		t.Fatalf("state = %v, model = %q after switching, want StateError with claude-haiku-4-5", m.state, m.modelName)
+	}
+
+	used = nil
+	m = finishGenerating(t, update(t, m, retryKey))
+	if m.state != StateReport {
+		t.Errorf("state = %v after retrying, want the report", m.state)
+	}
+	if len(used) == 0 || used[len(used)-1].Model != "claude-haiku-4-5" {
+		t.Errorf("retry created providers for %v, want one for claude-haiku-4-5", used)
+	}
+}
+
+func TestAbandonProviderCheck(t *testing.T) {
+	cfg := testConfig()
+	cfg.Models = map[string]config.ModelAlias{"fast": {Model: "claude-haiku-4-5"}}
+	m, _ := testModel(t, cfg)
+
+	// Choosing fast starts checking it, and back leaves before it finishes
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlP})
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = update(t, next.(Model), cmd())
+	switched := cfg
+	switched.Model = "claude-haiku-4-5"
+	late := providerCheckedMsg{id: m.checkID, Config: switched}
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("esc while checking did nothing")
+	}
+	m = update(t, next.(Model), cmd())
+	if m.state != StatePromptSelect {
+		t.Fatalf("state = %v after esc, want StatePromptSelect", m.state)
+	}
+
+	// The abandoned check's result is dropped, even once the switcher is
+	// open again
+	for _, reopen := range []bool{false, true} {
+		if reopen {
+			m = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlP})
+		}
+		m = update(t, m, late)
+		if m.modelName != cfg.Model {
+			t.Errorf("reopened %v: model = %q after a late check, want %q", reopen, m.modelName, cfg.Model)
+		}
+	}
+}
+
+func TestSettingsKeyInMessageEditor(t *testing.T) {
+	m, _ := testModel(t, testConfig())
+	m = update(t, m, promptselect.PromptSelectedMsg{Prompt: config.Prompt{Name: "weekly", Content: "Summarize my week."}, Edit: true})
+	if m.state != StateEditMessage {
+		t.Fatalf("state = %v, want StateEditMessage", m.state)
+	}
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlP})
+	if m.state != StateEditMessage {
+		t.Errorf("state = %v after ctrl+p in the editor, want it left to the editor", m.state)
+	}
+}
+
+func TestGenerationContextReleased(t *testing.T) {
+	m, _ := testModel(t, testConfig())
+	m = update(t, m, promptselect.PromptSelectedMsg{Prompt: config.Prompt{Name: "weekly", Content: "Summarize my week."}})
+	released := false
+	m.cancelGenerate = func() { released = true }
+
+	m = finishGenerating(t, m)
+	if m.state != StateReport || !released {
+		t.Errorf("state = %v, released = %v after generating, want the report with its context released", m.state, released)
+	}
+}
+
+func TestCombinedReportSections(t *testing.T) {
+	m, provider := testModel(t, testConfig())
+	prompts := []config.Prompt{
+		{Name: "accomplishments", Content: "List my accomplishments."},
+		{Name: "improvements", Content: "List areas to improve."},
+	}
+
+	m = update(t, m, promptselect.PromptsSelectedMsg{Prompts: prompts})
+	m = finishGenerating(t, m)
+	m = finishGenerating(t, m)
+
+	if m.state != StateReport {
+		t.Fatalf("state = %v, want StateReport", m.state)
+	}
+	if len(provider.messages) != 2 {
+		t.Fatalf("provider was sent %d messages, want one per section", len(provider.messages))
+	}
+	for i, p := range prompts {
+		if !strings.Contains(provider.messages[i], p.Content) {
+			t.Errorf("message %d doesn't hold prompt %q", i, p.Name)
+		}
+	}
+	first := strings.Index(m.generatedReport, "## accomplishments")
+	second := strings.Index(m.generatedReport, "## improvements")
+	if first < 0 || second < first {
+		t.Errorf("report sections are missing or out of order:\n%s", m.generatedReport)
+	}
+}
+
+func TestReloadedPromptsWarn(t *testing.T) {
+	m, _ := testModel(t, testConfig())
+	m = update(t, m, prlist.ContinueMsg{})
+
+	// A prompt file sharing a name with another is added while editing
+	promptsDir, err := config.PromptsDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(promptsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"standup.md", "standup.txt"} {
+		if err := os.WriteFile(filepath.Join(promptsDir, name), []byte("What did I do yesterday?"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m = update(t, m, promptselect.PromptsChangedMsg{Select: "standup"})
+	if view := m.promptSelect.View(); !strings.Contains(view, "Prompt file standup.txt was skipped") {
+		t.Errorf("prompt selection doesn't warn about standup.txt after reloading:\n%s", view)
+	}
+}
+
+func TestCostEstimatesComputedInCmd(t *testing.T) {
+	m, _ := testModel(t, testConfig())
+	m.state = StatePRList
+
+	next, cmd := m.Update(prlist.ContinueMsg{})
+	m = next.(Model)
+	if cmd == nil {
+		t.Fatal("continuing to prompt selection started no estimate")
+	}
+	first := cmd()
+	estimate := first.(costEstimatesMsg).estimates[m.prompts[0].Name]
+	if !strings.HasPrefix(estimate, "~") {
+		t.Fatalf("estimate for %q = %q, want a cost", m.prompts[0].Name, estimate)
+	}
+
+	// Estimates from before the prompts were reloaded are dropped
+	next, cmd = m.Update(promptselect.PromptsChangedMsg{})
+	m = next.(Model)
+	before := m.promptSelect.View()
+	if m = update(t, m, first); m.promptSelect.View() != before {
+		t.Error("stale estimates were shown")
+	}
+	m = update(t, m, cmd())
+	if !strings.Contains(m.promptSelect.View(), estimate) {
+		t.Errorf("prompt selection doesn't show the estimate %q:\n%s", estimate, m.promptSelect.View())
+	}
+}
+
+func TestConfirmCostThreshold(t *testing.T) {
+	full := config.Prompt{Name: "weekly", Content: "Summarize my week."}
+	issuesOnly := config.Prompt{Name: "issues", Content: "Summarize my issues.", Frontmatter: map[string]string{"sections": "issues"}}
+	m, _ := testModel(t, testConfig())
+	fullCost, issuesCost := m.promptCost(full), m.promptCost(issuesOnly)
+	if fullCost <= issuesCost {
+		t.Fatalf("cost with PRs = %v, want it above the issues-only cost %v", fullCost, issuesCost)
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		request   tea.Msg
+		want      State
+	}{
+		{"under", fullCost * 2, promptselect.PromptSelectedMsg{Prompt: full}, StateGenerating},
+		{"over", fullCost / 2, promptselect.PromptSelectedMsg{Prompt: full}, StateConfirmCost},
+		{"combined under", fullCost + issuesCost + 1, promptselect.PromptsSelectedMsg{Prompts: []config.Prompt{full, issuesOnly}}, StateGenerating},
+		{"combined over", fullCost + issuesCost/2, promptselect.PromptsSelectedMsg{Prompts: []config.Prompt{full, issuesOnly}}, StateConfirmCost},
+		// Each prompt is estimated with its own sections, not those of the
+		// prompt selected last time
+		{"other prompt", (fullCost + issuesCost) / 2, promptselect.PromptsSelectedMsg{Prompts: []config.Prompt{full}}, StateConfirmCost},
+	}
+	for _, tt := range tests {
+		cfg := testConfig()
+		cfg.ConfirmCostAbove = tt.threshold
+		m, _ := testModel(t, cfg)
+		m.selectedPrompt = issuesOnly
+		if m = update(t, m, tt.request); m.state != tt.want {
+			t.Errorf("%s the threshold: state = %v, want %v", tt.name, m.state, tt.want)
+		}
+	}
+}
+
+func TestComparisonWarningsShownOnce(t *testing.T) {
+	m, _ := testModel(t, testConfig())
+	warning := "Could not check for forked or archived repos, so none were excluded: boom"
+	m.warnings = []string{warning}
+
+	m = update(t, m, github.ComparisonLoadedMsg{Warnings: []string{warning, "Could not look up your login"}})
+
+	if len(m.warnings) != 2 || m.warnings[1] != "Could not look up your login" {
+		t.Errorf("warnings = %q, want the new warning added once", m.warnings)
+	}
+}
+
+func TestAnonymizedFocusRepos(t *testing.T) {
+	cfg := testConfig()
+	cfg.FocusRepos = []string{"acme/web"}
+	m, provider := testModel(t, cfg)
+	m.anonymize = true
+	m.state = StateLoading
+
+	m = update(t, m, github.ActivityLoadedMsg{PRs: m.prs})
+	m.state = StatePromptSelect
+	m = finishGenerating(t, update(t, m, promptselect.PromptSelectedMsg{Prompt: config.Prompt{Name: "weekly", Content: "Summarize my week."}}))
+
+	if len(provider.messages) != 1 {
+		t.Fatalf("provider was sent %d messages, want 1", len(provider.messages))
+	}
+	if strings.Contains(provider.messages[0], "acme/web") {
+		t.Errorf("the real repo name was sent:\n%s", provider.messages[0])
+	}
+	if !strings.Contains(provider.messages[0], "Focus repositories: repo-1.") {
+		t.Errorf("the focus repo wasn't sent as its placeholder:\n%s", provider.messages[0])
+	}
+}
+
+func TestConfigAcceptsAnalyticsKeys(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := config.LoadConfig()
+	for _, key := range analytics.RepoSortKeys {
+		cfg.RepoSort = key
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("repo_sort %q: %v", key, err)
+		}
+	}
+	cfg.RepoSort = ""
+	for _, by := range []string{analytics.TopPRsByComments, analytics.TopPRsBySize} {
+		cfg.TopPRsBy = by
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("top_prs_by %q: %v", by, err)
+		}
+	}
+}
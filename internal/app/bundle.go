@@ -0,0 +1,57 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/analytics"
+	"github.com/burritocatai/activitycat/internal/daterange"
+)
+
+// Bundle is a generated report with everything needed to make sense of it
+// later: the range, the prompt, the provider, and the metrics
+type Bundle struct {
+	Report      string             `json:"report"`
+	Range       daterange.Range    `json:"range"`
+	Prompt      BundlePrompt       `json:"prompt"`
+	Provider    string             `json:"provider,omitempty"`
+	Model       string             `json:"model,omitempty"`
+	GeneratedAt time.Time          `json:"generatedAt"`
+	Metrics     *analytics.Metrics `json:"metrics,omitempty"`
+}
+
+// BundlePrompt is the prompt a bundled report was generated with
+type BundlePrompt struct {
+	Name    string `json:"name"`
+	Content string `json:"content,omitempty"`
+}
+
+// bundle returns the bundle for a report generated from the current
+// activity and prompt. Metrics Only reports record no provider.
+func (m Model) bundle(generated string) Bundle {
+	b := Bundle{
+		Report:      generated,
+		Range:       m.selectedRange,
+		Prompt:      BundlePrompt{Name: m.selectedPrompt.Name, Content: m.selectedPrompt.Content},
+		GeneratedAt: time.Now(),
+		Metrics:     m.metrics,
+	}
+	if !m.selectedPrompt.MetricsOnly {
+		b.Provider, b.Model = m.providerName, m.modelName
+	}
+	return b
+}
+
+// marshalBundle encodes b as indented JSON, leaving characters like < in
+// the report as they are
+func marshalBundle(b Bundle) (string, error) {
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/burritocatai/activitycat/internal/config"
+	"github.com/burritocatai/activitycat/internal/ui/promptselect"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	m, provider := testModel(t, testConfig())
+	provider.report = "# Weekly\n\n- Shipped <Cache> & a faster login"
+	m = finishGenerating(t, update(t, m, promptselect.PromptSelectedMsg{Prompt: config.Prompt{Name: "weekly", Content: "Summarize my week."}}))
+
+	data, err := marshalBundle(m.bundle(m.generatedReport))
+	if err != nil {
+		t.Fatalf("marshalBundle: %v", err)
+	}
+	var got Bundle
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("the bundle doesn't parse: %v\n%s", err, data)
+	}
+
+	if got.Report != provider.report {
+		t.Errorf("Report = %q, want %q", got.Report, provider.report)
+	}
+	if !got.Range.Start.Equal(m.selectedRange.Start) || !got.Range.End.Equal(m.selectedRange.End) {
+		t.Errorf("Range = %v, want %v", got.Range, m.selectedRange)
+	}
+	if got.Prompt != (BundlePrompt{Name: "weekly", Content: "Summarize my week."}) {
+		t.Errorf("Prompt = %+v, want the weekly prompt", got.Prompt)
+	}
+	if got.Provider != "claude" || got.Model != "claude-sonnet-4-5" {
+		t.Errorf("provider = %s/%s, want claude/claude-sonnet-4-5", got.Provider, got.Model)
+	}
+	if got.GeneratedAt.IsZero() {
+		t.Error("GeneratedAt is not set")
+	}
+	if got.Metrics == nil || got.Metrics.PRsOpened != m.metrics.PRsOpened {
+		t.Errorf("Metrics = %+v, want PRsOpened %d", got.Metrics, m.metrics.PRsOpened)
+	}
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/analytics"
+	"github.com/burritocatai/activitycat/internal/config"
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/github"
+	"github.com/burritocatai/activitycat/internal/llm"
+	"github.com/burritocatai/activitycat/internal/ui/report"
+)
+
+// FetchActivity fetches activity for the range without running the TUI
+func FetchActivity(cfg config.Config, r daterange.Range) (github.ActivityLoadedMsg, error) {
+	msg := github.FetchActivityCmd(r, fetchOptions(cfg))().(github.ActivityLoadedMsg)
+	if msg.Error != nil {
+		return msg, msg.Error
+	}
+	return msg, nil
+}
+
+// ExportJSONLines fetches activity for the range and writes it to w as JSON
+// Lines, with names replaced by placeholders if anonymize is set
+func ExportJSONLines(cfg config.Config, r daterange.Range, w io.Writer, anonymize bool) error {
+	msg, err := FetchActivity(cfg, r)
+	if err != nil {
+		return err
+	}
+	if anonymize {
+		msg.Anonymize(nil)
+	}
+	if err := github.WriteJSONLines(w, msg.PRs, msg.Issues, msg.Reviews, msg.Commits, msg.CommentedItems, msg.Discussions); err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	return nil
+}
+
+// GenerateReport fetches activity for opts.Range, or uses opts.Activity, and
+// generates a report with opts.Prompt without running the TUI. It returns the
+// report along with any startup and fetch warnings.
+func GenerateReport(cfg config.Config, opts Options) (string, []string, error) {
+	generated, _, warnings, err := generateReport(cfg, opts)
+	return generated, warnings, err
+}
+
+// generateReport is GenerateReport, also returning the metrics the report
+// was generated from
+func generateReport(cfg config.Config, opts Options) (string, *analytics.Metrics, []string, error) {
+	if opts.Range == nil || opts.Prompt == nil {
+		return "", nil, nil, fmt.Errorf("a range and a prompt are required")
+	}
+	r, prompt := *opts.Range, *opts.Prompt
+
+	var activity github.ActivityLoadedMsg
+	if opts.Activity != nil {
+		activity = *opts.Activity
+	} else {
+		msg, err := FetchActivity(cfg, r)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		activity = msg
+	}
+	if opts.Anonymize {
+		cfg.FocusRepos = activity.Anonymize(cfg.FocusRepos)
+	}
+	warnings := append(append([]string(nil), opts.Warnings...), activity.Warnings...)
+
+	metrics := analytics.Compute(activity.PRs, activity.Issues, activity.Reviews, activity.Commits, activity.CommentedItems, activity.Discussions, r, analyticsOptions(cfg))
+	if prompt.MetricsOnly {
+		return metrics.FormatReport(r), metrics, warnings, nil
+	}
+
+	cfg, err := promptConfig(cfg, prompt)
+	if err != nil {
+		return "", metrics, warnings, err
+	}
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return "", metrics, warnings, err
+	}
+	// Patterns were checked by Validate at startup
+	redactor, _ := llm.NewRedactor(cfg.Redactions)
+	formatOpts := formatOptions(cfg, prompt, metrics)
+
+	if price, ok := modelPrice(cfg.Provider, cfg.Model, cfg.ModelPrices); ok && opts.ConfirmCost != nil && cfg.ConfirmCostAbove > 0 {
+		systemPrompt, userMessage := llm.BuildMessages(activity.PRs, activity.Issues, activity.Reviews, activity.Commits, activity.CommentedItems, activity.Discussions, metrics, prompt.Content, formatOpts, cfg.SystemPrompt)
+		if cost := llm.EstimateCost(price, systemPrompt, userMessage); cost > cfg.ConfirmCostAbove && !opts.ConfirmCost(cost) {
+			return "", metrics, warnings, fmt.Errorf("not generated: the estimated cost of ~%s is above confirm_cost_above (%s)", llm.FormatCost(cost), llm.FormatCost(cfg.ConfirmCostAbove))
+		}
+	}
+
+	msg := llm.GenerateReportCmd(context.Background(), provider, redactor, activity.PRs, activity.Issues, activity.Reviews, activity.Commits, activity.CommentedItems, activity.Discussions, metrics, prompt.Content, formatOpts, cfg.SystemPrompt)().(llm.ReportGeneratedMsg)
+	if msg.Error != nil {
+		return "", metrics, warnings, msg.Error
+	}
+	return report.NormalizeHeadings(msg.Report, cfg.HeadingLevel), metrics, warnings, nil
+}
+
+// ImportJSONLines reads activity previously written with ExportJSONLines.
+// The returned range spans the earliest to latest activity timestamp.
+func ImportJSONLines(path string) (github.ActivityLoadedMsg, daterange.Range, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return github.ActivityLoadedMsg{}, daterange.Range{}, fmt.Errorf("could not open import file: %w", err)
+	}
+	defer f.Close()
+
+	msg, err := github.ReadJSONLines(f)
+	if err != nil {
+		return github.ActivityLoadedMsg{}, daterange.Range{}, fmt.Errorf("import failed: %w", err)
+	}
+	return msg, activitySpan(msg), nil
+}
+
+// activitySpan returns the range covered by the activity's timestamps, or the
+// last week if there are none
+func activitySpan(msg github.ActivityLoadedMsg) daterange.Range {
+	var r daterange.Range
+	add := func(t time.Time) {
+		if t.IsZero() {
+			return
+		}
+		if r.Start.IsZero() || t.Before(r.Start) {
+			r.Start = t
+		}
+		if t.After(r.End) {
+			r.End = t
+		}
+	}
+
+	for _, pr := range msg.PRs {
+		add(pr.CreatedAt)
+	}
+	for _, issue := range msg.Issues {
+		add(issue.CreatedAt)
+		if issue.ClosedAt != nil {
+			add(*issue.ClosedAt)
+		}
+	}
+	for _, r := range msg.Reviews {
+		add(r.CreatedAt)
+	}
+	for _, c := range msg.Commits {
+		add(c.Commit.Author.Date)
+	}
+
+	if r.Start.IsZero() {
+		return daterange.LastWeek()
+	}
+	return r
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/analytics"
+	"github.com/burritocatai/activitycat/internal/github"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, time.March, d, 10, 0, 0, 0, time.UTC) }
+	closed, merged := day(5), day(6)
+	repo := github.Repository{Name: "web", NameWithOwner: "acme/web"}
+	exported := github.ActivityLoadedMsg{
+		PRs: []github.PullRequest{
+			{Number: 1, Title: "Add caching", State: "merged", CreatedAt: day(3), ClosedAt: &merged, MergedAt: &merged, Repository: repo},
+			{Number: 2, Title: "Try a rewrite", State: "closed", CreatedAt: day(4), ClosedAt: &closed, Repository: repo},
+		},
+		Issues:  []github.Issue{{Number: 3, Title: "Slow page", State: "closed", CreatedAt: day(3), ClosedAt: &closed, Repository: repo}},
+		Reviews: []github.Review{{Number: 4, Title: "Fix login", State: "merged", CreatedAt: day(7), Repository: repo}},
+		Commits: []github.Commit{{SHA: "abc123", Commit: github.CommitDetail{Message: "Fix typo", Author: github.CommitAuthor{Date: day(10)}}, Repository: github.CommitRepository{FullName: "acme/web"}}},
+	}
+
+	path := filepath.Join(t.TempDir(), "activity.jsonl")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := github.WriteJSONLines(f, exported.PRs, exported.Issues, exported.Reviews, exported.Commits, exported.CommentedItems, exported.Discussions); err != nil {
+		t.Fatalf("WriteJSONLines: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, span, err := ImportJSONLines(path)
+	if err != nil {
+		t.Fatalf("ImportJSONLines: %v", err)
+	}
+	if !span.Start.Equal(day(3)) || !span.End.Equal(day(10)) {
+		t.Errorf("imported span = %v to %v, want %v to %v", span.Start, span.End, day(3), day(10))
+	}
+
+	compute := func(msg github.ActivityLoadedMsg) string {
+		return analytics.Compute(msg.PRs, msg.Issues, msg.Reviews, msg.Commits, msg.CommentedItems, msg.Discussions, span, analyticsOptions(testConfig())).Format()
+	}
+	if got, want := compute(imported), compute(exported); got != want {
+		t.Errorf("imported analytics differ from the exported ones:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestImportedFetchesCheckAuth(t *testing.T) {
+	m, _ := testModel(t, testConfig())
+	if m.fetchOptions().CheckAuth {
+		t.Error("fetches check auth without imported activity, which was checked at startup")
+	}
+	m.imported = &github.ActivityLoadedMsg{}
+	if !m.fetchOptions().CheckAuth {
+		t.Error("fetches after an import don't check auth")
+	}
+}
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+
+	"github.com/burritocatai/activitycat/internal/analytics"
+	"github.com/burritocatai/activitycat/internal/config"
+	"github.com/burritocatai/activitycat/internal/daterange"
+)
+
+// reportPage is the HTML page a served report is rendered into
+var reportPage = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>activitycat — {{.Range}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.5; color: #1f2328; margin: 0; }
+main { max-width: 860px; margin: 0 auto; padding: 2rem 1rem; }
+pre { background: #f6f8fa; padding: 1rem; overflow-x: auto; border-radius: 6px; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.9em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d1d9e0; padding: 0.3rem 0.7rem; }
+.warning { color: #9a6700; }
+footer { margin-top: 2rem; color: #59636e; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<main>
+{{range .Warnings}}<p class="warning">⚠ {{.}}</p>
+{{end}}{{.Report}}
+<footer>Generated {{.GeneratedAt}} • <a href="?refresh=1">Refresh</a> • <a href="metrics.json">Metrics JSON</a></footer>
+</main>
+</body>
+</html>
+`))
+
+// reportMarkdown converts reports to HTML, with GitHub's tables and task
+// lists. Raw HTML in a report is left out rather than passed through.
+var reportMarkdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Server serves a report as an HTML page at / and its metrics at
+// /metrics.json. The report is generated on the first request, and again for
+// any request with a refresh query parameter, e.g. /?refresh=1. Other
+// requests are served the last report while a refresh runs.
+type Server struct {
+	cfg  config.Config
+	opts Options
+	// rangeSpec is parsed again for each report, so relative ranges like
+	// last-week move forward with the clock. If empty, opts.Range is used.
+	rangeSpec string
+
+	// refreshing is held while generating, so concurrent refreshes run once
+	refreshing sync.Mutex
+
+	// mu guards the last report generated, and generation counts them
+	mu          sync.Mutex
+	generation  int
+	report      string
+	metrics     *analytics.Metrics
+	warnings    []string
+	reportRange daterange.Range
+	generatedAt time.Time
+}
+
+// NewServer creates a Server generating the report for rangeSpec, or
+// opts.Range if it is empty, with opts.Prompt, the way GenerateReport does
+func NewServer(cfg config.Config, opts Options, rangeSpec string) *Server {
+	return &Server{cfg: cfg, opts: opts, rangeSpec: rangeSpec}
+}
+
+// refresh generates the report, unless it was generated by another request
+// since the seen generation while this one waited its turn
+func (s *Server) refresh(seen int) error {
+	s.refreshing.Lock()
+	defer s.refreshing.Unlock()
+
+	s.mu.Lock()
+	done := s.generation != seen
+	s.mu.Unlock()
+	if done {
+		return nil
+	}
+
+	opts := s.opts
+	if s.rangeSpec != "" {
+		r, err := daterange.ParseSpec(s.rangeSpec)
+		if err != nil {
+			return err
+		}
+		opts.Range = &r
+	}
+	generated, metrics, warnings, err := generateReport(s.cfg, opts)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report, s.metrics, s.warnings = generated, metrics, warnings
+	s.reportRange = *opts.Range
+	s.generatedAt = time.Now()
+	s.generation++
+	return nil
+}
+
+// ServeHTTP serves the report page and the metrics. A failed generation is
+// reported with a 502 and tried again on the next request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" && r.URL.Path != "/metrics.json" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	seen := s.generation
+	s.mu.Unlock()
+	if seen == 0 || r.URL.Query().Has("refresh") {
+		if err := s.refresh(seen); err != nil {
+			http.Error(w, "Could not generate the report: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	report, metrics, warnings := s.report, s.metrics, s.warnings
+	reportRange, generatedAt := s.reportRange, s.generatedAt
+	s.mu.Unlock()
+
+	if r.URL.Path == "/metrics.json" {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(metrics)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := reportMarkdown.Convert([]byte(report), &body); err != nil {
+		http.Error(w, "Could not render the report: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	reportPage.Execute(w, struct {
+		Range       string
+		Warnings    []string
+		Report      template.HTML
+		GeneratedAt string
+	}{
+		Range:       reportRange.Format(s.cfg.DateFormat),
+		Warnings:    warnings,
+		Report:      template.HTML(body.String()),
+		GeneratedAt: daterange.FormatDate(generatedAt, s.cfg.DateFormat) + " " + generatedAt.Format("15:04:05"),
+	})
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/analytics"
+	"github.com/burritocatai/activitycat/internal/config"
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/github"
+	"github.com/burritocatai/activitycat/internal/llm"
+)
+
+// testServer returns a server for a little activity, generating with a fake
+// provider
+func testServer(t *testing.T) (*Server, *fakeProvider) {
+	t.Helper()
+	provider := &fakeProvider{report: "# Report\n\nShipped **caching**.\n\n<script>alert(1)</script>"}
+	newProvider = func(config.Config) (llm.Provider, error) { return provider, nil }
+	t.Cleanup(func() { newProvider = llm.NewProvider })
+
+	r := daterange.Range{
+		Start: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC),
+	}
+	activity := github.ActivityLoadedMsg{PRs: []github.PullRequest{{
+		Number:     1,
+		Title:      "Add caching",
+		State:      "open",
+		CreatedAt:  r.Start.AddDate(0, 0, 2),
+		Repository: github.Repository{Name: "web", NameWithOwner: "acme/web"},
+	}}}
+	return NewServer(testConfig(), Options{
+		Range:    &r,
+		Prompt:   &config.Prompt{Name: "weekly", Content: "Summarize my week."},
+		Activity: &activity,
+		Warnings: []string{"Could not fetch discussions"},
+	}, ""), provider
+}
+
+// blockingProvider signals started when asked for a report, and replies with
+// report once release is closed
+type blockingProvider struct {
+	report  string
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingProvider) GenerateReport(ctx context.Context, systemPrompt, userMessage string) (string, llm.Usage, error) {
+	close(p.started)
+	<-p.release
+	return p.report, llm.Usage{}, nil
+}
+
+// get serves a GET request for target
+func get(s *Server, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestServeReport(t *testing.T) {
+	s, provider := testServer(t)
+
+	rec := get(s, "/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET / = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want HTML", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"<h1>Report</h1>", "<strong>caching</strong>", "⚠ Could not fetch discussions", `<a href="metrics.json">`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page is missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "<script>") {
+		t.Errorf("page passes the report's raw HTML through:\n%s", body)
+	}
+
+	// The report is generated once, and again only when refreshed
+	get(s, "/")
+	if len(provider.messages) != 1 {
+		t.Errorf("generated %d times for two requests, want 1", len(provider.messages))
+	}
+	get(s, "/?refresh=1")
+	if len(provider.messages) != 2 {
+		t.Errorf("generated %d times after a refresh, want 2", len(provider.messages))
+	}
+
+	if rec := get(s, "/report.md"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /report.md = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	s, _ := testServer(t)
+
+	rec := get(s, "/metrics.json")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics.json = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var metrics analytics.Metrics
+	if err := json.Unmarshal(rec.Body.Bytes(), &metrics); err != nil {
+		t.Fatalf("metrics are not JSON: %v\n%s", err, rec.Body)
+	}
+	if metrics.PRsOpened != 1 {
+		t.Errorf("PRsOpened = %d, want 1", metrics.PRsOpened)
+	}
+}
+
+func TestServeGenerationFailure(t *testing.T) {
+	s, provider := testServer(t)
+	provider.errs = []error{errors.New("overloaded")}
+
+	rec := get(s, "/")
+	if rec.Code != http.StatusBadGateway || !strings.Contains(rec.Body.String(), "overloaded") {
+		t.Errorf("GET / with a failing provider = %d %q, want %d with the error", rec.Code, rec.Body, http.StatusBadGateway)
+	}
+	// The next request tries again
+	if rec := get(s, "/"); rec.Code != http.StatusOK {
+		t.Errorf("GET / after a failure = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestServeDuringRefresh(t *testing.T) {
+	s, _ := testServer(t)
+	get(s, "/")
+
+	provider := &blockingProvider{report: "# Refreshed", started: make(chan struct{}), release: make(chan struct{})}
+	newProvider = func(config.Config) (llm.Provider, error) { return provider, nil }
+	refreshed := make(chan *httptest.ResponseRecorder)
+	go func() { refreshed <- get(s, "/?refresh=1") }()
+	<-provider.started
+
+	// The last report is served while the refresh is generating
+	served := make(chan *httptest.ResponseRecorder)
+	go func() { served <- get(s, "/") }()
+	select {
+	case rec := <-served:
+		if !strings.Contains(rec.Body.String(), "<h1>Report</h1>") {
+			t.Errorf("GET / during a refresh is missing the last report:\n%s", rec.Body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("GET / waited for the refresh")
+	}
+
+	close(provider.release)
+	if rec := <-refreshed; !strings.Contains(rec.Body.String(), "<h1>Refreshed</h1>") {
+		t.Errorf("GET /?refresh=1 is missing the refreshed report:\n%s", rec.Body)
+	}
+}
+
+func TestServeParsesRangeEachTime(t *testing.T) {
+	s, _ := testServer(t)
+	s.rangeSpec = "2025-02-01..2025-02-28"
+
+	rec := get(s, "/")
+	if want := "2025-02-01 to 2025-02-28"; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("page is missing the range %q parsed from the spec:\n%s", want, rec.Body)
+	}
+
+	s.rangeSpec = "2025-04-01..2025-04-30"
+	rec = get(s, "/?refresh=1")
+	if want := "2025-04-01 to 2025-04-30"; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("refreshed page is missing the range %q parsed again:\n%s", want, rec.Body)
+	}
+}
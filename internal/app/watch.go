@@ -0,0 +1,156 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/burritocatai/activitycat/internal/analytics"
+	"github.com/burritocatai/activitycat/internal/config"
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/github"
+	"github.com/burritocatai/activitycat/internal/keys"
+	"github.com/burritocatai/activitycat/internal/ui/styles"
+)
+
+// MinWatchInterval is the shortest refresh interval allowed, to stay well
+// inside GitHub's search rate limit
+const MinWatchInterval = time.Minute
+
+// watchTickMsg is sent when it is time to refresh
+type watchTickMsg time.Time
+
+// watchFetchedMsg is sent when a refresh has fetched the range's activity
+type watchFetchedMsg struct {
+	Range    daterange.Range
+	Activity github.ActivityLoadedMsg
+}
+
+// WatchModel re-fetches activity on an interval and shows only the
+// analytics, for an unattended dashboard
+type WatchModel struct {
+	cfg       config.Config
+	rangeSpec string
+	interval  time.Duration
+	anonymize bool
+
+	selectedRange daterange.Range
+	metrics       *analytics.Metrics
+	warnings      []string
+	updated       time.Time
+	fetching      bool
+	err           error
+	failedAt      time.Time
+}
+
+// NewWatch creates a WatchModel that fetches rangeSpec every interval. The
+// spec is parsed again on each refresh, so relative ranges like "last-week"
+// move forward with the clock. With anonymize, repository names and logins
+// are replaced by placeholders.
+func NewWatch(cfg config.Config, rangeSpec string, interval time.Duration, anonymize bool) WatchModel {
+	return WatchModel{cfg: cfg, rangeSpec: rangeSpec, interval: interval, anonymize: anonymize, fetching: true}
+}
+
+// Init starts the first fetch
+func (m WatchModel) Init() tea.Cmd {
+	return m.refreshCmd()
+}
+
+// refreshCmd fetches activity for the range as of now
+func (m WatchModel) refreshCmd() tea.Cmd {
+	return func() tea.Msg {
+		r, err := daterange.ParseSpec(m.rangeSpec)
+		if err != nil {
+			return watchFetchedMsg{Activity: github.ActivityLoadedMsg{Error: err}}
+		}
+		return watchFetchedMsg{
+			Range:    r,
+			Activity: github.FetchActivityCmd(r, fetchOptions(m.cfg))().(github.ActivityLoadedMsg),
+		}
+	}
+}
+
+// tickCmd waits for the interval and then asks for a refresh
+func (m WatchModel) tickCmd() tea.Cmd {
+	return tea.Tick(m.interval, func(t time.Time) tea.Msg { return watchTickMsg(t) })
+}
+
+// Update refreshes on each tick and keeps the last good data when a fetch fails
+func (m WatchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" || key.Matches(msg, keys.Map.Quit) {
+			return m, tea.Quit
+		}
+
+	case watchTickMsg:
+		m.fetching = true
+		return m, m.refreshCmd()
+
+	case watchFetchedMsg:
+		m.fetching = false
+		activity := msg.Activity
+		if activity.Error != nil {
+			m.err = activity.Error
+			m.failedAt = time.Now()
+			return m, m.tickCmd()
+		}
+		m.err = nil
+		if m.anonymize {
+			activity.Anonymize(nil)
+		}
+		m.selectedRange = msg.Range
+		m.metrics = analytics.Compute(activity.PRs, activity.Issues, activity.Reviews, activity.Commits, activity.CommentedItems, activity.Discussions, msg.Range, analyticsOptions(m.cfg))
+		m.warnings = activity.Warnings
+		m.updated = time.Now()
+		return m, m.tickCmd()
+	}
+	return m, nil
+}
+
+// View renders the analytics box with when it was last updated
+func (m WatchModel) View() string {
+	var sb strings.Builder
+
+	title := "activitycat — watching"
+	if !m.selectedRange.Start.IsZero() {
+		title += " " + m.selectedRange.Format(m.cfg.DateFormat)
+	}
+	sb.WriteString(styles.TitleStyle.Render(title))
+	sb.WriteString("\n")
+
+	for _, w := range m.warnings {
+		sb.WriteString(styles.WarningStyle.Render("⚠ "+w) + "\n")
+	}
+	if m.err != nil {
+		note := "Refresh failed at " + m.failedAt.Format("15:04:05") + ": " + m.err.Error()
+		if m.metrics != nil {
+			note += " (showing the last good data)"
+		}
+		sb.WriteString(styles.WarningStyle.Render("⚠ "+note) + "\n")
+	}
+	if len(m.warnings) > 0 || m.err != nil {
+		sb.WriteString("\n")
+	}
+
+	if m.metrics != nil {
+		sb.WriteString(styles.MetricsBoxStyle.Render(m.metrics.FormatSummary()))
+		sb.WriteString("\n")
+	} else if m.err == nil {
+		sb.WriteString(styles.SubtleStyle.Render(fetchingMessage) + "\n")
+	}
+
+	status := fmt.Sprintf("Refreshes every %s", m.interval)
+	if !m.updated.IsZero() {
+		status = "Last updated " + m.updated.Format("15:04:05") + " • " + status
+	}
+	if m.fetching {
+		status += " • refreshing..."
+	}
+	sb.WriteString(styles.FooterStyle.Render(status + " • " + keys.Map.Quit.Help().Key + ": quit"))
+
+	return sb.String()
+}
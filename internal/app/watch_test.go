@@ -0,0 +1,89 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/github"
+)
+
+// watchRange is the range watch tests fetch
+var watchRange = daterange.Range{
+	Start: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+	End:   time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC),
+}
+
+// watchActivity returns an open PR in acme/web
+func watchActivity() github.ActivityLoadedMsg {
+	return github.ActivityLoadedMsg{PRs: []github.PullRequest{{
+		Number:     1,
+		State:      "open",
+		CreatedAt:  watchRange.Start.AddDate(0, 0, 2),
+		Repository: github.Repository{Name: "web", NameWithOwner: "acme/web"},
+		Author:     github.Author{Login: "alice"},
+	}}}
+}
+
+func TestWatchAnonymizes(t *testing.T) {
+	m := NewWatch(testConfig(), "last-week", time.Minute, true)
+
+	next, _ := m.Update(watchFetchedMsg{Range: watchRange, Activity: watchActivity()})
+	m = next.(WatchModel)
+
+	if len(m.metrics.RepoStats) != 1 || m.metrics.RepoStats[0].Repo != "repo-1" {
+		t.Errorf("RepoStats = %+v, want acme/web shown as repo-1", m.metrics.RepoStats)
+	}
+}
+
+func TestWatchTickRefreshes(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$*" in
+"search prs "*) echo '[{"number":1,"title":"Add caching","state":"open","repository":{"name":"web","nameWithOwner":"acme/web"}}]' ;;
+*) echo '[]' ;;
+esac`
+	if err := os.WriteFile(filepath.Join(dir, "gh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	m := NewWatch(testConfig(), "last-week", time.Minute, false)
+	m.fetching = false
+
+	next, cmd := m.Update(watchTickMsg(time.Now()))
+	m = next.(WatchModel)
+	if !m.fetching || cmd == nil {
+		t.Fatal("a tick started no refresh")
+	}
+	msg := cmd()
+	fetched, ok := msg.(watchFetchedMsg)
+	if !ok {
+		t.Fatalf("the tick's command returned %T, want watchFetchedMsg", msg)
+	}
+	if fetched.Activity.Error != nil || len(fetched.Activity.PRs) != 1 {
+		t.Fatalf("refresh fetched %d PRs (error %v), want 1", len(fetched.Activity.PRs), fetched.Activity.Error)
+	}
+
+	next, cmd = m.Update(fetched)
+	m = next.(WatchModel)
+	if m.fetching || m.metrics == nil || m.updated.IsZero() {
+		t.Fatal("the refreshed activity wasn't shown")
+	}
+	if cmd == nil {
+		t.Error("no tick was scheduled after the refresh")
+	}
+
+	// A failed refresh keeps the last good data and keeps ticking
+	metrics := m.metrics
+	next, cmd = m.Update(watchFetchedMsg{Activity: github.ActivityLoadedMsg{Error: errors.New("gh command failed: boom")}})
+	m = next.(WatchModel)
+	if m.metrics != metrics || m.err == nil {
+		t.Errorf("metrics = %v, err = %v after a failed refresh, want the last good data and the error", m.metrics, m.err)
+	}
+	if cmd == nil {
+		t.Error("no tick was scheduled after a failed refresh")
+	}
+}
@@ -1,10 +1,19 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/keys"
 )
 
 // Config holds application configuration for LLM provider selection
@@ -14,28 +23,583 @@ type Config struct {
 	OllamaHost   string `toml:"ollama_host"`
 	OpenAIBaseURL string `toml:"openai_base_url"`
 	OpenAIAPIKey  string `toml:"openai_api_key"`
+	// AnthropicBaseURL sends Claude requests through a gateway or proxy
+	// instead of the default endpoint. ANTHROPIC_BASE_URL overrides it.
+	AnthropicBaseURL string `toml:"anthropic_base_url"`
+
+	// Files holding the provider API keys, read when the key is not set in
+	// the environment. The ANTHROPIC_API_KEY_FILE and OPENAI_API_KEY_FILE
+	// environment variables override them.
+	AnthropicAPIKeyFile string `toml:"anthropic_api_key_file"`
+	OpenAIAPIKeyFile    string `toml:"openai_api_key_file"`
+
+	// AllowedModels overrides the built-in list of valid Claude models
+	AllowedModels []string `toml:"allowed_models"`
+	// OllamaAPIKey is sent as a bearer token and OllamaHeaders are added to
+	// every Ollama request, for servers behind an authenticating proxy
+	OllamaAPIKey  string            `toml:"ollama_api_key"`
+	OllamaHeaders map[string]string `toml:"ollama_headers"`
+
+	// Ollama generation settings: how long the model stays loaded (e.g.
+	// "10m"), the context window in tokens, and the sampling temperature.
+	// Unset values use the server's defaults.
+	OllamaKeepAlive   string   `toml:"ollama_keep_alive"`
+	OllamaNumCtx      int      `toml:"ollama_num_ctx"`
+	OllamaTemperature *float64 `toml:"ollama_temperature"`
+	// OllamaAttempts is how many times a report that comes back empty while
+	// the model loads is requested, 3 if zero
+	OllamaAttempts int `toml:"ollama_attempts"`
+
+	// RequestTimeout abandons a single LLM request that takes longer, such
+	// as one stalled on a dead connection, e.g. "5m". DefaultRequestTimeout
+	// if empty, "0" for no timeout.
+	RequestTimeout string `toml:"request_timeout"`
+
+	// VerifyOllamaModel checks at startup that the Ollama model is pulled
+	VerifyOllamaModel bool `toml:"verify_ollama_model"`
+
+	// Loading screen: spinner style is one of dot, line, minidot, jump, points
+	SpinnerStyle    string   `toml:"spinner_style"`
+	SpinnerColor    string   `toml:"spinner_color"`
+	LoadingMessages []string `toml:"loading_messages"`
+
+	// GitHubSearchLimit caps the results fetched per gh search
+	GitHubSearchLimit int `toml:"github_search_limit"`
+	// FetchConcurrency limits how many gh searches run at once
+	FetchConcurrency int `toml:"fetch_concurrency"`
+	// FetchRetries is how many more times a failed gh search is run before
+	// the fetch fails
+	FetchRetries int `toml:"fetch_retries"`
+
+	// EditBeforeGenerate always opens the assembled message for editing
+	EditBeforeGenerate bool `toml:"edit_before_generate"`
+
+	// WorkingDays are the days used for per-workday rates, e.g. ["sun", "mon", "tue", "wed", "thu"]
+	WorkingDays []string `toml:"working_days"`
+	// WorkingHours bound the working day for the off-hours share, like
+	// "09:00-18:00" (the default), on the clock in Timezone
+	WorkingHours string `toml:"working_hours"`
+	// Timezone is an IANA name like "Europe/Berlin", the system's local
+	// timezone if empty
+	Timezone string `toml:"timezone"`
+
+	// ResolveMergeStatus checks each closed PR with gh pr view to tell merged
+	// from closed-without-merge, at the cost of one extra call per PR
+	ResolveMergeStatus bool `toml:"resolve_merge_status"`
+
+	// ResolveLinkedIssues looks up the title of each issue a PR body says it
+	// closes ("Fixes #12") with gh issue view, one call per issue
+	ResolveLinkedIssues bool `toml:"resolve_linked_issues"`
+
+	// ResolveCommitStats looks up the lines each commit added and deleted
+	// with gh api graphql, one call per 50 commits
+	ResolveCommitStats bool `toml:"resolve_commit_stats"`
+
+	// AssignedIssues also counts closed issues assigned to the user, not
+	// just those they opened
+	AssignedIssues bool `toml:"assigned_issues"`
+
+	// FetchDiscussions also fetches the GitHub Discussions the user started,
+	// with gh api graphql
+	FetchDiscussions bool `toml:"fetch_discussions"`
+
+	// FocusRepos are emphasized in the report, e.g. ["acme/web"]
+	FocusRepos []string `toml:"focus_repos"`
+
+	// DefaultRange is the initially selected date range: a preset label like
+	// "Last Month" or a spec like "30d"
+	DefaultRange string `toml:"default_range"`
+
+	// ExcludeForks and ExcludeArchived leave activity in forked or archived
+	// repos out of the activity list and stats
+	ExcludeForks    bool `toml:"exclude_forks"`
+	ExcludeArchived bool `toml:"exclude_archived"`
+
+	// OwnRepos is "include" (the default) to count activity in your own
+	// repos, "exclude" to leave it out, or "only" to count nothing else
+	OwnRepos string `toml:"own_repos"`
+
+	// TeamMembers fetches and merges the activity of each login instead of
+	// your own, with a per-member breakdown. Team, as "org/team-slug", reads
+	// the members of a GitHub team instead when TeamMembers is empty.
+	TeamMembers []string `toml:"team_members"`
+	Team        string   `toml:"team"`
+
+	// CompareYTD fetches year-to-date activity to put the range in context
+	CompareYTD bool `toml:"compare_ytd"`
+
+	// ComparePrevious fetches the previous period of the same length and
+	// compares key metrics against it
+	ComparePrevious bool `toml:"compare_previous"`
+
+	// FullCommitMessages sends whole commit messages to the LLM instead of
+	// only the subject line; the TUI still shows one line per commit
+	FullCommitMessages bool `toml:"full_commit_messages"`
+
+	// MaxItemsPerType caps how many of each type of activity are sent to the
+	// LLM, keeping the most recent; zero sends everything
+	MaxItemsPerType int `toml:"max_items_per_type"`
+
+	// MaxPromptChars caps the message sent to the LLM, prompt included, at
+	// about this many characters by dropping descriptions and then whole
+	// items of activity; zero for no cap
+	MaxPromptChars int `toml:"max_prompt_chars"`
+
+	// CommentExcerpts is how many of your own comments to fetch and send for
+	// each commented PR or issue; zero skips the extra gh api calls
+	CommentExcerpts int `toml:"comment_excerpts"`
+
+	// ReviewExcerpts is how many of your own review bodies and inline review
+	// comments to fetch and send for each reviewed PR; zero skips the extra
+	// gh api calls
+	ReviewExcerpts int `toml:"review_excerpts"`
+
+	// MinRepoActivity hides repos with less total activity from the repo
+	// breakdown; their activity still counts towards totals
+	MinRepoActivity int `toml:"min_repo_activity"`
+	// RepoSort orders the repo breakdown: total, prs, commits, reviews, or issues
+	RepoSort string `toml:"repo_sort"`
+
+	// TopPRs is how many PRs to highlight in the analytics and the report,
+	// zero for none. TopPRsBy ranks them by "comments" or by "size" (lines
+	// changed, looked up with one gh pr view call per PR).
+	TopPRs   int    `toml:"top_prs"`
+	TopPRsBy string `toml:"top_prs_by"`
+
+	// ActivityWeights score each activity type (prs, issues, reviews,
+	// commits) when finding the most productive day. Unset types count 1.
+	ActivityWeights map[string]float64 `toml:"activity_weights"`
+
+	// MergeTimeCap excludes merge times longer than this, e.g. "30d" or
+	// "72h", from merge time stats. With MergeTimeCapMode "clamp" they are
+	// counted at the cap instead.
+	MergeTimeCap     string `toml:"merge_time_cap"`
+	MergeTimeCapMode string `toml:"merge_time_cap_mode"`
+
+	// DateFormat is the Go layout dates are shown in, e.g. "02 Jan 2006"
+	DateFormat string `toml:"date_format"`
+
+	// ActivityOrder lists activity "newest" first (the default) or "oldest"
+	// first, on screen and in the report
+	ActivityOrder string `toml:"activity_order"`
+
+	// BadgeTheme picks the colors of SVG badges: light, dark, or auto to
+	// match the terminal background
+	BadgeTheme string `toml:"badge_theme"`
+
+	// SystemPrompt sends the prompt as the system prompt and only the
+	// activity as the user message, instead of combining them
+	SystemPrompt bool `toml:"system_prompt"`
+
+	// ReportPreamble is an instruction sent before every prompt, for a house
+	// style shared by all reports
+	ReportPreamble string `toml:"report_preamble"`
+
+	// OutputDir is where reports saved with a relative filename go, e.g.
+	// "~/reports". It is created if needed.
+	OutputDir string `toml:"output_dir"`
+
+	// SaveMetrics includes the metrics summary and repo breakdown above the
+	// narrative when saving a report. Tab toggles it in the save prompt.
+	SaveMetrics bool `toml:"save_metrics"`
+
+	// SaveTOC adds a table of contents linking to each section of saved reports
+	SaveTOC bool `toml:"save_toc"`
+
+	// RenderMarkdown styles the report's headings, lists, code, and emphasis
+	// on screen. The key bound to render toggles it; saved reports are
+	// always the raw Markdown.
+	RenderMarkdown bool `toml:"render_markdown"`
+
+	// HeadingLevel shifts the headings of generated reports so the highest
+	// one is at this level, e.g. 2 for ##, keeping their nesting. 0 leaves
+	// them as the model wrote them.
+	HeadingLevel int `toml:"heading_level"`
+
+	// Models maps logical names like "fast" or "deep" to a provider and
+	// model, selected with --model, a prompt's "model" frontmatter, or by
+	// setting model to the name
+	Models map[string]ModelAlias `toml:"models"`
+
+	// ModelPrices overrides the built-in Claude prices used for cost
+	// estimates, keyed by model name
+	ModelPrices map[string]ModelPrice `toml:"model_prices"`
+	// ConfirmCostAbove asks before generating a report whose estimated cost,
+	// in US dollars, is above it; zero never asks
+	ConfirmCostAbove float64 `toml:"confirm_cost_above"`
+
+	// LabelSections maps report headings to the PR labels listed under them
+	// by prompts with "group_by: labels" frontmatter, e.g. "Bug Fixes" =
+	// ["bug", "bugfix"]. LabelSectionHeadings gives them in file order.
+	LabelSections map[string][]string `toml:"label_sections"`
+	// labelSectionOrder is the LabelSections headings in the order the
+	// config file lists them
+	labelSectionOrder []string
+
+	// RepoAliases maps "owner/name" to a friendlier label shown in its place
+	// in the activity list, repo breakdown, and what is sent to the model
+	RepoAliases map[string]string `toml:"repo_aliases"`
+
+	// Redactions maps regular expressions to replacements applied to the
+	// message before it is sent to the provider, e.g. internal hostnames
+	Redactions map[string]string `toml:"redactions"`
+
+	// Keys remaps actions to comma-separated keys, e.g. back = "h,left"
+	Keys map[string]string `toml:"keys"`
+}
+
+// ModelAlias is the provider and model a logical model name stands for. An
+// empty provider keeps the configured one.
+type ModelAlias struct {
+	Provider string `toml:"provider"`
+	Model    string `toml:"model"`
 }
 
-// LoadConfig reads configuration from ~/.config/activitycat/config.toml.
+// ResolveModel returns the config with Provider and Model replaced by the
+// [models] entry Model names, if it names one
+func (c Config) ResolveModel() Config {
+	if alias, ok := c.Models[c.Model]; ok {
+		if alias.Provider != "" {
+			c.Provider = alias.Provider
+		}
+		c.Model = alias.Model
+	}
+	return c
+}
+
+// SelectModel returns the config with the provider and model of the named
+// [models] entry, or an error if there is no such entry
+func (c Config) SelectModel(name string) (Config, error) {
+	if _, ok := c.Models[name]; !ok {
+		names := make([]string, 0, len(c.Models))
+		for n := range c.Models {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return c, fmt.Errorf("unknown model name %q (no [models] are configured)", name)
+		}
+		return c, fmt.Errorf("unknown model name %q (expected one of %s)", name, strings.Join(names, ", "))
+	}
+	c.Model = name
+	return c.ResolveModel(), nil
+}
+
+// ModelPrice is a model's price in US dollars per million tokens
+type ModelPrice struct {
+	Input  float64 `toml:"input"`
+	Output float64 `toml:"output"`
+}
+
+// LoadConfig reads configuration from config.toml in ConfigDir, usually
+// ~/.config/activitycat/config.toml, then applies any ACTIVITYCAT_*
+// environment overrides.
 // Returns sensible defaults if the file is missing or unreadable.
 func LoadConfig() Config {
 	cfg := Config{
 		Provider:   "claude",
 		Model:      "claude-sonnet-4-5-20250929",
 		OllamaHost: "http://localhost:11434",
+
+		SpinnerStyle: "dot",
+		SpinnerColor: "205",
+
+		GitHubSearchLimit: 1000,
+		FetchConcurrency:  6,
+		FetchRetries:      2,
+		MinRepoActivity:   1,
 	}
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return cfg
+	// A missing or invalid file leaves the defaults
+	if configDir, err := ConfigDir(); err == nil {
+		if md, err := toml.DecodeFile(filepath.Join(configDir, "config.toml"), &cfg); err == nil {
+			cfg.labelSectionOrder = tableKeys(md, "label_sections")
+		}
 	}
 
-	configPath := filepath.Join(homeDir, ".config", "activitycat", "config.toml")
-	_, err = toml.DecodeFile(configPath, &cfg)
+	cfg.applyEnv()
+	return cfg
+}
+
+// tableKeys returns the keys of the named table in the order they were decoded
+func tableKeys(md toml.MetaData, table string) []string {
+	var keys []string
+	for _, key := range md.Keys() {
+		if len(key) == 2 && key[0] == table {
+			keys = append(keys, key[1])
+		}
+	}
+	return keys
+}
+
+// LabelSectionHeadings returns the LabelSections headings in the order the
+// config file lists them, followed in name order by any set another way
+func (c Config) LabelSectionHeadings() []string {
+	headings := make([]string, 0, len(c.LabelSections))
+	listed := make(map[string]bool)
+	for _, heading := range c.labelSectionOrder {
+		if _, ok := c.LabelSections[heading]; ok && !listed[heading] {
+			headings = append(headings, heading)
+			listed[heading] = true
+		}
+	}
+	var rest []string
+	for heading := range c.LabelSections {
+		if !listed[heading] {
+			rest = append(rest, heading)
+		}
+	}
+	sort.Strings(rest)
+	return append(headings, rest...)
+}
+
+// envOverrides maps environment variables to the settings they override,
+// for containers and CI where mounting a config file is awkward
+var envOverrides = map[string]func(c *Config) *string{
+	"ACTIVITYCAT_PROVIDER":    func(c *Config) *string { return &c.Provider },
+	"ACTIVITYCAT_MODEL":       func(c *Config) *string { return &c.Model },
+	"ACTIVITYCAT_OLLAMA_HOST": func(c *Config) *string { return &c.OllamaHost },
+}
+
+// applyEnv replaces settings with the environment variables in envOverrides
+// that are set and non-empty
+func (c *Config) applyEnv() {
+	for name, field := range envOverrides {
+		if value := os.Getenv(name); value != "" {
+			*field(c) = value
+		}
+	}
+}
+
+// weekdays maps lowercase day names and abbreviations to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// Validate checks config values that can't be validated by decoding alone
+func (c Config) Validate() error {
+	if c.DefaultRange != "" {
+		if _, err := daterange.ParseSpec(c.DefaultRange); err != nil {
+			return fmt.Errorf("invalid default_range: %w", err)
+		}
+	}
+	for _, name := range c.WorkingDays {
+		if _, ok := weekdays[strings.ToLower(name)]; !ok {
+			return fmt.Errorf("invalid working_days entry %q (use day names like \"mon\" or \"monday\")", name)
+		}
+	}
+	if c.DateFormat != "" {
+		if err := daterange.ValidateDateFormat(c.DateFormat); err != nil {
+			return fmt.Errorf("invalid date_format: %w", err)
+		}
+	}
+	if _, err := c.MergeTimeCapDuration(); err != nil {
+		return err
+	}
+	if _, err := c.RequestTimeoutDuration(); err != nil {
+		return err
+	}
+	if _, _, err := c.WorkingHoursRange(); err != nil {
+		return err
+	}
+	if _, err := c.Location(); err != nil {
+		return err
+	}
+	switch c.MergeTimeCapMode {
+	case "", "exclude", "clamp":
+	default:
+		return fmt.Errorf("invalid merge_time_cap_mode %q (expected \"exclude\" or \"clamp\")", c.MergeTimeCapMode)
+	}
+	switch c.BadgeTheme {
+	case "", "auto", "light", "dark":
+	default:
+		return fmt.Errorf("invalid badge_theme %q (expected \"auto\", \"light\", or \"dark\")", c.BadgeTheme)
+	}
+	switch c.OwnRepos {
+	case "", "include", "exclude", "only":
+	default:
+		return fmt.Errorf("invalid own_repos %q (expected \"include\", \"exclude\", or \"only\")", c.OwnRepos)
+	}
+	switch c.ActivityOrder {
+	case "", "newest", "oldest":
+	default:
+		return fmt.Errorf("invalid activity_order %q (expected \"newest\" or \"oldest\")", c.ActivityOrder)
+	}
+	switch c.RepoSort {
+	case "", "total", "prs", "commits", "reviews", "issues":
+	default:
+		return fmt.Errorf("invalid repo_sort %q (expected one of total, prs, commits, reviews, issues)", c.RepoSort)
+	}
+	if c.Team != "" {
+		if org, slug, ok := strings.Cut(c.Team, "/"); !ok || org == "" || slug == "" {
+			return fmt.Errorf("invalid team %q (expected org/team)", c.Team)
+		}
+	}
+	for heading, labels := range c.LabelSections {
+		if len(labels) == 0 {
+			return fmt.Errorf("invalid label_sections entry %q (no labels given)", heading)
+		}
+	}
+	if c.OllamaAttempts < 0 {
+		return fmt.Errorf("invalid ollama_attempts %d (must not be negative)", c.OllamaAttempts)
+	}
+	if c.MaxItemsPerType < 0 {
+		return fmt.Errorf("invalid max_items_per_type %d (must not be negative)", c.MaxItemsPerType)
+	}
+	if c.MaxPromptChars < 0 {
+		return fmt.Errorf("invalid max_prompt_chars %d (must not be negative)", c.MaxPromptChars)
+	}
+	if c.HeadingLevel < 0 || c.HeadingLevel > 6 {
+		return fmt.Errorf("invalid heading_level %d (must be between 0 and 6)", c.HeadingLevel)
+	}
+	if c.TopPRs < 0 {
+		return fmt.Errorf("invalid top_prs %d (must not be negative)", c.TopPRs)
+	}
+	switch c.TopPRsBy {
+	case "", "comments", "size":
+	default:
+		return fmt.Errorf("invalid top_prs_by %q (expected \"comments\" or \"size\")", c.TopPRsBy)
+	}
+	if c.FetchConcurrency < 1 {
+		return fmt.Errorf("invalid fetch_concurrency %d (must be at least 1)", c.FetchConcurrency)
+	}
+	if c.FetchRetries < 0 {
+		return fmt.Errorf("invalid fetch_retries %d (must not be negative)", c.FetchRetries)
+	}
+	for name, weight := range c.ActivityWeights {
+		if !activityTypes[name] {
+			return fmt.Errorf("invalid activity_weights entry %q (expected prs, issues, reviews, or commits)", name)
+		}
+		if weight < 0 {
+			return fmt.Errorf("invalid activity_weights entry %q: weight must not be negative", name)
+		}
+	}
+	for name, alias := range c.Models {
+		switch alias.Provider {
+		case "", "claude", "ollama", "openai":
+		default:
+			return fmt.Errorf("invalid models entry %q: unknown provider %q", name, alias.Provider)
+		}
+		if alias.Model == "" {
+			return fmt.Errorf("invalid models entry %q: model is required", name)
+		}
+	}
+	if c.ConfirmCostAbove < 0 {
+		return fmt.Errorf("invalid confirm_cost_above %v (must not be negative)", c.ConfirmCostAbove)
+	}
+	for model, price := range c.ModelPrices {
+		if price.Input < 0 || price.Output < 0 {
+			return fmt.Errorf("invalid model_prices entry %q: prices must not be negative", model)
+		}
+	}
+	for repo, label := range c.RepoAliases {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" {
+			return fmt.Errorf("invalid repo_aliases entry %q (expected \"owner/name\")", repo)
+		}
+		if strings.TrimSpace(label) == "" {
+			return fmt.Errorf("invalid repo_aliases entry %q (empty label)", repo)
+		}
+	}
+	for pattern := range c.Redactions {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redactions pattern %q: %w", pattern, err)
+		}
+	}
+	if _, err := keys.New(c.Keys); err != nil {
+		return fmt.Errorf("invalid keys: %w", err)
+	}
+	return nil
+}
+
+// activityTypes are the valid activity_weights keys
+var activityTypes = map[string]bool{"prs": true, "issues": true, "reviews": true, "commits": true}
+
+// ActivityWeight returns the configured weight for an activity type, or 1 if unset
+func (c Config) ActivityWeight(name string) float64 {
+	if w, ok := c.ActivityWeights[name]; ok {
+		return w
+	}
+	return 1
+}
+
+// MergeTimeCapDuration parses MergeTimeCap, which is a number of days like
+// "30d" or a Go duration like "72h". It returns zero if no cap is set.
+func (c Config) MergeTimeCapDuration() (time.Duration, error) {
+	if c.MergeTimeCap == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(c.MergeTimeCap, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(c.MergeTimeCap); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid merge_time_cap %q (use days like \"30d\" or a duration like \"72h\")", c.MergeTimeCap)
+}
+
+// DefaultRequestTimeout leaves room for long reports from slow local models
+const DefaultRequestTimeout = 10 * time.Minute
+
+// RequestTimeoutDuration parses RequestTimeout, returning
+// DefaultRequestTimeout if it is unset and zero for "0"
+func (c Config) RequestTimeoutDuration() (time.Duration, error) {
+	switch c.RequestTimeout {
+	case "":
+		return DefaultRequestTimeout, nil
+	case "0":
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.RequestTimeout)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid request_timeout %q (use a duration like \"5m\", or \"0\" for none)", c.RequestTimeout)
+	}
+	return d, nil
+}
+
+// WorkingHoursRange parses WorkingHours, like "09:00-18:00", into offsets
+// from midnight. Both are zero if it is unset.
+func (c Config) WorkingHoursRange() (time.Duration, time.Duration, error) {
+	if c.WorkingHours == "" {
+		return 0, 0, nil
+	}
+	from, to, ok := strings.Cut(c.WorkingHours, "-")
+	if ok {
+		start, startErr := time.Parse("15:04", strings.TrimSpace(from))
+		end, endErr := time.Parse("15:04", strings.TrimSpace(to))
+		if startErr == nil && endErr == nil && start.Before(end) {
+			midnight := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
+			return start.Sub(midnight), end.Sub(midnight), nil
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid working_hours %q (use 24-hour times like \"09:00-18:00\")", c.WorkingHours)
+}
+
+// Location loads Timezone, or returns the local timezone if it is unset
+func (c Config) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
 	if err != nil {
-		// File missing or invalid — use defaults
-		return cfg
+		return nil, fmt.Errorf("invalid timezone %q (use an IANA name like \"Europe/Berlin\")", c.Timezone)
 	}
+	return loc, nil
+}
 
-	return cfg
+// WorkingWeekdays returns the configured working days, skipping invalid names
+func (c Config) WorkingWeekdays() []time.Weekday {
+	var days []time.Weekday
+	for _, name := range c.WorkingDays {
+		if d, ok := weekdays[strings.ToLower(name)]; ok {
+			days = append(days, d)
+		}
+	}
+	return days
 }
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// defaultConfig returns the config loaded with no config file
+func defaultConfig(t *testing.T) Config {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	return LoadConfig()
+}
+
+func TestValidateDefaultRange(t *testing.T) {
+	cfg := defaultConfig(t)
+	for _, spec := range []string{"", "Last Month", "last-3-months", "30d", "2025-01-01..2025-03-31"} {
+		cfg.DefaultRange = spec
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("default_range %q: %v", spec, err)
+		}
+	}
+	cfg.DefaultRange = "last-decade"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "default_range") {
+		t.Errorf("default_range %q: error = %v, want one naming default_range", "last-decade", err)
+	}
+}
+
+func TestValidateOwnRepos(t *testing.T) {
+	cfg := defaultConfig(t)
+	for _, mode := range []string{"", "include", "exclude", "only"} {
+		cfg.OwnRepos = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("own_repos %q: %v", mode, err)
+		}
+	}
+	cfg.OwnRepos = "mine"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "own_repos") {
+		t.Errorf("own_repos %q: error = %v, want one naming own_repos", "mine", err)
+	}
+}
+
+func TestSelectModel(t *testing.T) {
+	cfg := Config{
+		Provider: "claude",
+		Model:    "claude-sonnet-4-5",
+		Models: map[string]ModelAlias{
+			"fast":  {Model: "claude-haiku-4-5"},
+			"local": {Provider: "ollama", Model: "llama3"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		wantProvider string
+		wantModel    string
+	}{
+		{"fast", "claude", "claude-haiku-4-5"},
+		{"local", "ollama", "llama3"},
+	}
+	for _, tt := range tests {
+		got, err := cfg.SelectModel(tt.name)
+		if err != nil {
+			t.Errorf("SelectModel(%q): %v", tt.name, err)
+			continue
+		}
+		if got.Provider != tt.wantProvider || got.Model != tt.wantModel {
+			t.Errorf("SelectModel(%q) = %s/%s, want %s/%s", tt.name, got.Provider, got.Model, tt.wantProvider, tt.wantModel)
+		}
+	}
+
+	_, err := cfg.SelectModel("deep")
+	if err == nil || !strings.Contains(err.Error(), `unknown model name "deep" (expected one of fast, local)`) {
+		t.Errorf("SelectModel of an unknown name: error = %v, want one listing the configured names", err)
+	}
+}
+
+func TestLabelSectionHeadings(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	configDir, err := ConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "[label_sections]\n\"Features\" = [\"feature\"]\n\"Bug Fixes\" = [\"bug\"]\n\"Documentation\" = [\"docs\"]\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := LoadConfig()
+	want := "Features, Bug Fixes, Documentation"
+	if got := strings.Join(cfg.LabelSectionHeadings(), ", "); got != want {
+		t.Errorf("LabelSectionHeadings = %s, want the file order %s", got, want)
+	}
+
+	// Headings set in code follow in name order
+	cfg.LabelSections["Chores"] = []string{"chore"}
+	cfg.LabelSections["Breaking"] = []string{"breaking"}
+	want += ", Breaking, Chores"
+	if got := strings.Join(cfg.LabelSectionHeadings(), ", "); got != want {
+		t.Errorf("LabelSectionHeadings = %s, want %s", got, want)
+	}
+}
+
+func TestEnvOverrides(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	configDir, err := ConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "provider = \"claude\"\nmodel = \"claude-haiku-4-5\"\nollama_host = \"http://gpu-box:11434\"\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Set variables replace the file's settings, empty ones are ignored
+	t.Setenv("ACTIVITYCAT_PROVIDER", "ollama")
+	t.Setenv("ACTIVITYCAT_MODEL", "llama3")
+	t.Setenv("ACTIVITYCAT_OLLAMA_HOST", "")
+	cfg := LoadConfig()
+	if cfg.Provider != "ollama" || cfg.Model != "llama3" {
+		t.Errorf("provider, model = %q, %q, want the environment's ollama, llama3", cfg.Provider, cfg.Model)
+	}
+	if cfg.OllamaHost != "http://gpu-box:11434" {
+		t.Errorf("OllamaHost = %q, want the file's http://gpu-box:11434", cfg.OllamaHost)
+	}
+}
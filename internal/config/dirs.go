@@ -0,0 +1,20 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ConfigDir returns the directory holding config.toml and prompts:
+// $XDG_CONFIG_HOME/activitycat, or ~/.config/activitycat when it is unset.
+// As the XDG spec requires, a relative XDG_CONFIG_HOME is ignored.
+func ConfigDir() (string, error) {
+	if base := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(base) {
+		return filepath.Join(base, "activitycat"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "activitycat"), nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	xdg := t.TempDir()
+
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{xdg, filepath.Join(xdg, "activitycat")},
+		{"", filepath.Join(home, ".config", "activitycat")},
+		{"relative/config", filepath.Join(home, ".config", "activitycat")},
+	}
+	for _, tt := range tests {
+		t.Setenv("XDG_CONFIG_HOME", tt.env)
+		got, err := ConfigDir()
+		if err != nil {
+			t.Fatalf("ConfigDir with XDG_CONFIG_HOME=%q: %v", tt.env, err)
+		}
+		if got != tt.want {
+			t.Errorf("ConfigDir with XDG_CONFIG_HOME=%q = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	if got, err := PromptsDir(); err != nil || got != filepath.Join(xdg, "activitycat", "prompts") {
+		t.Errorf("PromptsDir() = %q, %v, want it under XDG_CONFIG_HOME", got, err)
+	}
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"embed"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,11 +13,28 @@ import (
 type Prompt struct {
 	Name    string
 	Content string
+	Path    string // file the prompt was loaded from, empty for built-ins
+	// Frontmatter holds "key: value" settings from an optional block
+	// delimited by "---" lines at the top of the file
+	Frontmatter map[string]string
+	// MetricsOnly reports the computed metrics without calling a provider
+	MetricsOnly bool
+	// BuiltIn marks prompts shipped with activitycat rather than user files
+	BuiltIn bool
 }
 
-// defaultPrompt is used when no user prompts are found
+// MetricsOnlyPrompt is the built-in option that skips the LLM entirely
+var MetricsOnlyPrompt = Prompt{
+	Name:        "Metrics Only",
+	MetricsOnly: true,
+	BuiltIn:     true,
+}
+
+// defaultPrompt is the first built-in prompt, listed first when the user has
+// no prompts of their own
 var defaultPrompt = Prompt{
-	Name: "Default Report",
+	Name:    "Default Report",
+	BuiltIn: true,
 	Content: `Please analyze my GitHub PR activity and create a concise monthly report.
 
 Include:
@@ -26,31 +46,60 @@ Include:
 Keep the report professional and highlight the most important work.`,
 }
 
-// LoadPrompts reads all prompt files from $HOME/.config/activitycat/prompts/
-// Returns at least one prompt (the default if no user prompts exist)
-func LoadPrompts() ([]Prompt, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		// Return default prompt if we can't get home dir
-		return []Prompt{defaultPrompt}, nil
+// builtinFS holds the prompts shipped with the binary, one file per prompt
+//
+//go:embed builtin/*.md
+var builtinFS embed.FS
+
+// builtInPrompts returns the default prompt followed by the embedded prompts
+func builtInPrompts() []Prompt {
+	prompts := []Prompt{defaultPrompt}
+	entries, _ := builtinFS.ReadDir("builtin")
+	for _, entry := range entries {
+		content, err := builtinFS.ReadFile("builtin/" + entry.Name())
+		if err != nil {
+			continue
+		}
+		frontmatter, body := parseFrontmatter(string(content))
+		prompts = append(prompts, Prompt{
+			Name:        promptName(entry.Name()),
+			Content:     body,
+			Frontmatter: frontmatter,
+			BuiltIn:     true,
+		})
 	}
+	return prompts
+}
 
-	promptsDir := filepath.Join(homeDir, ".config", "activitycat", "prompts")
+// LoadPrompts reads all prompt files from PromptsDir, followed by the
+// built-in prompts. A user prompt with the same name as a built-in one
+// replaces it, so there is always at least one prompt. Warnings describe
+// prompt files that were skipped because another file has the same name.
+func LoadPrompts() ([]Prompt, []string) {
+	user, warnings := loadUserPrompts()
+	return mergePrompts(user, builtInPrompts()), warnings
+}
 
-	// Check if directory exists
-	if _, err := os.Stat(promptsDir); os.IsNotExist(err) {
-		// Directory doesn't exist, return default
-		return []Prompt{defaultPrompt}, nil
+// loadUserPrompts reads the prompt files in PromptsDir, skipping any that
+// can't be read. It returns nil if the directory is missing or unreadable.
+// Files differing only in extension, like standup.md and standup.txt, would
+// share a name, so only the first in filename order is kept and a warning
+// is returned for each of the others.
+func loadUserPrompts() ([]Prompt, []string) {
+	promptsDir, err := PromptsDir()
+	if err != nil {
+		return nil, nil
 	}
 
-	// Read directory contents
+	// Read directory contents, sorted by filename
 	entries, err := os.ReadDir(promptsDir)
 	if err != nil {
-		// Error reading directory, return default
-		return []Prompt{defaultPrompt}, nil
+		return nil, nil
 	}
 
 	var prompts []Prompt
+	var warnings []string
+	kept := make(map[string]string)
 	for _, entry := range entries {
 		// Skip directories and hidden files
 		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
@@ -65,19 +114,190 @@ func LoadPrompts() ([]Prompt, error) {
 			continue
 		}
 
-		// Use filename without extension as name
-		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		name := promptName(entry.Name())
+		if first, ok := kept[name]; ok {
+			warnings = append(warnings, fmt.Sprintf("Prompt file %s was skipped because %s has the same name %q", entry.Name(), first, name))
+			continue
+		}
+		kept[name] = entry.Name()
 
+		frontmatter, body := parseFrontmatter(string(content))
 		prompts = append(prompts, Prompt{
-			Name:    name,
-			Content: string(content),
+			Name:        name,
+			Content:     body,
+			Path:        filePath,
+			Frontmatter: frontmatter,
 		})
 	}
+	return prompts, warnings
+}
+
+// mergePrompts returns the user prompts followed by the built-in prompts
+// whose names no user prompt has taken
+func mergePrompts(user, builtIn []Prompt) []Prompt {
+	taken := make(map[string]bool, len(user))
+	for _, p := range user {
+		taken[p.Name] = true
+	}
 
-	// If no prompts were loaded, return default
-	if len(prompts) == 0 {
-		return []Prompt{defaultPrompt}, nil
+	prompts := append([]Prompt(nil), user...)
+	for _, p := range builtIn {
+		if !taken[p.Name] {
+			prompts = append(prompts, p)
+			taken[p.Name] = true
+		}
+	}
+	return prompts
+}
+
+// LoadPromptFile reads a single prompt from an arbitrary file path.
+// The prompt name is derived from the file's basename without extension.
+func LoadPromptFile(path string) (Prompt, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Prompt{}, fmt.Errorf("could not read prompt file: %w", err)
+	}
+
+	frontmatter, body := parseFrontmatter(string(content))
+	return Prompt{
+		Name:        promptName(path),
+		Content:     body,
+		Path:        path,
+		Frontmatter: frontmatter,
+	}, nil
+}
+
+// ReadPrompt reads a one-off prompt, with optional frontmatter, from r. Like
+// the built-in prompts, it has no Path.
+func ReadPrompt(r io.Reader, name string) (Prompt, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return Prompt{}, fmt.Errorf("could not read prompt: %w", err)
+	}
+
+	frontmatter, body := parseFrontmatter(string(content))
+	if strings.TrimSpace(body) == "" {
+		return Prompt{}, fmt.Errorf("prompt %q is empty", name)
+	}
+	return Prompt{
+		Name:        name,
+		Content:     body,
+		Frontmatter: frontmatter,
+	}, nil
+}
+
+// parseFrontmatter splits an optional block of "key: value" lines delimited
+// by "---" from the start of a prompt file. Keys are lowercased.
+func parseFrontmatter(content string) (map[string]string, string) {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, content
+	}
+
+	frontmatter := make(map[string]string)
+	for i, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "---" {
+			return frontmatter, strings.Join(lines[i+2:], "\n")
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			frontmatter[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
 	}
 
-	return prompts, nil
+	// No closing delimiter, so this isn't frontmatter
+	return nil, content
+}
+
+// PromptsDir returns the directory user prompts are loaded from
+func PromptsDir() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "prompts"), nil
+}
+
+// DuplicatePrompt writes a copy of p to the prompts directory under the next
+// free "-copy" name and returns the new prompt
+func DuplicatePrompt(p Prompt) (Prompt, error) {
+	if p.MetricsOnly {
+		return Prompt{}, fmt.Errorf("%s is built in and has no prompt to copy", p.Name)
+	}
+
+	promptsDir, err := PromptsDir()
+	if err != nil {
+		return Prompt{}, fmt.Errorf("could not find the prompts directory: %w", err)
+	}
+	if err := os.MkdirAll(promptsDir, 0755); err != nil {
+		return Prompt{}, fmt.Errorf("could not create prompts directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(promptsDir)
+	if err != nil {
+		return Prompt{}, fmt.Errorf("could not read prompts directory: %w", err)
+	}
+	taken := make(map[string]bool)
+	for _, entry := range entries {
+		taken[promptName(entry.Name())] = true
+	}
+
+	ext := filepath.Ext(p.Path)
+	if ext == "" {
+		ext = ".md"
+	}
+
+	// Copy the source file as-is so frontmatter is preserved, reading
+	// built-ins from the embedded file they were loaded from. The default
+	// prompt has no file and no frontmatter.
+	raw := []byte(p.Content)
+	if p.Path != "" {
+		if raw, err = os.ReadFile(p.Path); err != nil {
+			return Prompt{}, fmt.Errorf("could not read prompt file: %w", err)
+		}
+	} else if p.BuiltIn {
+		if embedded, err := builtinFS.ReadFile("builtin/" + p.Name + ".md"); err == nil {
+			raw = embedded
+		}
+	}
+
+	dup := Prompt{
+		Name:        copyName(p.Name, taken),
+		Content:     p.Content,
+		Frontmatter: p.Frontmatter,
+	}
+	dup.Path = filepath.Join(promptsDir, dup.Name+ext)
+	if err := os.WriteFile(dup.Path, raw, 0644); err != nil {
+		return Prompt{}, fmt.Errorf("could not write prompt file: %w", err)
+	}
+
+	return dup, nil
+}
+
+// copyName returns name with a "-copy" suffix, numbered from 2 if the plain
+// suffix is already taken
+func copyName(name string, taken map[string]bool) string {
+	candidate := name + "-copy"
+	for n := 2; taken[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-copy-%d", name, n)
+	}
+	return candidate
+}
+
+// FindPrompt returns the prompt with the given name
+func FindPrompt(prompts []Prompt, name string) (Prompt, error) {
+	names := make([]string, len(prompts))
+	for i, p := range prompts {
+		if p.Name == name {
+			return p, nil
+		}
+		names[i] = p.Name
+	}
+	return Prompt{}, fmt.Errorf("prompt %q not found (available: %s)", name, strings.Join(names, ", "))
+}
+
+// promptName uses the filename without directory or extension as the prompt name
+func promptName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
 }
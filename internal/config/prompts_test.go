@@ -0,0 +1,246 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPromptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weekly.md")
+	content := "---\nmodel: fast\n---\nSummarize my week."
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadPromptFile(path)
+	if err != nil {
+		t.Fatalf("LoadPromptFile: %v", err)
+	}
+	if p.Name != "weekly" {
+		t.Errorf("Name = %q, want %q", p.Name, "weekly")
+	}
+	if p.Content != "Summarize my week." {
+		t.Errorf("Content = %q, want %q", p.Content, "Summarize my week.")
+	}
+	if p.Path != path {
+		t.Errorf("Path = %q, want %q", p.Path, path)
+	}
+	if p.Frontmatter["model"] != "fast" {
+		t.Errorf("Frontmatter[model] = %q, want %q", p.Frontmatter["model"], "fast")
+	}
+}
+
+func TestLoadPromptFileMissing(t *testing.T) {
+	_, err := LoadPromptFile(filepath.Join(t.TempDir(), "missing.md"))
+	if err == nil {
+		t.Fatal("LoadPromptFile of a missing file returned no error")
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("error %v does not wrap a not-exist error", err)
+	}
+}
+
+func TestPromptName(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"weekly.md", "weekly"},
+		{"/home/me/prompts/release-notes.txt", "release-notes"},
+		{"notes", "notes"},
+		{"my.team.md", "my.team"},
+		{"dir.d/standup", "standup"},
+	}
+	for _, tt := range tests {
+		if got := promptName(tt.path); got != tt.want {
+			t.Errorf("promptName(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCopyName(t *testing.T) {
+	tests := []struct {
+		taken []string
+		want  string
+	}{
+		{nil, "standup-copy"},
+		{[]string{"standup-copy"}, "standup-copy-2"},
+		{[]string{"standup-copy", "standup-copy-2", "standup-copy-3"}, "standup-copy-4"},
+	}
+	for _, tt := range tests {
+		taken := make(map[string]bool)
+		for _, name := range tt.taken {
+			taken[name] = true
+		}
+		if got := copyName("standup", taken); got != tt.want {
+			t.Errorf("copyName with %v taken = %q, want %q", tt.taken, got, tt.want)
+		}
+	}
+}
+
+func TestDuplicatePrompt(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	promptsDir, err := PromptsDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(promptsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	raw := "---\ndescription: Daily notes\n---\nWhat did I do yesterday?"
+	source := filepath.Join(promptsDir, "standup.md")
+	if err := os.WriteFile(source, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadPromptFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := DuplicatePrompt(p)
+	if err != nil {
+		t.Fatalf("DuplicatePrompt: %v", err)
+	}
+	if first.Name != "standup-copy" {
+		t.Errorf("first duplicate is named %q, want %q", first.Name, "standup-copy")
+	}
+	content, err := os.ReadFile(first.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != raw {
+		t.Errorf("duplicate content = %q, want the source's %q", content, raw)
+	}
+
+	second, err := DuplicatePrompt(p)
+	if err != nil {
+		t.Fatalf("DuplicatePrompt: %v", err)
+	}
+	if second.Name != "standup-copy-2" {
+		t.Errorf("second duplicate is named %q, want %q", second.Name, "standup-copy-2")
+	}
+}
+
+func TestDuplicateBuiltInPrompt(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	p, err := FindPrompt(builtInPrompts(), "release-notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dup, err := DuplicatePrompt(p)
+	if err != nil {
+		t.Fatalf("DuplicatePrompt: %v", err)
+	}
+	if dup.BuiltIn {
+		t.Error("duplicate of a built-in is still marked built-in")
+	}
+	loaded, err := LoadPromptFile(dup.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Frontmatter["group_by"] != "labels" {
+		t.Errorf("duplicate Frontmatter[group_by] = %q, want %q", loaded.Frontmatter["group_by"], "labels")
+	}
+	if loaded.Content != p.Content {
+		t.Errorf("duplicate content = %q, want the built-in's %q", loaded.Content, p.Content)
+	}
+}
+
+func TestReadPrompt(t *testing.T) {
+	p, err := ReadPrompt(strings.NewReader("---\nmodel: fast\n---\nSummarize my week."), "stdin")
+	if err != nil {
+		t.Fatalf("ReadPrompt: %v", err)
+	}
+	if p.Name != "stdin" || p.Content != "Summarize my week." || p.Path != "" {
+		t.Errorf("ReadPrompt = %+v, want the stdin prompt with no path", p)
+	}
+	if p.Frontmatter["model"] != "fast" {
+		t.Errorf("Frontmatter[model] = %q, want %q", p.Frontmatter["model"], "fast")
+	}
+
+	if _, err := ReadPrompt(strings.NewReader(" \n"), "stdin"); err == nil {
+		t.Error("ReadPrompt of an empty prompt returned no error")
+	}
+}
+
+func TestLoadPromptsBuiltIns(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	prompts, _ := LoadPrompts()
+	var names []string
+	for _, p := range prompts {
+		if !p.BuiltIn || p.Path != "" {
+			t.Errorf("prompt %q is not marked built-in with no path", p.Name)
+		}
+		if strings.TrimSpace(p.Content) == "" {
+			t.Errorf("built-in prompt %q is empty", p.Name)
+		}
+		names = append(names, p.Name)
+	}
+	want := "Default Report, performance-review, release-notes, standup, weekly-summary"
+	if got := strings.Join(names, ", "); got != want {
+		t.Errorf("built-in prompts = %s, want %s", got, want)
+	}
+}
+
+func TestUserPromptShadowsBuiltIn(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	promptsDir, err := PromptsDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(promptsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(promptsDir, "standup.md"), []byte("My own standup."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prompts, _ := LoadPrompts()
+	count := make(map[string]int)
+	for _, p := range prompts {
+		count[p.Name]++
+		if count[p.Name] > 1 {
+			t.Errorf("prompt %q is listed more than once", p.Name)
+		}
+	}
+	if prompts[0].Name != "standup" || prompts[0].BuiltIn || prompts[0].Content != "My own standup." {
+		t.Errorf("first prompt = %+v, want the user's standup", prompts[0])
+	}
+	if count["weekly-summary"] != 1 {
+		t.Error("the other built-in prompts were dropped")
+	}
+}
+
+func TestLoadPromptsSharedName(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	promptsDir, err := PromptsDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(promptsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"standup.md": "Markdown standup.", "standup.txt": "Text standup."} {
+		if err := os.WriteFile(filepath.Join(promptsDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prompts, warnings := LoadPrompts()
+	p, err := FindPrompt(prompts, "standup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Content != "Markdown standup." {
+		t.Errorf("standup prompt = %q, want the first file's", p.Content)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "standup.txt") || !strings.Contains(warnings[0], "standup.md") {
+		t.Errorf("warnings = %q, want one naming standup.txt as skipped for standup.md", warnings)
+	}
+}
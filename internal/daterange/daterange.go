@@ -2,13 +2,15 @@ package daterange
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // Range represents a date range with start and end times
 type Range struct {
-	Start time.Time
-	End   time.Time
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
 }
 
 // LastWeek returns a range covering the last 7 days
@@ -38,6 +40,17 @@ func Last3Months() Range {
 	}
 }
 
+// YearToDate returns a range from January 1st of the range's end year to its
+// end. It returns false if the range starts in an earlier year, as the
+// range wouldn't be contained in the year to date.
+func (r Range) YearToDate() (Range, bool) {
+	ytd := Range{
+		Start: time.Date(r.End.Year(), time.January, 1, 0, 0, 0, 0, r.End.Location()),
+		End:   r.End,
+	}
+	return ytd, !r.Start.Before(ytd.Start)
+}
+
 // Parse parses a date string in YYYY-MM-DD format
 func Parse(s string) (time.Time, error) {
 	return time.Parse("2006-01-02", s)
@@ -59,13 +72,92 @@ func Custom(startStr, endStr string) (Range, error) {
 	return Range{Start: start, End: end}, nil
 }
 
-// GitHubQueryString formats the range for GitHub search queries
-// Returns a string like ">=YYYY-MM-DD" for the start date
-func (r Range) GitHubQueryString() string {
-	return ">=" + r.Start.Format("2006-01-02")
+// ParseSpec parses a range spec: a preset name ("last-week", "last-month",
+// "last-3-months", case and spacing insensitive), a relative number of days
+// like "30d", or two dates as "YYYY-MM-DD..YYYY-MM-DD"
+func ParseSpec(spec string) (Range, error) {
+	normalized := NormalizeSpec(spec)
+	switch normalized {
+	case "last-week":
+		return LastWeek(), nil
+	case "last-month":
+		return LastMonth(), nil
+	case "last-3-months":
+		return Last3Months(), nil
+	}
+
+	if start, end, ok := strings.Cut(spec, ".."); ok {
+		return Custom(strings.TrimSpace(start), strings.TrimSpace(end))
+	}
+
+	if days, ok := strings.CutSuffix(normalized, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			now := time.Now()
+			return Range{Start: now.AddDate(0, 0, -n), End: now}, nil
+		}
+	}
+
+	return Range{}, fmt.Errorf("invalid range %q (use last-week, last-month, last-3-months, a number of days like 30d, or YYYY-MM-DD..YYYY-MM-DD)", spec)
+}
+
+// NormalizeSpec lowercases a range spec or preset label and joins words with
+// hyphens, so "Last Month" and "last-month" compare equal
+func NormalizeSpec(spec string) string {
+	return strings.ToLower(strings.Join(strings.Fields(spec), "-"))
+}
+
+// GitHubQueryString formats the range for GitHub search queries.
+// Returns a string like ">=YYYY-MM-DD" for ranges running to today, or
+// "YYYY-MM-DD..YYYY-MM-DD" for ranges that end earlier.
+func (r Range) GitHubQueryString(today time.Time) string {
+	end := r.End.Format("2006-01-02")
+	if end >= today.Format("2006-01-02") {
+		return ">=" + r.Start.Format("2006-01-02")
+	}
+	return r.Start.Format("2006-01-02") + ".." + end
+}
+
+// Previous returns the range of the same length that ends the day before
+// this one starts, e.g. the previous quarter for a quarter
+func (r Range) Previous() Range {
+	end := r.Start.AddDate(0, 0, -1)
+	return Range{
+		Start: end.Add(-r.End.Sub(r.Start)),
+		End:   end,
+	}
 }
 
 // String returns a human-readable representation of the range
 func (r Range) String() string {
-	return r.Start.Format("2006-01-02") + " to " + r.End.Format("2006-01-02")
+	return r.Format(DefaultDateFormat)
+}
+
+// Format returns the range like String, with its dates in layout
+func (r Range) Format(layout string) string {
+	return FormatDate(r.Start, layout) + " to " + FormatDate(r.End, layout)
+}
+
+// DefaultDateFormat is the layout dates are displayed in unless date_format
+// sets another
+const DefaultDateFormat = "2006-01-02"
+
+// FormatDate formats t for display in layout, or DefaultDateFormat if layout
+// is empty
+func FormatDate(t time.Time, layout string) string {
+	if layout == "" {
+		layout = DefaultDateFormat
+	}
+	return t.Format(layout)
+}
+
+// ValidateDateFormat checks that layout is a Go time layout that shows the
+// full date, by formatting a reference date and parsing it back
+func ValidateDateFormat(layout string) error {
+	ref := time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC)
+	parsed, err := time.Parse(layout, ref.Format(layout))
+	if err != nil || !parsed.Equal(ref) {
+		return fmt.Errorf("%q is not a Go date layout showing year, month, and day (e.g. \"02 Jan 2006\")", layout)
+	}
+	return nil
 }
@@ -0,0 +1,46 @@
+package daterange
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestYearToDate(t *testing.T) {
+	r := Range{Start: date(2025, time.April, 1), End: date(2025, time.June, 30)}
+	ytd, ok := r.YearToDate()
+	if !ok {
+		t.Fatal("YearToDate of a range within one year returned false")
+	}
+	if !ytd.Start.Equal(date(2025, time.January, 1)) || !ytd.End.Equal(r.End) {
+		t.Errorf("YearToDate = %v, want 2025-01-01 to %v", ytd, r.End)
+	}
+}
+
+func TestYearToDateAcrossNewYear(t *testing.T) {
+	r := Range{Start: date(2024, time.December, 15), End: date(2025, time.January, 14)}
+	if _, ok := r.YearToDate(); ok {
+		t.Error("YearToDate of a range crossing January 1st returned true")
+	}
+}
+
+func TestGitHubQueryString(t *testing.T) {
+	today := date(2025, time.June, 30)
+	tests := []struct {
+		name string
+		r    Range
+		want string
+	}{
+		{"runs to today", Range{Start: date(2025, time.June, 1), End: today}, ">=2025-06-01"},
+		{"ends later today", Range{Start: date(2025, time.June, 1), End: today.Add(23 * time.Hour)}, ">=2025-06-01"},
+		{"ends before today", Range{Start: date(2025, time.May, 1), End: date(2025, time.May, 31)}, "2025-05-01..2025-05-31"},
+	}
+	for _, tt := range tests {
+		if got := tt.r.GitHubQueryString(today); got != tt.want {
+			t.Errorf("%s: GitHubQueryString = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
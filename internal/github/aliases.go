@@ -0,0 +1,18 @@
+package github
+
+import "strings"
+
+// RepoLabel returns what to show for the repository named "owner/name": its
+// label in aliases, keyed by "owner/name" in any case, if it has one,
+// otherwise the name itself. Links and repo filters keep using the real name.
+func RepoLabel(aliases map[string]string, name string) string {
+	if label, ok := aliases[name]; ok {
+		return label
+	}
+	for alias, label := range aliases {
+		if strings.EqualFold(alias, name) {
+			return label
+		}
+	}
+	return name
+}
@@ -0,0 +1,40 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepoLabel(t *testing.T) {
+	aliases := map[string]string{"Acme/Web": "Web app"}
+	t.Setenv("GH_HOST", "")
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"acme/web", "Web app"},
+		{"ACME/WEB", "Web app"},
+		{"acme/api", "acme/api"},
+	}
+	for _, tt := range tests {
+		if got := RepoLabel(aliases, tt.name); got != tt.want {
+			t.Errorf("RepoLabel(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Activity shows the label, but links use the real name
+	a := newTestActivity()
+	opts := DefaultFormatOptions().IncludeOnly([]string{"prs", "commits"})
+	opts.RepoAliases = aliases
+	out := a.format(opts)
+	if !strings.Contains(out, "- Repository: Web app\n") || !strings.Contains(out, "(Web app, ") || strings.Contains(out, "acme/web") {
+		t.Errorf("formatted activity should name acme/web by its alias:\n%s", out)
+	}
+	if got, want := a.prs[0].URL(), "https://github.com/acme/web/pull/1"; got != want {
+		t.Errorf("PR URL = %q, want %q with the real name", got, want)
+	}
+	if got := a.commits[0].URL(); !strings.Contains(got, "/acme/web/commit/") {
+		t.Errorf("commit URL = %q, want it to use the real name", got)
+	}
+}
@@ -0,0 +1,118 @@
+package github
+
+import (
+	"fmt"
+	"sort"
+)
+
+// pseudonyms maps names to stable placeholders like "repo-1"
+type pseudonyms map[string]string
+
+// newPseudonyms numbers the distinct non-empty names in sorted order, so the
+// same activity always gets the same placeholders
+func newPseudonyms(prefix string, names []string) pseudonyms {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+
+	p := make(pseudonyms)
+	for _, name := range sorted {
+		if _, ok := p[name]; ok || name == "" {
+			continue
+		}
+		p[name] = fmt.Sprintf("%s-%d", prefix, len(p)+1)
+	}
+	return p
+}
+
+// of returns the placeholder for name, or name itself if it is empty
+func (p pseudonyms) of(name string) string {
+	if pseudonym, ok := p[name]; ok {
+		return pseudonym
+	}
+	return name
+}
+
+// Anonymize replaces every repository name with "repo-N" and every user login
+// with "user-N". Each distinct name gets its own placeholder, used
+// consistently across all activity. Titles, bodies, and comments are left
+// as they are.
+//
+// It returns focusRepos mapped to the same placeholders so they still match.
+// Focus repos without activity are left out, as they have no placeholder and
+// would otherwise be sent as they are.
+func (msg *ActivityLoadedMsg) Anonymize(focusRepos []string) []string {
+	repos := newPseudonyms("repo", msg.repoNames())
+
+	var logins []string
+	for _, pr := range msg.PRs {
+		logins = append(logins, pr.Author.Login, pr.Member)
+		for _, req := range pr.ReviewRequests {
+			logins = append(logins, req.Login)
+		}
+	}
+	for _, issue := range msg.Issues {
+		logins = append(logins, issue.Author.Login, issue.Member)
+	}
+	for _, review := range msg.Reviews {
+		logins = append(logins, review.Author.Login, review.Member)
+	}
+	for _, commit := range msg.Commits {
+		logins = append(logins, commit.Repository.Owner.Login, commit.Member)
+	}
+	for _, item := range msg.CommentedItems {
+		logins = append(logins, item.Author.Login, item.Member)
+	}
+	for _, d := range msg.Discussions {
+		logins = append(logins, d.Author.Login, d.Member)
+	}
+	users := newPseudonyms("user", logins)
+
+	repo := func(r *Repository) {
+		r.NameWithOwner = repos.of(r.NameWithOwner)
+		r.Name = r.NameWithOwner
+	}
+
+	for i := range msg.PRs {
+		pr := &msg.PRs[i]
+		repo(&pr.Repository)
+		pr.Author.Login = users.of(pr.Author.Login)
+		pr.Member = users.of(pr.Member)
+		for j := range pr.ReviewRequests {
+			pr.ReviewRequests[j].Login = users.of(pr.ReviewRequests[j].Login)
+		}
+	}
+	for i := range msg.Issues {
+		repo(&msg.Issues[i].Repository)
+		msg.Issues[i].Author.Login = users.of(msg.Issues[i].Author.Login)
+		msg.Issues[i].Member = users.of(msg.Issues[i].Member)
+	}
+	for i := range msg.Reviews {
+		repo(&msg.Reviews[i].Repository)
+		msg.Reviews[i].Author.Login = users.of(msg.Reviews[i].Author.Login)
+		msg.Reviews[i].Member = users.of(msg.Reviews[i].Member)
+	}
+	for i := range msg.Commits {
+		r := &msg.Commits[i].Repository
+		r.FullName = repos.of(r.FullName)
+		r.Owner.Login = users.of(r.Owner.Login)
+		msg.Commits[i].Member = users.of(msg.Commits[i].Member)
+	}
+	for i := range msg.CommentedItems {
+		repo(&msg.CommentedItems[i].Repository)
+		msg.CommentedItems[i].Author.Login = users.of(msg.CommentedItems[i].Author.Login)
+		msg.CommentedItems[i].Member = users.of(msg.CommentedItems[i].Member)
+	}
+	for i := range msg.Discussions {
+		repo(&msg.Discussions[i].Repository)
+		msg.Discussions[i].Author.Login = users.of(msg.Discussions[i].Author.Login)
+		msg.Discussions[i].Member = users.of(msg.Discussions[i].Member)
+	}
+
+	var focus []string
+	for _, name := range focusRepos {
+		if pseudonym, ok := repos[name]; ok {
+			focus = append(focus, pseudonym)
+		}
+	}
+	return focus
+}
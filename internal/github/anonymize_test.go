@@ -0,0 +1,54 @@
+package github
+
+import (
+	"slices"
+	"testing"
+)
+
+// namedActivity returns PRs and commits across two repos by two authors
+func namedActivity() ActivityLoadedMsg {
+	web := Repository{Name: "web", NameWithOwner: "acme/web"}
+	api := Repository{Name: "api", NameWithOwner: "acme/api"}
+	return ActivityLoadedMsg{
+		PRs: []PullRequest{
+			{Number: 1, Repository: web, Author: Author{Login: "alice"}},
+			{Number: 2, Repository: api, Author: Author{Login: "bob"}},
+			{Number: 3, Repository: web, Author: Author{Login: "alice"}},
+		},
+		Commits: []Commit{{SHA: "abc1234", Repository: CommitRepository{FullName: "acme/api"}}},
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	msg := namedActivity()
+	focus := msg.Anonymize([]string{"acme/web", "acme/gone"})
+
+	web, api := msg.PRs[0].Repository.NameWithOwner, msg.PRs[1].Repository.NameWithOwner
+	if web != msg.PRs[2].Repository.NameWithOwner {
+		t.Errorf("acme/web became both %q and %q", web, msg.PRs[2].Repository.NameWithOwner)
+	}
+	if api != msg.Commits[0].Repository.FullName {
+		t.Errorf("acme/api became %q on a PR and %q on a commit", api, msg.Commits[0].Repository.FullName)
+	}
+	if web == api {
+		t.Errorf("acme/web and acme/api both became %q", web)
+	}
+	// Names are numbered in sorted order
+	if api != "repo-1" || web != "repo-2" {
+		t.Errorf("repos became %q and %q, want repo-1 for acme/api and repo-2 for acme/web", api, web)
+	}
+	if alice, bob := msg.PRs[0].Author.Login, msg.PRs[1].Author.Login; alice != "user-1" || bob != "user-2" || msg.PRs[2].Author.Login != alice {
+		t.Errorf("authors became %q, %q, and %q, want user-1, user-2, and user-1", alice, bob, msg.PRs[2].Author.Login)
+	}
+
+	// acme/gone has no activity, so no placeholder to send instead
+	if !slices.Equal(focus, []string{web}) {
+		t.Errorf("focus repos = %q, want [%q]", focus, web)
+	}
+
+	again := namedActivity()
+	again.Anonymize(nil)
+	if again.PRs[0].Repository.NameWithOwner != web || again.PRs[1].Author.Login != "user-2" {
+		t.Error("the same activity got different placeholders")
+	}
+}
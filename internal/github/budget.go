@@ -0,0 +1,96 @@
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// bodyBudgetPercent is how much of the character budget can be used before
+// descriptions, commit bodies, and comment excerpts are left out
+const bodyBudgetPercent = 80
+
+// truncationNoteReserve is the part of the budget kept for the note saying
+// what was left out
+const truncationNoteReserve = 300
+
+// itemWriter is what an item is rendered to: the formatted activity itself,
+// or a reused buffer it is measured in first when there is a budget
+type itemWriter interface {
+	io.Writer
+	io.StringWriter
+}
+
+// promptBudget writes formatted activity while keeping it within max
+// characters. Past bodyBudgetPercent of the budget items are written
+// without their bodies, and once an item no longer fits, it and every item
+// after it are left out.
+type promptBudget struct {
+	sb   *strings.Builder
+	max  int // zero for no limit
+	used int
+
+	bodiesDropped bool
+	omitted       int
+
+	// scratch holds each item while it is measured, reused across items
+	scratch bytes.Buffer
+}
+
+// write writes s, such as a heading, unless items are already being left out
+func (b *promptBudget) write(s string) {
+	if b.omitted > 0 {
+		return
+	}
+	b.sb.WriteString(s)
+	if b.max > 0 {
+		b.used += utf8.RuneCountInString(s)
+	}
+}
+
+// item writes an item rendered by render, which is given the number of
+// body runes to include, maxBody or zero once bodies are being left out.
+// Without a limit the item is rendered straight into the output.
+func (b *promptBudget) item(maxBody int, render func(w itemWriter, maxBody int)) {
+	if b.max <= 0 {
+		render(b.sb, maxBody)
+		return
+	}
+	if b.omitted > 0 {
+		b.omitted++
+		return
+	}
+	if b.used >= b.max*bodyBudgetPercent/100 {
+		maxBody = 0
+		b.bodiesDropped = true
+	}
+
+	b.scratch.Reset()
+	render(&b.scratch, maxBody)
+	n := utf8.RuneCount(b.scratch.Bytes())
+	if b.used+n > b.max-truncationNoteReserve {
+		b.omitted++
+		return
+	}
+	b.sb.Write(b.scratch.Bytes())
+	b.used += n
+}
+
+// note returns what was left out to stay within the budget, or "" if nothing was
+func (b *promptBudget) note() string {
+	var parts []string
+	if b.bodiesDropped {
+		parts = append(parts, "later items are listed without their descriptions")
+	}
+	if b.omitted > 0 {
+		parts = append(parts, fmt.Sprintf("%d more items were left out", b.omitted))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	// b.max is what the prompt left of max_prompt_chars, so it isn't named
+	return fmt.Sprintf("[Truncated to fit max_prompt_chars: %s. The totals above still count all activity.]\n",
+		strings.Join(parts, ", and "))
+}
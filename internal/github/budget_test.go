@@ -0,0 +1,52 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// manyPRs returns n PRs, each with a long description
+func manyPRs(n int) []PullRequest {
+	prs := make([]PullRequest, n)
+	for i := range prs {
+		prs[i] = PullRequest{
+			Number:     i + 1,
+			Title:      fmt.Sprintf("Change %d", i+1),
+			State:      "merged",
+			Body:       strings.Repeat("Lots of detail. ", 25),
+			CreatedAt:  time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Minute),
+			Repository: Repository{Name: "web", NameWithOwner: "acme/web"},
+		}
+	}
+	return prs
+}
+
+func TestFormatMaxPromptChars(t *testing.T) {
+	prs := manyPRs(5000)
+	opts := DefaultFormatOptions()
+	opts.MaxPromptChars = 20000
+
+	out := FormatActivityForClaude(prs, nil, nil, nil, nil, nil, "", opts)
+	if n := utf8.RuneCountInString(out); n > opts.MaxPromptChars {
+		t.Errorf("formatted %d characters, want at most %d", n, opts.MaxPromptChars)
+	}
+	for _, want := range []string{
+		"Total PRs: 5000",
+		"- Description: Lots of detail.",
+		"[Truncated to fit max_prompt_chars: later items are listed without their descriptions, and ",
+		"more items were left out.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("formatted activity is missing %q", want)
+		}
+	}
+
+	// Without a budget everything is sent
+	opts.MaxPromptChars = 0
+	if out := FormatActivityForClaude(prs, nil, nil, nil, nil, nil, "", opts); strings.Contains(out, "[Truncated") || !strings.Contains(out, "Change 1\n") {
+		t.Error("activity was truncated without a budget")
+	}
+}
@@ -2,162 +2,350 @@ package github
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/burritocatai/activitycat/internal/daterange"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// DefaultSearchLimit is the maximum number of results fetched per search
+const DefaultSearchLimit = 1000
+
+// FetchOptions controls how activity is fetched
+type FetchOptions struct {
+	// Limit caps the number of results per gh search, DefaultSearchLimit if zero
+	Limit int
+	// ResolveMergeStatus looks up the real merge time of closed PRs with gh pr view
+	ResolveMergeStatus bool
+	// CommentExcerpts is how many of the user's comments to fetch for each
+	// commented item with gh api, zero to skip
+	CommentExcerpts int
+	// ReviewExcerpts is how many of the reviewer's own review bodies and
+	// inline comments to fetch for each reviewed PR with gh api, zero to skip
+	ReviewExcerpts int
+	// ResolveLinkedIssues looks up the titles of issues PR bodies say they
+	// close with gh issue view
+	ResolveLinkedIssues bool
+	// ResolvePRSizes looks up the lines added and deleted by each PR with gh pr view
+	ResolvePRSizes bool
+	// ResolveCommitStats looks up the lines added and deleted by each commit
+	// with gh api graphql
+	ResolveCommitStats bool
+	// AssignedIssues also fetches closed issues assigned to the user, merged
+	// into the authored ones
+	AssignedIssues bool
+	// ExcludeForks and ExcludeArchived drop activity in forked or archived
+	// repositories, looked up with gh api graphql
+	ExcludeForks    bool
+	ExcludeArchived bool
+	// CheckAuth checks that gh is installed and authenticated before
+	// fetching, for sessions that skipped the check at startup
+	CheckAuth bool
+	// OwnRepos is OwnReposExclude to drop activity in repositories owned by
+	// the user whose activity it is, or OwnReposOnly to keep only that.
+	// Anything else keeps all activity.
+	OwnRepos string
+	// Concurrency limits how many gh searches run at once,
+	// DefaultFetchConcurrency if zero
+	Concurrency int
+	// Retries is how many more times a failed search is run, with a growing
+	// pause before each, before the fetch fails
+	Retries int
+	// Author is the login whose activity is fetched, the authenticated user
+	// if empty
+	Author string
+	// Members, if set, fetches each login's activity in turn instead of
+	// Author's and merges it, tagging every item with its Member
+	Members []string
+	// OldestFirst sorts the fetched activity oldest first instead of
+	// newest first
+	OldestFirst bool
+	// Discussions also fetches the discussions the user started, with
+	// gh api graphql
+	Discussions bool
+}
+
+func (o FetchOptions) author() string {
+	if o.Author == "" {
+		return "@me"
+	}
+	return o.Author
+}
+
+// DefaultFetchConcurrency runs every activity search at once
+const DefaultFetchConcurrency = 6
+
+func (o FetchOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return DefaultFetchConcurrency
+	}
+	return o.Concurrency
+}
+
+func (o FetchOptions) limit() int {
+	if o.Limit <= 0 {
+		return DefaultSearchLimit
+	}
+	return o.Limit
+}
+
+// truncationWarning returns a warning if a result set hit the search limit
+func (o FetchOptions) truncationWarning(kind string, count int) string {
+	if count < o.limit() {
+		return ""
+	}
+	return fmt.Sprintf("%s results may be truncated at %d; narrow your range or raise github_search_limit.", kind, o.limit())
+}
+
 // CheckAuth verifies that gh CLI is installed and authenticated
 func CheckAuth() error {
 	// Check if gh is installed
 	if _, err := exec.LookPath("gh"); err != nil {
-		return fmt.Errorf("gh CLI not found. Please install it from https://cli.github.com/")
+		return &ErrAuthFailed{NotInstalled: true}
 	}
 
 	// Check if authenticated
 	cmd := exec.Command("gh", "auth", "status")
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("gh CLI not authenticated. Run 'gh auth login' first")
+		return &ErrAuthFailed{}
 	}
 
 	return nil
 }
 
+// minGHVersion is the oldest gh release known to support every search and
+// JSON field activitycat uses
+var minGHVersion = [3]int{2, 23, 0}
+
+// CheckVersion returns a warning if the installed gh is older than
+// minGHVersion, or "" if it is new enough or its version can't be determined
+func CheckVersion() string {
+	output, err := exec.Command("gh", "--version").Output()
+	if err != nil {
+		return ""
+	}
+	version, ok := parseGHVersion(string(output))
+	if !ok {
+		return ""
+	}
+	for i := range version {
+		if version[i] != minGHVersion[i] {
+			if version[i] > minGHVersion[i] {
+				return ""
+			}
+			return fmt.Sprintf("gh %d.%d.%d is older than the minimum supported %d.%d.%d; some searches may fail. Upgrade from https://cli.github.com/",
+				version[0], version[1], version[2], minGHVersion[0], minGHVersion[1], minGHVersion[2])
+		}
+	}
+	return ""
+}
+
+// parseGHVersion extracts the version from "gh version 2.40.1 (2023-12-13)"
+func parseGHVersion(output string) ([3]int, bool) {
+	var version [3]int
+	fields := strings.Fields(output)
+	if len(fields) < 3 || fields[0] != "gh" || fields[1] != "version" {
+		return version, false
+	}
+	parts := strings.SplitN(fields[2], ".", 3)
+	if len(parts) != 3 {
+		return version, false
+	}
+	for i, p := range parts {
+		// Drop pre-release suffixes like "0-rc1"
+		p, _, _ = strings.Cut(p, "-")
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return version, false
+		}
+		version[i] = n
+	}
+	return version, true
+}
+
 // FetchPRs executes gh search prs to fetch PRs for the authenticated user
-func FetchPRs(ctx context.Context, dateRange daterange.Range) ([]PullRequest, error) {
+func FetchPRs(ctx context.Context, dateRange daterange.Range, opts FetchOptions) ([]PullRequest, error) {
 	args := []string{
 		"search", "prs",
-		"--author", "@me",
-		"--created", dateRange.GitHubQueryString(),
-		"--json", "number,title,state,body,createdAt,closedAt,author,repository",
-		"--limit", "1000",
+		"--author", opts.author(),
+		"--created", dateRange.GitHubQueryString(time.Now()),
+		"--json", "number,title,state,body,createdAt,closedAt,author,repository,commentsCount,labels",
+		"--limit", strconv.Itoa(opts.limit()),
 	}
 
 	cmd := exec.CommandContext(ctx, "gh", args...)
 	output, err := cmd.Output()
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("gh command failed: %s", string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("gh command failed: %w", err)
+		return nil, ghError(err)
 	}
 
 	var prs []PullRequest
-	if err := json.Unmarshal(output, &prs); err != nil {
-		return nil, fmt.Errorf("failed to parse PR data: %w", err)
+	if err := parseJSON("PR data", output, &prs); err != nil {
+		return nil, err
 	}
 
 	return prs, nil
 }
 
+// ResolveMergeStatus replaces the state heuristics for closed and merged PRs
+// with the authoritative mergedAt from gh pr view, running at most
+// concurrency lookups at a time. PRs are updated in place; the first lookup
+// error is returned after all lookups finish.
+func ResolveMergeStatus(ctx context.Context, prs []PullRequest, concurrency int) error {
+	return lookupAll(prs, concurrency, func(pr *PullRequest) error {
+		if pr.IsOpen() || pr.MergedAt != nil {
+			return nil
+		}
+		return resolvePRMergeStatus(ctx, pr)
+	})
+}
+
+// resolvePRMergeStatus fetches mergedAt for a single PR and updates its state
+func resolvePRMergeStatus(ctx context.Context, pr *PullRequest) error {
+	cmd := exec.CommandContext(ctx, "gh", "pr", "view", strconv.Itoa(pr.Number),
+		"--repo", pr.Repository.NameWithOwner,
+		"--json", "mergedAt",
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		return ghError(err)
+	}
+
+	var view struct {
+		MergedAt *time.Time `json:"mergedAt"`
+	}
+	if err := parseJSON(fmt.Sprintf("merge status for %s#%d", pr.Repository.NameWithOwner, pr.Number), output, &view); err != nil {
+		return err
+	}
+
+	applyMergeStatus(pr, view.MergedAt)
+	return nil
+}
+
+// applyMergeStatus records an authoritative merge time, or marks the PR as
+// closed without merge when there is none
+func applyMergeStatus(pr *PullRequest, mergedAt *time.Time) {
+	pr.MergedAt = mergedAt
+	if mergedAt != nil {
+		pr.State = "merged"
+	} else {
+		pr.State = "closed"
+	}
+}
+
 // FetchIssues executes gh search issues to fetch closed issues for the authenticated user
-func FetchIssues(ctx context.Context, dateRange daterange.Range) ([]Issue, error) {
+func FetchIssues(ctx context.Context, dateRange daterange.Range, opts FetchOptions) ([]Issue, error) {
+	return searchClosedIssues(ctx, dateRange, opts, "--author")
+}
+
+// FetchAssignedIssues fetches issues closed in the range that were assigned
+// to the user, marking each as Assigned
+func FetchAssignedIssues(ctx context.Context, dateRange daterange.Range, opts FetchOptions) ([]Issue, error) {
+	issues, err := searchClosedIssues(ctx, dateRange, opts, "--assignee")
+	for i := range issues {
+		issues[i].Assigned = true
+	}
+	return issues, err
+}
+
+// searchClosedIssues searches for closed issues with the user as the given
+// role, --author or --assignee
+func searchClosedIssues(ctx context.Context, dateRange daterange.Range, opts FetchOptions, role string) ([]Issue, error) {
 	args := []string{
 		"search", "issues",
-		"--author", "@me",
-		"--closed", dateRange.GitHubQueryString(),
+		role, opts.author(),
+		"--closed", dateRange.GitHubQueryString(time.Now()),
 		"--json", "number,title,state,body,createdAt,closedAt,author,repository",
-		"--limit", "1000",
+		"--limit", strconv.Itoa(opts.limit()),
 	}
 
 	cmd := exec.CommandContext(ctx, "gh", args...)
 	output, err := cmd.Output()
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("gh command failed: %s", string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("gh command failed: %w", err)
+		return nil, ghError(err)
 	}
 
 	var issues []Issue
-	if err := json.Unmarshal(output, &issues); err != nil {
-		return nil, fmt.Errorf("failed to parse issue data: %w", err)
+	if err := parseJSON("issue data", output, &issues); err != nil {
+		return nil, err
 	}
 
 	return issues, nil
 }
 
 // FetchReviews fetches PRs the user reviewed
-func FetchReviews(ctx context.Context, dateRange daterange.Range) ([]Review, error) {
+func FetchReviews(ctx context.Context, dateRange daterange.Range, opts FetchOptions) ([]Review, error) {
 	args := []string{
 		"search", "prs",
-		"--reviewed-by", "@me",
-		"--created", dateRange.GitHubQueryString(),
+		"--reviewed-by", opts.author(),
+		"--created", dateRange.GitHubQueryString(time.Now()),
 		"--json", "number,title,state,author,repository,createdAt,closedAt",
-		"--limit", "1000",
+		"--limit", strconv.Itoa(opts.limit()),
 	}
 
 	cmd := exec.CommandContext(ctx, "gh", args...)
 	output, err := cmd.Output()
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("gh command failed: %s", string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("gh command failed: %w", err)
+		return nil, ghError(err)
 	}
 
 	var reviews []Review
-	if err := json.Unmarshal(output, &reviews); err != nil {
-		return nil, fmt.Errorf("failed to parse review data: %w", err)
+	if err := parseJSON("review data", output, &reviews); err != nil {
+		return nil, err
 	}
 
 	return reviews, nil
 }
 
 // FetchCommits fetches commits authored by the user
-func FetchCommits(ctx context.Context, dateRange daterange.Range) ([]Commit, error) {
+func FetchCommits(ctx context.Context, dateRange daterange.Range, opts FetchOptions) ([]Commit, error) {
 	args := []string{
 		"search", "commits",
-		"--author", "@me",
-		"--author-date", dateRange.GitHubQueryString(),
+		"--author", opts.author(),
+		"--author-date", dateRange.GitHubQueryString(time.Now()),
 		"--json", "sha,commit,repository",
-		"--limit", "1000",
+		"--limit", strconv.Itoa(opts.limit()),
 	}
 
 	cmd := exec.CommandContext(ctx, "gh", args...)
 	output, err := cmd.Output()
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("gh command failed: %s", string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("gh command failed: %w", err)
+		return nil, ghError(err)
 	}
 
 	var commits []Commit
-	if err := json.Unmarshal(output, &commits); err != nil {
-		return nil, fmt.Errorf("failed to parse commit data: %w", err)
+	if err := parseJSON("commit data", output, &commits); err != nil {
+		return nil, err
 	}
 
 	return commits, nil
 }
 
 // FetchCommentedPRs fetches PRs the user commented on
-func FetchCommentedPRs(ctx context.Context, dateRange daterange.Range) ([]CommentedItem, error) {
+func FetchCommentedPRs(ctx context.Context, dateRange daterange.Range, opts FetchOptions) ([]CommentedItem, error) {
 	args := []string{
 		"search", "prs",
-		"--commenter", "@me",
-		"--created", dateRange.GitHubQueryString(),
-		"--json", "number,title,state,author,repository,commentsCount",
-		"--limit", "1000",
+		"--commenter", opts.author(),
+		"--created", dateRange.GitHubQueryString(time.Now()),
+		"--json", "number,title,state,author,repository,commentsCount,updatedAt",
+		"--limit", strconv.Itoa(opts.limit()),
 	}
 
 	cmd := exec.CommandContext(ctx, "gh", args...)
 	output, err := cmd.Output()
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("gh command failed: %s", string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("gh command failed: %w", err)
+		return nil, ghError(err)
 	}
 
 	var items []CommentedItem
-	if err := json.Unmarshal(output, &items); err != nil {
-		return nil, fmt.Errorf("failed to parse commented PR data: %w", err)
+	if err := parseJSON("commented PR data", output, &items); err != nil {
+		return nil, err
 	}
 
 	for i := range items {
@@ -168,27 +356,24 @@ func FetchCommentedPRs(ctx context.Context, dateRange daterange.Range) ([]Commen
 }
 
 // FetchCommentedIssues fetches issues the user commented on
-func FetchCommentedIssues(ctx context.Context, dateRange daterange.Range) ([]CommentedItem, error) {
+func FetchCommentedIssues(ctx context.Context, dateRange daterange.Range, opts FetchOptions) ([]CommentedItem, error) {
 	args := []string{
 		"search", "issues",
-		"--commenter", "@me",
-		"--created", dateRange.GitHubQueryString(),
-		"--json", "number,title,state,author,repository,commentsCount",
-		"--limit", "1000",
+		"--commenter", opts.author(),
+		"--created", dateRange.GitHubQueryString(time.Now()),
+		"--json", "number,title,state,author,repository,commentsCount,updatedAt",
+		"--limit", strconv.Itoa(opts.limit()),
 	}
 
 	cmd := exec.CommandContext(ctx, "gh", args...)
 	output, err := cmd.Output()
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("gh command failed: %s", string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("gh command failed: %w", err)
+		return nil, ghError(err)
 	}
 
 	var items []CommentedItem
-	if err := json.Unmarshal(output, &items); err != nil {
-		return nil, fmt.Errorf("failed to parse commented issue data: %w", err)
+	if err := parseJSON("commented issue data", output, &items); err != nil {
+		return nil, err
 	}
 
 	for i := range items {
@@ -198,212 +383,486 @@ func FetchCommentedIssues(ctx context.Context, dateRange daterange.Range) ([]Com
 	return items, nil
 }
 
-// ActivityLoadedMsg is sent when all activity data is loaded
-type ActivityLoadedMsg struct {
-	PRs            []PullRequest
-	Issues         []Issue
-	Reviews        []Review
-	Commits        []Commit
-	CommentedItems []CommentedItem
-	Error          error
-}
-
-// FetchActivityCmd runs all fetch functions concurrently and returns an ActivityLoadedMsg
-func FetchActivityCmd(dateRange daterange.Range) tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-
-		var (
-			prs            []PullRequest
-			issues         []Issue
-			reviews        []Review
-			commits        []Commit
-			commentedPRs   []CommentedItem
-			commentedIssue []CommentedItem
-			prErr, issueErr, reviewErr, commitErr, commentPRErr, commentIssueErr error
-			wg sync.WaitGroup
-		)
+// commentExcerptLength is the number of runes kept from each comment
+const commentExcerptLength = 300
 
-		wg.Add(6)
-
-		go func() {
-			defer wg.Done()
-			prs, prErr = FetchPRs(ctx, dateRange)
-		}()
-
-		go func() {
-			defer wg.Done()
-			issues, issueErr = FetchIssues(ctx, dateRange)
-		}()
+// FetchCommentExcerpts fills in CommentExcerpts on each item with up to
+// perItem of the most recent comments by login, or by the authenticated user
+// if login is "@me", running at most concurrency lookups at a time. Items
+// are updated in place; the first lookup error is returned after all lookups
+// finish.
+func FetchCommentExcerpts(ctx context.Context, items []CommentedItem, login string, perItem, concurrency int) error {
+	login, err := resolveLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+	return lookupAll(items, concurrency, func(item *CommentedItem) error {
+		return fetchItemComments(ctx, item, login, perItem)
+	})
+}
 
-		go func() {
-			defer wg.Done()
-			reviews, reviewErr = FetchReviews(ctx, dateRange)
-		}()
+// issueComment is a comment from the GitHub REST API
+type issueComment struct {
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Body string `json:"body"`
+}
 
-		go func() {
-			defer wg.Done()
-			commits, commitErr = FetchCommits(ctx, dateRange)
-		}()
+// resolveLogin returns login, or the authenticated user's login for "@me"
+func resolveLogin(ctx context.Context, login string) (string, error) {
+	if login != "@me" {
+		return login, nil
+	}
+	output, err := exec.CommandContext(ctx, "gh", "api", "user", "--jq", ".login").Output()
+	if err != nil {
+		return "", ghError(err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-		go func() {
-			defer wg.Done()
-			commentedPRs, commentPRErr = FetchCommentedPRs(ctx, dateRange)
-		}()
+// fetchItemComments fetches every comment on a single item and keeps the
+// most recent ones written by login
+func fetchItemComments(ctx context.Context, item *CommentedItem, login string, perItem int) error {
+	endpoint := fmt.Sprintf("repos/%s/issues/%d/comments?per_page=100", item.Repository.NameWithOwner, item.Number)
+	comments, err := fetchAllPages[issueComment](ctx, "comment data", endpoint)
+	if err != nil {
+		return err
+	}
 
-		go func() {
-			defer wg.Done()
-			commentedIssue, commentIssueErr = FetchCommentedIssues(ctx, dateRange)
-		}()
+	item.CommentExcerpts = commentExcerpts(comments, login, perItem)
+	return nil
+}
 
-		wg.Wait()
+// fetchAllPages fetches every page of a REST endpoint returning an array
+// with gh api, decoding the elements of all of them for source. Comments
+// come oldest first, so the most recent ones are on the last page.
+func fetchAllPages[T any](ctx context.Context, source, endpoint string) ([]T, error) {
+	output, err := exec.CommandContext(ctx, "gh", "api", "--paginate", endpoint, "--jq", ".[]").Output()
+	if err != nil {
+		return nil, ghError(err)
+	}
+	return parseJSONValues[T](source, output)
+}
 
-		// Return the first error encountered
-		for _, err := range []error{prErr, issueErr, reviewErr, commitErr, commentPRErr, commentIssueErr} {
-			if err != nil {
-				return ActivityLoadedMsg{Error: err}
-			}
+// commentExcerpts returns the last perItem comments written by login, each
+// collapsed to a single line and truncated
+func commentExcerpts(comments []issueComment, login string, perItem int) []string {
+	var excerpts []string
+	for _, c := range comments {
+		if c.User.Login != login {
+			continue
 		}
+		excerpts = append(excerpts, truncateRunes(strings.Join(strings.Fields(c.Body), " "), commentExcerptLength))
+	}
+	if len(excerpts) > perItem {
+		excerpts = excerpts[len(excerpts)-perItem:]
+	}
+	return excerpts
+}
 
-		// Merge commented PRs and issues
-		commented := append(commentedPRs, commentedIssue...)
+// itemKey identifies a PR or issue across search results
+type itemKey struct {
+	repo   string
+	number int
+}
 
-		return ActivityLoadedMsg{
-			PRs:            prs,
-			Issues:         issues,
-			Reviews:        reviews,
-			Commits:        commits,
-			CommentedItems: commented,
+// dedupe returns items without repeats of the same key, keeping the first
+// occurrence of each in order
+func dedupe[T any](items []T, key func(T) itemKey) []T {
+	seen := make(map[itemKey]bool, len(items))
+	var unique []T
+	for _, item := range items {
+		k := key(item)
+		if seen[k] {
+			continue
 		}
+		seen[k] = true
+		unique = append(unique, item)
 	}
+	return unique
 }
 
-// FormatActivityForClaude converts all activity data to a text format suitable for Claude API
-func FormatActivityForClaude(prs []PullRequest, issues []Issue, reviews []Review, commits []Commit, commentedItems []CommentedItem, metricsText string) string {
-	var sb strings.Builder
+// dedupePRs drops repeated PRs, keyed on repository and number
+func dedupePRs(prs []PullRequest) []PullRequest {
+	return dedupe(prs, func(pr PullRequest) itemKey { return itemKey{pr.Repository.NameWithOwner, pr.Number} })
+}
 
-	sb.WriteString("# GitHub Activity Report\n\n")
+// dedupeIssues drops repeated issues, keyed on repository and number
+func dedupeIssues(issues []Issue) []Issue {
+	return dedupe(issues, func(i Issue) itemKey { return itemKey{i.Repository.NameWithOwner, i.Number} })
+}
 
-	// Metrics summary at top
-	if metricsText != "" {
-		sb.WriteString("## Metrics Summary\n\n")
-		sb.WriteString(metricsText)
-		sb.WriteString("\n\n")
-	}
+// dedupeReviews drops repeated reviewed PRs, keyed on repository and number
+func dedupeReviews(reviews []Review) []Review {
+	return dedupe(reviews, func(r Review) itemKey { return itemKey{r.Repository.NameWithOwner, r.Number} })
+}
 
-	sb.WriteString(fmt.Sprintf("Total PRs: %d\n", len(prs)))
-	sb.WriteString(fmt.Sprintf("Total Closed Issues: %d\n", len(issues)))
-	sb.WriteString(fmt.Sprintf("Total Reviews Given: %d\n", len(reviews)))
-	sb.WriteString(fmt.Sprintf("Total Commits: %d\n", len(commits)))
-	sb.WriteString(fmt.Sprintf("Total Items Commented On: %d\n\n", len(commentedItems)))
+// dedupeCommented drops repeated commented items, keyed on repository and number
+func dedupeCommented(items []CommentedItem) []CommentedItem {
+	return dedupe(items, func(ci CommentedItem) itemKey { return itemKey{ci.Repository.NameWithOwner, ci.Number} })
+}
 
-	// Format PRs
-	if len(prs) > 0 {
-		sb.WriteString("## Pull Requests\n\n")
-		for i, pr := range prs {
-			sb.WriteString(fmt.Sprintf("### PR #%d: %s\n", i+1, pr.Title))
-			sb.WriteString(fmt.Sprintf("- Repository: %s\n", pr.Repository.NameWithOwner))
-			sb.WriteString(fmt.Sprintf("- Author: @%s\n", pr.Author.Login))
-			sb.WriteString(fmt.Sprintf("- State: %s\n", pr.State))
-			sb.WriteString(fmt.Sprintf("- Created: %s\n", pr.CreatedAt.Format("2006-01-02")))
+// fetchGroup runs fetches concurrently, at most limit at a time
+type fetchGroup struct {
+	wg  sync.WaitGroup
+	sem chan struct{}
+}
 
-			if mt := pr.MergeTime(); mt != nil {
-				sb.WriteString(fmt.Sprintf("- Merged: %s\n", mt.Format("2006-01-02")))
-			} else if pr.ClosedAt != nil {
-				sb.WriteString(fmt.Sprintf("- Closed: %s\n", pr.ClosedAt.Format("2006-01-02")))
-			}
+func newFetchGroup(limit int) *fetchGroup {
+	return &fetchGroup{sem: make(chan struct{}, limit)}
+}
 
-			reviewers := pr.Reviewers()
-			if len(reviewers) > 0 {
-				sb.WriteString(fmt.Sprintf("- Reviewers: %s\n", strings.Join(reviewers, ", ")))
-			}
+// Go runs fetch once a slot is free
+func (g *fetchGroup) Go(fetch func()) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		g.sem <- struct{}{}
+		defer func() { <-g.sem }()
+		fetch()
+	}()
+}
 
-			if pr.Body != "" {
-				body := pr.Body
-				if len(body) > 500 {
-					body = body[:500] + "..."
+// Wait blocks until every fetch has finished
+func (g *fetchGroup) Wait() {
+	g.wg.Wait()
+}
+
+// lookupAll runs lookup on each of items in a fetchGroup of at most limit,
+// and returns the first error after every lookup finishes
+func lookupAll[T any](items []T, limit int, lookup func(*T) error) error {
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	g := newFetchGroup(max(limit, 1))
+	for i := range items {
+		g.Go(func() {
+			if err := lookup(&items[i]); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
 				}
-				sb.WriteString(fmt.Sprintf("- Description: %s\n", body))
+				mu.Unlock()
 			}
+		})
+	}
+	g.Wait()
+	return firstErr
+}
 
-			sb.WriteString("\n")
+// fetchRetryBackoff is the pause before the first retry of failed fetches,
+// doubled before each one after it, replaced in tests
+var fetchRetryBackoff = 2 * time.Second
+
+// runFetches runs fetches with g, then runs the ones that returned an error
+// again, up to retries more times with a pause of backoff, doubling each
+// time, before each attempt. Fetches that succeeded keep their results and
+// aren't run again. Only failed gh commands are retried: output that can't
+// be parsed would be the same again, and exhausted rate limits won't have
+// reset by then.
+func runFetches(ctx context.Context, g *fetchGroup, fetches []func() error, retries int, backoff time.Duration) {
+	pending := fetches
+	for attempt := 0; ; attempt++ {
+		errs := make([]error, len(pending))
+		for i, fetch := range pending {
+			g.Go(func() { errs[i] = fetch() })
 		}
+		g.Wait()
+
+		var failed []func() error
+		for i, err := range errs {
+			var cmdErr *ErrCommandFailed
+			if errors.As(err, &cmdErr) {
+				failed = append(failed, pending[i])
+			}
+		}
+		if len(failed) == 0 || attempt >= retries {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff << attempt):
+		}
+		pending = failed
 	}
+}
 
-	// Format Issues
-	if len(issues) > 0 {
-		sb.WriteString("## Closed Issues\n\n")
-		for i, issue := range issues {
-			sb.WriteString(fmt.Sprintf("### Issue #%d: %s\n", i+1, issue.Title))
-			sb.WriteString(fmt.Sprintf("- Repository: %s\n", issue.Repository.NameWithOwner))
-			sb.WriteString(fmt.Sprintf("- Author: @%s\n", issue.Author.Login))
-			sb.WriteString(fmt.Sprintf("- State: %s\n", issue.State))
-			sb.WriteString(fmt.Sprintf("- Created: %s\n", issue.CreatedAt.Format("2006-01-02")))
+// ActivityLoadedMsg is sent when all activity data is loaded
+type ActivityLoadedMsg struct {
+	PRs            []PullRequest
+	Issues         []Issue
+	Reviews        []Review
+	Commits        []Commit
+	CommentedItems []CommentedItem
+	Discussions    []Discussion
+	Warnings       []string
+	Error          error
+}
 
-			if issue.ClosedAt != nil {
-				sb.WriteString(fmt.Sprintf("- Closed: %s\n", issue.ClosedAt.Format("2006-01-02")))
+// FetchActivityCmd runs all fetch functions concurrently and returns an
+// ActivityLoadedMsg, for each of opts.Members in turn if set, with each
+// type of activity sorted newest first (or oldest first with opts.OldestFirst)
+func FetchActivityCmd(dateRange daterange.Range, opts FetchOptions) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if opts.CheckAuth {
+			if err := CheckAuth(); err != nil {
+				return ActivityLoadedMsg{Error: err}
 			}
+		}
 
-			if issue.Body != "" {
-				body := issue.Body
-				if len(body) > 500 {
-					body = body[:500] + "..."
-				}
-				sb.WriteString(fmt.Sprintf("- Description: %s\n", body))
+		var msg ActivityLoadedMsg
+		if len(opts.Members) > 0 {
+			msg = fetchTeamActivity(ctx, dateRange, opts)
+		} else {
+			msg = fetchActivity(ctx, dateRange, opts)
+		}
+		if msg.Error == nil {
+			excludeRepos(ctx, &msg, opts)
+			// Team activity is tagged with each member, whose repos are theirs
+			login := ""
+			if len(opts.Members) == 0 {
+				login = opts.author()
 			}
+			filterOwnRepos(ctx, &msg, opts.OwnRepos, login)
+			SortActivity(&msg, opts.OldestFirst)
+		}
+		msg.Error = withRateLimitReset(ctx, msg.Error)
+		return msg
+	}
+}
+
+// fetchActivity runs all fetch functions for opts.Author concurrently
+func fetchActivity(ctx context.Context, dateRange daterange.Range, opts FetchOptions) ActivityLoadedMsg {
+	var (
+		prs            []PullRequest
+		issues         []Issue
+		assignedIssues []Issue
+		reviews        []Review
+		commits        []Commit
+		commentedPRs   []CommentedItem
+		commentedIssue []CommentedItem
+		discussions    []Discussion
+		prErr, issueErr, assignedErr, reviewErr, commitErr, commentPRErr, commentIssueErr error
+		mergeErr, linkErr, sizeErr, commitStatsErr, discussionErr error
+	)
+
+	// Each fetch returns the error of its search, so only failed searches are
+	// retried. Lookups that follow a search are warnings and aren't retried
+	// on their own.
+	fetches := []func() error{
+		func() error {
+			prs, prErr = FetchPRs(ctx, dateRange, opts)
+			if prErr == nil && opts.ResolveMergeStatus {
+				mergeErr = ResolveMergeStatus(ctx, prs, opts.concurrency())
+			}
+			if prErr == nil && opts.ResolveLinkedIssues {
+				linkErr = ResolveLinkedIssues(ctx, prs, opts.concurrency())
+			}
+			if prErr == nil && opts.ResolvePRSizes {
+				sizeErr = ResolvePRSizes(ctx, prs, opts.concurrency())
+			}
+			return prErr
+		},
+		func() error {
+			issues, issueErr = FetchIssues(ctx, dateRange, opts)
+			return issueErr
+		},
+		func() error {
+			reviews, reviewErr = FetchReviews(ctx, dateRange, opts)
+			return reviewErr
+		},
+		func() error {
+			commits, commitErr = FetchCommits(ctx, dateRange, opts)
+			if commitErr == nil && opts.ResolveCommitStats {
+				commitStatsErr = ResolveCommitStats(ctx, commits)
+			}
+			return commitErr
+		},
+		func() error {
+			commentedPRs, commentPRErr = FetchCommentedPRs(ctx, dateRange, opts)
+			return commentPRErr
+		},
+		func() error {
+			commentedIssue, commentIssueErr = FetchCommentedIssues(ctx, dateRange, opts)
+			return commentIssueErr
+		},
+	}
+	if opts.AssignedIssues {
+		fetches = append(fetches, func() error {
+			assignedIssues, assignedErr = FetchAssignedIssues(ctx, dateRange, opts)
+			return assignedErr
+		})
+	}
+	if opts.Discussions {
+		fetches = append(fetches, func() error {
+			discussions, discussionErr = FetchDiscussions(ctx, dateRange, opts)
+			return discussionErr
+		})
+	}
+
+	runFetches(ctx, newFetchGroup(opts.concurrency()), fetches, opts.Retries, fetchRetryBackoff)
 
-			sb.WriteString("\n")
+	// Return the first error encountered
+	for _, err := range []error{prErr, issueErr, assignedErr, reviewErr, commitErr, commentPRErr, commentIssueErr} {
+		if err != nil {
+			return ActivityLoadedMsg{Error: err}
 		}
 	}
 
-	// Format Reviews
-	if len(reviews) > 0 {
-		sb.WriteString("## Code Reviews Given\n\n")
-		for i, r := range reviews {
-			sb.WriteString(fmt.Sprintf("### Review #%d: %s\n", i+1, r.Title))
-			sb.WriteString(fmt.Sprintf("- Repository: %s\n", r.Repository.NameWithOwner))
-			sb.WriteString(fmt.Sprintf("- PR Author: @%s\n", r.Author.Login))
-			sb.WriteString(fmt.Sprintf("- State: %s\n", r.State))
-			sb.WriteString(fmt.Sprintf("- Created: %s\n", r.CreatedAt.Format("2006-01-02")))
-			sb.WriteString("\n")
+	var warnings []string
+	for _, w := range []string{
+		opts.truncationWarning("PR", len(prs)),
+		opts.truncationWarning("Issue", len(issues)),
+		opts.truncationWarning("Assigned issue", len(assignedIssues)),
+		opts.truncationWarning("Review", len(reviews)),
+		opts.truncationWarning("Commit", len(commits)),
+		opts.truncationWarning("Commented PR", len(commentedPRs)),
+		opts.truncationWarning("Commented issue", len(commentedIssue)),
+		opts.truncationWarning("Discussion", len(discussions)),
+	} {
+		if w != "" {
+			warnings = append(warnings, w)
 		}
 	}
+	if mergeErr != nil {
+		warnings = append(warnings, fmt.Sprintf("Could not resolve merge status for some PRs: %v", mergeErr))
+	}
+	if linkErr != nil {
+		warnings = append(warnings, fmt.Sprintf("Could not look up some linked issues: %v", linkErr))
+	}
+	if sizeErr != nil {
+		warnings = append(warnings, fmt.Sprintf("Could not look up the size of some PRs: %v", sizeErr))
+	}
+	if commitStatsErr != nil {
+		warnings = append(warnings, fmt.Sprintf("Could not look up the lines changed by some commits: %v", commitStatsErr))
+	}
+	// Not every org uses discussions, so a failed search doesn't fail the fetch
+	if discussionErr != nil {
+		warnings = append(warnings, fmt.Sprintf("Could not fetch discussions: %v", discussionErr))
+	}
 
-	// Format Commits
-	if len(commits) > 0 {
-		sb.WriteString("## Commits\n\n")
-		for _, c := range commits {
-			msg := c.Commit.Message
-			// Use first line only
-			if idx := strings.Index(msg, "\n"); idx != -1 {
-				msg = msg[:idx]
-			}
-			if len(msg) > 100 {
-				msg = msg[:100] + "..."
-			}
-			sb.WriteString(fmt.Sprintf("- %s %s (%s, %s)\n",
-				c.SHA[:min(7, len(c.SHA))],
-				msg,
-				c.Repository.FullName,
-				c.Commit.Author.Date.Format("2006-01-02"),
-			))
+	// Merge commented PRs and issues. An item can match more than one
+	// search, so drop repeats wherever lists are combined.
+	commented := dedupeCommented(append(commentedPRs, commentedIssue...))
+	prs = dedupePRs(prs)
+	// Authored issues come first, so an issue both authored and assigned
+	// counts as authored
+	issues = dedupeIssues(append(issues, assignedIssues...))
+	reviews = dedupeReviews(reviews)
+
+	if opts.ReviewExcerpts > 0 && len(reviews) > 0 {
+		if err := FetchReviewExcerpts(ctx, reviews, opts.author(), opts.ReviewExcerpts, opts.concurrency()); err != nil {
+			warnings = append(warnings, fmt.Sprintf("Could not fetch review comments for some PRs: %v", err))
+		}
+	}
+	if opts.CommentExcerpts > 0 && len(commented) > 0 {
+		if err := FetchCommentExcerpts(ctx, commented, opts.author(), opts.CommentExcerpts, opts.concurrency()); err != nil {
+			warnings = append(warnings, fmt.Sprintf("Could not fetch comment text for some items: %v", err))
 		}
-		sb.WriteString("\n")
 	}
 
-	// Format Commented Items
-	if len(commentedItems) > 0 {
-		sb.WriteString("## Items Commented On\n\n")
-		for _, item := range commentedItems {
-			kind := "Issue"
-			if item.IsPR {
-				kind = "PR"
-			}
-			sb.WriteString(fmt.Sprintf("- [%s] %s (%s, %d comments)\n",
-				kind, item.Title, item.Repository.NameWithOwner, item.Comments))
+	return ActivityLoadedMsg{
+		PRs:            prs,
+		Issues:         issues,
+		Reviews:        reviews,
+		Commits:        commits,
+		CommentedItems: commented,
+		Discussions:    discussions,
+		Warnings:       warnings,
+	}
+}
+
+// PriorActivityLoadedMsg is sent when activity for a prior range is loaded
+type PriorActivityLoadedMsg struct {
+	Range    daterange.Range
+	Activity ActivityLoadedMsg
+}
+
+// FetchPriorActivityCmd fetches activity for a prior range to compare
+// against. Only metrics are computed from it, so comment and review
+// excerpts, linked issues, and PR and commit sizes are not looked up.
+func FetchPriorActivityCmd(dateRange daterange.Range, opts FetchOptions) tea.Cmd {
+	opts.CommentExcerpts = 0
+	opts.ReviewExcerpts = 0
+	opts.ResolveLinkedIssues = false
+	opts.ResolvePRSizes = false
+	opts.ResolveCommitStats = false
+	return func() tea.Msg {
+		return PriorActivityLoadedMsg{
+			Range:    dateRange,
+			Activity: FetchActivityCmd(dateRange, opts)().(ActivityLoadedMsg),
 		}
-		sb.WriteString("\n")
 	}
+}
+
+// ComparisonLoadedMsg is sent when activity for a comparison range is loaded
+type ComparisonLoadedMsg struct {
+	PRs     []PullRequest
+	Commits []Commit
+	// Warnings describe repos that couldn't be filtered as configured
+	Warnings []string
+	Error    error
+}
+
+// FetchComparisonCmd fetches PRs and commits for a wider comparison range
+func FetchComparisonCmd(dateRange daterange.Range, opts FetchOptions) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if opts.CheckAuth {
+			if err := CheckAuth(); err != nil {
+				return ComparisonLoadedMsg{Error: err}
+			}
+		}
+
+		authors := opts.Members
+		if len(authors) == 0 {
+			authors = []string{opts.author()}
+		}
 
-	return sb.String()
+		var (
+			prs      []PullRequest
+			commits  []Commit
+			warnings []string
+		)
+		for _, author := range authors {
+			var (
+				authorPRs        []PullRequest
+				authorCommits    []Commit
+				prErr, commitErr error
+			)
+			opts.Author = author
+
+			runFetches(ctx, newFetchGroup(opts.concurrency()), []func() error{
+				func() error {
+					authorPRs, prErr = FetchPRs(ctx, dateRange, opts)
+					return prErr
+				},
+				func() error {
+					authorCommits, commitErr = FetchCommits(ctx, dateRange, opts)
+					return commitErr
+				},
+			}, opts.Retries, fetchRetryBackoff)
+
+			for _, err := range []error{prErr, commitErr} {
+				if err != nil {
+					return ComparisonLoadedMsg{Error: withRateLimitReset(ctx, err)}
+				}
+			}
+			authorActivity := ActivityLoadedMsg{PRs: authorPRs, Commits: authorCommits}
+			filterOwnRepos(ctx, &authorActivity, opts.OwnRepos, author)
+			prs = append(prs, authorActivity.PRs...)
+			commits = append(commits, authorActivity.Commits...)
+			warnings = append(warnings, authorActivity.Warnings...)
+		}
+
+		// Exclude the same repos as the main range so the comparison is like for like
+		activity := ActivityLoadedMsg{PRs: prs, Commits: commits, Warnings: warnings}
+		excludeRepos(ctx, &activity, opts)
+		return ComparisonLoadedMsg{PRs: activity.PRs, Commits: activity.Commits, Warnings: activity.Warnings}
+	}
 }
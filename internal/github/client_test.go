@@ -0,0 +1,263 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+)
+
+// testRange returns March 2025
+func testRange() daterange.Range {
+	return daterange.Range{
+		Start: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestTruncationWarning(t *testing.T) {
+	opts := FetchOptions{Limit: 50}
+	if w := opts.truncationWarning("PR", 49); w != "" {
+		t.Errorf("warning below the limit: %q", w)
+	}
+	w := opts.truncationWarning("PR", 50)
+	if !strings.Contains(w, "PR results may be truncated at 50") {
+		t.Errorf("warning at the limit = %q, want it to say PR results may be truncated at 50", w)
+	}
+
+	if w := (FetchOptions{}).truncationWarning("Commit", DefaultSearchLimit); !strings.Contains(w, "truncated at 1000") {
+		t.Errorf("warning at the default limit = %q, want it to mention 1000", w)
+	}
+}
+
+func TestApplyMergeStatus(t *testing.T) {
+	closedAt := time.Date(2025, time.March, 4, 0, 0, 0, 0, time.UTC)
+	mergedAt := closedAt.Add(-time.Hour)
+
+	// gh search reports merged PRs as closed, so both start out the same
+	merged := PullRequest{State: "closed", ClosedAt: &closedAt}
+	applyMergeStatus(&merged, &mergedAt)
+	if !merged.IsMerged() || merged.State != "merged" {
+		t.Errorf("merged PR: IsMerged() = %v, State = %q, want merged", merged.IsMerged(), merged.State)
+	}
+	if got := merged.MergeTime(); got == nil || !got.Equal(mergedAt) {
+		t.Errorf("merged PR: MergeTime() = %v, want the merge time %v, not the close time", got, mergedAt)
+	}
+
+	unmerged := PullRequest{State: "merged", ClosedAt: &closedAt}
+	applyMergeStatus(&unmerged, nil)
+	if unmerged.IsMerged() || !unmerged.IsClosed() {
+		t.Errorf("closed-unmerged PR: IsMerged() = %v, IsClosed() = %v, want closed", unmerged.IsMerged(), unmerged.IsClosed())
+	}
+	if got := unmerged.MergeTime(); got != nil {
+		t.Errorf("closed-unmerged PR: MergeTime() = %v, want nil", got)
+	}
+}
+
+func TestResolveMergeStatusSkipsOpenPRs(t *testing.T) {
+	// An open PR needs no lookup, so this must succeed without calling gh
+	prs := []PullRequest{{Number: 1, State: "open", Repository: Repository{NameWithOwner: "acme/web"}}}
+	if err := ResolveMergeStatus(context.Background(), prs, 4); err != nil {
+		t.Fatalf("ResolveMergeStatus: %v", err)
+	}
+	if prs[0].State != "open" || prs[0].IsMerged() || prs[0].IsClosed() {
+		t.Errorf("open PR became %+v", prs[0])
+	}
+}
+
+func TestParseGHVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   [3]int
+		ok     bool
+	}{
+		{"gh version 2.40.1 (2023-12-13)\nhttps://github.com/cli/cli/releases/tag/v2.40.1\n", [3]int{2, 40, 1}, true},
+		{"gh version 2.23.0-rc1 (2023-02-01)", [3]int{2, 23, 0}, true},
+		{"gh version DEV", [3]int{}, false},
+		{"hub version 2.14.2", [3]int{}, false},
+		{"", [3]int{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseGHVersion(tt.output)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseGHVersion(%q) = %v, %v, want %v, %v", tt.output, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	fakeGH(t, `echo "gh version 2.20.2 (2022-11-01)"`)
+	if w := CheckVersion(); !strings.Contains(w, "gh 2.20.2 is older than the minimum supported 2.23.0") {
+		t.Errorf("CheckVersion() with an old gh = %q, want an upgrade warning", w)
+	}
+
+	fakeGH(t, `echo "gh version 2.23.0 (2023-02-07)"`)
+	if w := CheckVersion(); w != "" {
+		t.Errorf("CheckVersion() with the minimum gh = %q, want no warning", w)
+	}
+}
+
+func TestUnknownJSONFieldHint(t *testing.T) {
+	fakeGH(t, `echo 'Unknown JSON field: "reviewRequests"' >&2; exit 1`)
+
+	_, err := FetchPRs(context.Background(), testRange(), FetchOptions{})
+	if err == nil {
+		t.Fatal("FetchPRs returned no error")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "please upgrade gh to 2.23.0 or newer") || !strings.Contains(msg, `"reviewRequests"`) {
+		t.Errorf("error = %q, want an upgrade hint naming the field", msg)
+	}
+	var failed *ErrCommandFailed
+	if !errors.As(err, &failed) {
+		t.Errorf("error is %T, want *ErrCommandFailed", err)
+	}
+}
+
+func TestFetchCommentExcerpts(t *testing.T) {
+	fixture, err := filepath.Abs("testdata/issue_comments.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The fixture is every page's comments as gh api --paginate --jq '.[]'
+	// prints them; without --paginate only the first page would be read
+	fakeGH(t, `case "$*" in
+*"--paginate repos/acme/web/issues/4/comments"*) cat '`+fixture+`' ;;
+*) echo "unexpected gh $*" >&2; exit 1 ;;
+esac`)
+
+	items := []CommentedItem{{Number: 4, Title: "Flaky test", Comments: 4, Repository: Repository{Name: "web", NameWithOwner: "acme/web"}}}
+	if err := FetchCommentExcerpts(context.Background(), items, "octocat", 2, 4); err != nil {
+		t.Fatalf("FetchCommentExcerpts: %v", err)
+	}
+
+	want := []string{"Thanks! The retry path still looks racy though.", "LGTM once CI is green."}
+	if !slices.Equal(items[0].CommentExcerpts, want) {
+		t.Errorf("CommentExcerpts = %q, want the last two by octocat %q", items[0].CommentExcerpts, want)
+	}
+
+	out := FormatActivityForClaude(nil, nil, nil, nil, items, nil, "", FormatOptions{IncludeComments: true, MaxBodyLength: DefaultMaxBodyLength})
+	for _, excerpt := range want {
+		if !strings.Contains(out, "  > "+excerpt+"\n") {
+			t.Errorf("formatted activity is missing the excerpt %q:\n%s", excerpt, out)
+		}
+	}
+}
+
+func TestDedupePRs(t *testing.T) {
+	web := Repository{Name: "web", NameWithOwner: "acme/web"}
+	api := Repository{Name: "api", NameWithOwner: "acme/api"}
+	prs := []PullRequest{
+		{Number: 1, Title: "First", Body: "full data", Repository: web},
+		{Number: 2, Title: "Second", Repository: web},
+		{Number: 1, Title: "First again", Repository: web},
+		// The same number in another repo is a different PR
+		{Number: 1, Title: "API first", Repository: api},
+		{Number: 2, Title: "Second again", Repository: web},
+	}
+
+	got := dedupePRs(prs)
+	want := []string{"First", "Second", "API first"}
+	if len(got) != len(want) {
+		t.Fatalf("dedupePRs kept %d PRs, want %d", len(got), len(want))
+	}
+	for i, title := range want {
+		if got[i].Title != title {
+			t.Errorf("PR %d = %q, want %q", i, got[i].Title, title)
+		}
+	}
+	if got[0].Body != "full data" {
+		t.Error("the first occurrence's data wasn't kept")
+	}
+
+	issues := dedupeIssues([]Issue{{Number: 3, Repository: web}, {Number: 3, Repository: web}, {Number: 4, Repository: web}})
+	if len(issues) != 2 || issues[0].Number != 3 || issues[1].Number != 4 {
+		t.Errorf("dedupeIssues = %+v, want issues 3 and 4", issues)
+	}
+}
+
+func TestFetchGroupLimit(t *testing.T) {
+	const limit = 3
+	var running, peak atomic.Int32
+	items := make([]int, 20)
+
+	err := lookupAll(items, limit, func(item *int) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		*item = 1
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("lookupAll: %v", err)
+	}
+
+	if got := peak.Load(); got > limit {
+		t.Errorf("%d lookups overlapped, want at most %d", got, limit)
+	}
+	if got := peak.Load(); got < 2 {
+		t.Errorf("at most %d lookup ran at once, want them to overlap", got)
+	}
+	for i, item := range items {
+		if item != 1 {
+			t.Errorf("item %d wasn't looked up", i)
+		}
+	}
+}
+
+func TestLookupAllReturnsAnError(t *testing.T) {
+	items := []int{1, 2, 3}
+	var looked atomic.Int32
+	err := lookupAll(items, 1, func(item *int) error {
+		looked.Add(1)
+		if *item >= 2 {
+			return fmt.Errorf("lookup %d failed", *item)
+		}
+		return nil
+	})
+	if err == nil || !strings.HasSuffix(err.Error(), "failed") {
+		t.Errorf("error = %v, want one of the failures", err)
+	}
+	if looked.Load() != 3 {
+		t.Errorf("%d lookups ran, want all 3 despite the failure", looked.Load())
+	}
+}
+
+func TestAssignedIssuesDeduped(t *testing.T) {
+	fakeGH(t, `issue() { printf '{"number":%s,"title":"Issue %s","state":"closed","repository":{"name":"web","nameWithOwner":"acme/web"}}' $1 $1; }
+case "$*" in
+"search issues --author "*) echo "[$(issue 1),$(issue 2)]" ;;
+"search issues --assignee "*) echo "[$(issue 2),$(issue 3)]" ;;
+*) echo '[]' ;;
+esac`)
+
+	msg := FetchActivityCmd(testRange(), FetchOptions{AssignedIssues: true})().(ActivityLoadedMsg)
+	if msg.Error != nil {
+		t.Fatalf("FetchActivityCmd: %v", msg.Error)
+	}
+	assigned := make(map[int]bool)
+	for _, issue := range msg.Issues {
+		assigned[issue.Number] = issue.Assigned
+	}
+	want := map[int]bool{1: false, 2: false, 3: true}
+	if len(msg.Issues) != len(want) {
+		t.Fatalf("got %d issues, want %d with #2 once", len(msg.Issues), len(want))
+	}
+	for number, a := range want {
+		if assigned[number] != a {
+			t.Errorf("issue #%d Assigned = %v, want %v", number, assigned[number], a)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// commitStatsBatchSize is the number of commits looked up per GraphQL query
+const commitStatsBatchSize = 50
+
+// ResolveCommitStats sets Stats on each commit with batched GraphQL queries,
+// as gh search commits does not return line counts. Commits in repositories
+// that can't be read, e.g. ones the token has no access to, are left without
+// Stats. An error is returned only if a query could not be run at all, or
+// returned errors and no data.
+func ResolveCommitStats(ctx context.Context, commits []Commit) error {
+	for start := 0; start < len(commits); start += commitStatsBatchSize {
+		batch := commits[start:min(start+commitStatsBatchSize, len(commits))]
+
+		var query strings.Builder
+		query.WriteString("query {")
+		for i, c := range batch {
+			owner, repo, ok := strings.Cut(c.Repository.FullName, "/")
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&query, " c%d: repository(owner: %q, name: %q) { object(oid: %q) { ... on Commit { additions deletions } } }", i, owner, repo, c.SHA)
+		}
+		query.WriteString(" }")
+
+		// gh exits non-zero when any repository can't be resolved, but still
+		// prints the data for the rest
+		output, err := exec.CommandContext(ctx, "gh", "api", "graphql", "-f", "query="+query.String()).Output()
+		if err != nil && len(output) == 0 {
+			return ghError(err)
+		}
+
+		var resp struct {
+			Data map[string]*struct {
+				Object *CommitStats `json:"object"`
+			} `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if err := parseJSON("commit stats", output, &resp); err != nil {
+			return err
+		}
+		if len(resp.Data) == 0 && len(resp.Errors) > 0 {
+			messages := make([]string, len(resp.Errors))
+			for i, e := range resp.Errors {
+				messages[i] = e.Message
+			}
+			return apiMessageError("commit stats", secretPattern.ReplaceAllString(strings.Join(messages, "; "), "[redacted]"))
+		}
+
+		for i := range batch {
+			if r := resp.Data[fmt.Sprintf("c%d", i)]; r != nil && r.Object != nil {
+				stats := *r.Object
+				batch[i].Stats = &stats
+			}
+		}
+	}
+	return nil
+}
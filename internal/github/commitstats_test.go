@@ -0,0 +1,43 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolveCommitStats(t *testing.T) {
+	// gh exits non-zero when a repository can't be resolved, but still
+	// prints the data for the rest
+	fakeGH(t, `echo '{"data":{"c0":{"object":{"additions":10,"deletions":4}},"c1":null},"errors":[{"message":"Could not resolve to a Repository"}]}'; exit 1`)
+
+	var web, secret Commit
+	web.SHA, web.Repository.FullName = "aaa", "acme/web"
+	secret.SHA, secret.Repository.FullName = "bbb", "acme/secret"
+	commits := []Commit{web, secret}
+	if err := ResolveCommitStats(context.Background(), commits); err != nil {
+		t.Fatalf("ResolveCommitStats with partial data: %v", err)
+	}
+	if s := commits[0].Stats; s == nil || s.Additions != 10 || s.Deletions != 4 {
+		t.Errorf("acme/web stats = %+v, want +10/-4", s)
+	}
+	if commits[1].Stats != nil {
+		t.Errorf("acme/secret stats = %+v, want none", commits[1].Stats)
+	}
+}
+
+func TestResolveCommitStatsErrorsOnly(t *testing.T) {
+	fakeGH(t, `echo '{"data":{},"errors":[{"message":"Field object is missing"}]}'; exit 1`)
+
+	var c Commit
+	c.SHA, c.Repository.FullName = "aaa", "acme/web"
+	err := ResolveCommitStats(context.Background(), []Commit{c})
+	var parseErr *ErrParse
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("ResolveCommitStats with only errors = %v, want an *ErrParse", err)
+	}
+	if !strings.Contains(err.Error(), "Field object is missing") {
+		t.Errorf("error %q does not carry the API message", err)
+	}
+}
@@ -0,0 +1,137 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+)
+
+// discussionPageSize is the most results GraphQL search returns per page
+const discussionPageSize = 100
+
+// discussionsQuery searches discussions a page at a time. gh search has no
+// discussions subcommand, so this goes through gh api graphql.
+const discussionsQuery = `query($q: String!, $first: Int!, $after: String) {
+  search(query: $q, type: DISCUSSION, first: $first, after: $after) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ... on Discussion {
+        number
+        title
+        body
+        createdAt
+        isAnswered
+        category { name }
+        author { login }
+        repository { name nameWithOwner }
+        comments { totalCount }
+      }
+    }
+  }
+}`
+
+// discussionsResponse is a page of gh api graphql discussion search results
+type discussionsResponse struct {
+	Data struct {
+		Search *struct {
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Nodes []struct {
+				Discussion
+				Category struct {
+					Name string `json:"name"`
+				} `json:"category"`
+				CommentCount struct {
+					TotalCount int `json:"totalCount"`
+				} `json:"comments"`
+			} `json:"nodes"`
+		} `json:"search"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// ParseDiscussions decodes a page of discussion search results, returning
+// the discussions and the cursor of the next page, or "" on the last page
+func ParseDiscussions(output []byte) ([]Discussion, string, error) {
+	var resp discussionsResponse
+	if err := parseJSON("discussion data", output, &resp); err != nil {
+		return nil, "", err
+	}
+
+	search := resp.Data.Search
+	if search == nil {
+		var messages []string
+		for _, e := range resp.Errors {
+			messages = append(messages, e.Message)
+		}
+		if len(messages) == 0 {
+			return nil, "", &ErrParse{Source: "discussion data", Err: errors.New("no search results in the response"), excerpt: outputExcerpt(output)}
+		}
+		return nil, "", apiMessageError("discussion data", strings.Join(messages, "; "))
+	}
+
+	discussions := make([]Discussion, 0, len(search.Nodes))
+	for _, node := range search.Nodes {
+		// Nodes that aren't discussions decode as empty objects
+		if node.Number == 0 {
+			continue
+		}
+		d := node.Discussion
+		d.Category = node.Category.Name
+		d.Comments = node.CommentCount.TotalCount
+		discussions = append(discussions, d)
+	}
+
+	next := ""
+	if search.PageInfo.HasNextPage {
+		next = search.PageInfo.EndCursor
+	}
+	return discussions, next, nil
+}
+
+// FetchDiscussions fetches the discussions the user started in the range,
+// up to the search limit
+func FetchDiscussions(ctx context.Context, dateRange daterange.Range, opts FetchOptions) ([]Discussion, error) {
+	q := fmt.Sprintf("author:%s created:%s", opts.author(), dateRange.GitHubQueryString(time.Now()))
+
+	var discussions []Discussion
+	cursor := ""
+	for len(discussions) < opts.limit() {
+		args := []string{
+			"api", "graphql",
+			"-f", "query=" + discussionsQuery,
+			"-f", "q=" + q,
+			"-F", "first=" + strconv.Itoa(min(discussionPageSize, opts.limit()-len(discussions))),
+		}
+		if cursor != "" {
+			args = append(args, "-f", "after="+cursor)
+		}
+
+		output, err := exec.CommandContext(ctx, "gh", args...).Output()
+		if err != nil {
+			return nil, ghError(err)
+		}
+
+		page, next, err := ParseDiscussions(output)
+		if err != nil {
+			return nil, err
+		}
+		discussions = append(discussions, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	return discussions, nil
+}
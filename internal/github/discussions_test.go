@@ -0,0 +1,68 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+// discussionsPage is a page of discussion search results with a node that
+// isn't a discussion and another page to follow
+const discussionsPage = `{"data":{"search":{
+  "pageInfo":{"hasNextPage":true,"endCursor":"Y3Vyc29yOjI="},
+  "nodes":[
+    {"number":5,"title":"Roadmap","body":"What's next?","createdAt":"2025-03-08T10:00:00Z","isAnswered":true,
+     "category":{"name":"Ideas"},"author":{"login":"alice"},
+     "repository":{"name":"web","nameWithOwner":"acme/web"},"comments":{"totalCount":3}},
+    {}
+  ]}}}`
+
+func TestParseDiscussions(t *testing.T) {
+	discussions, next, err := ParseDiscussions([]byte(discussionsPage))
+	if err != nil {
+		t.Fatalf("ParseDiscussions: %v", err)
+	}
+	if next != "Y3Vyc29yOjI=" {
+		t.Errorf("next cursor = %q, want %q", next, "Y3Vyc29yOjI=")
+	}
+	if len(discussions) != 1 {
+		t.Fatalf("got %d discussions, want 1 with the empty node skipped", len(discussions))
+	}
+	d := discussions[0]
+	if d.Number != 5 || d.Title != "Roadmap" || d.Category != "Ideas" || d.Comments != 3 || !d.Answered {
+		t.Errorf("discussion = %+v, want #5 Roadmap in Ideas, answered, with 3 comments", d)
+	}
+	if d.Author.Login != "alice" || d.Repository.NameWithOwner != "acme/web" || d.CreatedAt.Day() != 8 {
+		t.Errorf("discussion = %+v, want alice's in acme/web on March 8", d)
+	}
+
+	last := strings.Replace(discussionsPage, `"hasNextPage":true`, `"hasNextPage":false`, 1)
+	if _, next, _ := ParseDiscussions([]byte(last)); next != "" {
+		t.Errorf("next cursor on the last page = %q, want none", next)
+	}
+}
+
+func TestParseDiscussionsErrors(t *testing.T) {
+	_, _, err := ParseDiscussions([]byte(`{"data":{"search":null},"errors":[{"message":"Something went wrong"}]}`))
+	if err == nil || !strings.Contains(err.Error(), "Something went wrong") {
+		t.Errorf("error = %v, want the GraphQL error message", err)
+	}
+}
+
+func TestFormatDiscussions(t *testing.T) {
+	discussions, _, err := ParseDiscussions([]byte(discussionsPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := FormatActivityForClaude(nil, nil, nil, nil, nil, discussions, "", DefaultFormatOptions())
+	for _, want := range []string{"Total Discussions Started: 1", "## Discussions Started", "Roadmap"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("formatted activity is missing %q:\n%s", want, out)
+		}
+	}
+
+	opts := DefaultFormatOptions()
+	opts.IncludeDiscussions = false
+	if out := FormatActivityForClaude(nil, nil, nil, nil, nil, discussions, "", opts); strings.Contains(out, "## Discussions Started") {
+		t.Errorf("discussions were formatted with IncludeDiscussions off:\n%s", out)
+	}
+}
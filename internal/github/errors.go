@@ -0,0 +1,165 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// rateLimitLookupTimeout bounds the gh api rate_limit call made to find when
+// an exhausted rate limit resets
+const rateLimitLookupTimeout = 10 * time.Second
+
+// ErrAuthFailed is returned by CheckAuth when gh is missing or not logged in
+type ErrAuthFailed struct {
+	NotInstalled bool // gh itself could not be found
+}
+
+func (e *ErrAuthFailed) Error() string {
+	if e.NotInstalled {
+		return "gh CLI not found. Please install it from https://cli.github.com/"
+	}
+	return "gh CLI not authenticated. Run 'gh auth login' first"
+}
+
+// ErrRateLimited is returned when GitHub's API rate limit stopped a request.
+// ResetAt is looked up by withRateLimitReset once a fetch has failed, not
+// by every request the limit stops.
+type ErrRateLimited struct {
+	ResetAt time.Time // when the limit resets, zero if unknown
+
+	lookedUp bool // ResetAt has been looked up, successfully or not
+}
+
+func (e *ErrRateLimited) Error() string {
+	if e.ResetAt.IsZero() {
+		return "GitHub API rate limit exceeded; try again later"
+	}
+	return "GitHub API rate limit exceeded; it resets at " + e.ResetAt.Local().Format("15:04:05")
+}
+
+// ErrCommandFailed is returned when a gh command could not be run or exited
+// with an error
+type ErrCommandFailed struct {
+	Stderr string // gh's error output, empty if gh could not be run
+	Err    error
+	hint   string // explanation shown in place of Stderr, e.g. for an outdated gh
+}
+
+func (e *ErrCommandFailed) Error() string {
+	switch {
+	case e.hint != "":
+		return e.hint
+	case e.Stderr != "":
+		return "gh command failed: " + e.Stderr
+	}
+	return fmt.Sprintf("gh command failed: %v", e.Err)
+}
+
+func (e *ErrCommandFailed) Unwrap() error {
+	return e.Err
+}
+
+// ErrParse is returned when gh's output isn't the expected results, either
+// because it couldn't be decoded or because it holds an API error instead
+type ErrParse struct {
+	Source     string // what was being read, e.g. "PR data"
+	APIMessage string // the API's error message, if it sent one
+	Err        error  // the decoding error, if any
+	excerpt    string // quoted start and end of the output
+}
+
+func (e *ErrParse) Error() string {
+	if e.APIMessage != "" {
+		return fmt.Sprintf("gh returned an error for %s: %s", e.Source, e.APIMessage)
+	}
+	return fmt.Sprintf("failed to parse %s: %v (output: %s)", e.Source, e.Err, e.excerpt)
+}
+
+func (e *ErrParse) Unwrap() error {
+	return e.Err
+}
+
+// ghError turns a failed gh invocation into an error, explaining the
+// "unknown JSON field" failure older gh versions give for newer fields
+func ghError(err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return &ErrCommandFailed{Err: err}
+	}
+	stderr := string(exitErr.Stderr)
+	if isRateLimited(stderr) {
+		return &ErrRateLimited{}
+	}
+	failed := &ErrCommandFailed{Stderr: stderr, Err: err}
+	if strings.Contains(strings.ToLower(stderr), "unknown json field") {
+		field, _, _ := strings.Cut(stderr, "\n")
+		failed.hint = fmt.Sprintf("your gh CLI is too old for activitycat (%s); please upgrade gh to %d.%d.%d or newer: https://cli.github.com/",
+			strings.TrimSpace(field), minGHVersion[0], minGHVersion[1], minGHVersion[2])
+	}
+	return failed
+}
+
+// apiMessageError reports an error message the API sent in place of the
+// results for source
+func apiMessageError(source, message string) error {
+	if isRateLimited(message) {
+		return &ErrRateLimited{}
+	}
+	return &ErrParse{Source: source, APIMessage: message}
+}
+
+// isRateLimited reports whether an error message is GitHub's primary or
+// secondary rate limit
+func isRateLimited(message string) bool {
+	return strings.Contains(strings.ToLower(message), "rate limit")
+}
+
+// withRateLimitReset returns err, with when the limit resets filled in if it
+// is an exhausted rate limit that hasn't been looked up yet
+func withRateLimitReset(ctx context.Context, err error) error {
+	var rateLimited *ErrRateLimited
+	if errors.As(err, &rateLimited) && !rateLimited.lookedUp {
+		rateLimited.ResetAt = rateLimitReset(ctx)
+		rateLimited.lookedUp = true
+	}
+	return err
+}
+
+// rateLimitReset asks GitHub when the exhausted rate limit resets, returning
+// the zero time if that can't be found out
+func rateLimitReset(ctx context.Context) time.Time {
+	ctx, cancel := context.WithTimeout(ctx, rateLimitLookupTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "gh", "api", "rate_limit").Output()
+	if err != nil {
+		return time.Time{}
+	}
+	return parseRateLimitReset(output)
+}
+
+// parseRateLimitReset returns the latest reset time of the exhausted
+// resources in gh api rate_limit output, or the zero time if none are
+func parseRateLimitReset(output []byte) time.Time {
+	var limits struct {
+		Resources map[string]struct {
+			Remaining int   `json:"remaining"`
+			Reset     int64 `json:"reset"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(output, &limits); err != nil {
+		return time.Time{}
+	}
+
+	var reset time.Time
+	for _, r := range limits.Resources {
+		if at := time.Unix(r.Reset, 0); r.Remaining == 0 && at.After(reset) {
+			reset = at
+		}
+	}
+	return reset
+}
@@ -0,0 +1,96 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ghFailure runs the fake gh and returns ghError of its failure
+func ghFailure(t *testing.T, stderr string) error {
+	t.Helper()
+	fakeGH(t, "echo '"+stderr+"' >&2\nexit 1")
+	_, err := exec.Command("gh", "search", "prs").Output()
+	if err == nil {
+		t.Fatal("fake gh succeeded")
+	}
+	return ghError(err)
+}
+
+func TestGHErrorTypes(t *testing.T) {
+	var rateLimited *ErrRateLimited
+	if err := ghFailure(t, "API rate limit exceeded for user ID 1."); !errors.As(err, &rateLimited) || !rateLimited.ResetAt.IsZero() {
+		t.Errorf("ghError = %#v, want an ErrRateLimited not yet looked up", err)
+	}
+
+	var failed *ErrCommandFailed
+	err := ghFailure(t, "Unknown JSON field: \"closedAt\"")
+	if !errors.As(err, &failed) || !strings.Contains(err.Error(), "too old") {
+		t.Errorf("ghError = %v, want an ErrCommandFailed explaining gh is too old", err)
+	}
+	err = ghFailure(t, "HTTP 502: Bad Gateway")
+	if !errors.As(err, &failed) || failed.Stderr == "" || !strings.Contains(err.Error(), "Bad Gateway") {
+		t.Errorf("ghError = %v, want an ErrCommandFailed with gh's output", err)
+	}
+	if err := ghError(exec.ErrNotFound); !errors.As(err, &failed) || !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("ghError = %v, want an ErrCommandFailed wrapping the error", err)
+	}
+}
+
+func TestAPIMessageErrorTypes(t *testing.T) {
+	var parseErr *ErrParse
+	err := apiMessageError("PR data", "Validation Failed")
+	if !errors.As(err, &parseErr) || parseErr.APIMessage != "Validation Failed" || parseErr.Source != "PR data" {
+		t.Errorf("apiMessageError = %#v, want an ErrParse with the API message", err)
+	}
+	var rateLimited *ErrRateLimited
+	if err := apiMessageError("PR data", "You have exceeded a secondary rate limit"); !errors.As(err, &rateLimited) {
+		t.Errorf("apiMessageError = %#v, want an ErrRateLimited", err)
+	}
+}
+
+func TestParseDiscussionsWithoutResults(t *testing.T) {
+	_, _, err := ParseDiscussions([]byte(`{"data":{"search":null}}`))
+	var parseErr *ErrParse
+	if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), "no search results") {
+		t.Errorf("ParseDiscussions = %v, want an ErrParse saying there were no results", err)
+	}
+}
+
+func TestRateLimitResetLookedUpOnce(t *testing.T) {
+	reset := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	calls := filepath.Join(t.TempDir(), "calls")
+	fakeGH(t, `case "$*" in
+"api rate_limit") echo x >> `+calls+`; echo '{"resources":{"core":{"remaining":10,"reset":1},"search":{"remaining":0,"reset":`+strconv.FormatInt(reset.Unix(), 10)+`}}}' ;;
+*) echo 'API rate limit exceeded' >&2; exit 1 ;;
+esac`)
+
+	// Every search is rate limited, but the reset is looked up once
+	msg := FetchActivityCmd(testRange(), FetchOptions{Author: "alice"})().(ActivityLoadedMsg)
+	var rateLimited *ErrRateLimited
+	if !errors.As(msg.Error, &rateLimited) || !rateLimited.ResetAt.Equal(reset) {
+		t.Fatalf("Error = %v, want an ErrRateLimited resetting at %v", msg.Error, reset)
+	}
+	withRateLimitReset(context.Background(), msg.Error)
+	output, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(output), "\n"); n != 1 {
+		t.Errorf("gh api rate_limit ran %d times, want once", n)
+	}
+
+	// The lookup is made with the caller's context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rateLimited = &ErrRateLimited{}
+	if withRateLimitReset(ctx, rateLimited); !rateLimited.ResetAt.IsZero() {
+		t.Errorf("reset = %v with a cancelled context, want it unknown", rateLimited.ResetAt)
+	}
+}
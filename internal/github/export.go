@@ -0,0 +1,151 @@
+package github
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Activity type discriminators used in exports
+const (
+	TypePR         = "pr"
+	TypeIssue      = "issue"
+	TypeReview     = "review"
+	TypeCommit     = "commit"
+	TypeComment    = "comment"
+	TypeDiscussion = "discussion"
+)
+
+// WriteJSONLines writes each activity item as a single-line JSON object with
+// a "type" field, so large exports can be streamed into tools like jq.
+func WriteJSONLines(
+	w io.Writer,
+	prs []PullRequest,
+	issues []Issue,
+	reviews []Review,
+	commits []Commit,
+	commentedItems []CommentedItem,
+	discussions []Discussion,
+) error {
+	enc := json.NewEncoder(w)
+
+	for _, pr := range prs {
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			PullRequest
+		}{TypePR, pr}); err != nil {
+			return fmt.Errorf("failed to write PR: %w", err)
+		}
+	}
+	for _, issue := range issues {
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			Issue
+		}{TypeIssue, issue}); err != nil {
+			return fmt.Errorf("failed to write issue: %w", err)
+		}
+	}
+	for _, r := range reviews {
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			Review
+		}{TypeReview, r}); err != nil {
+			return fmt.Errorf("failed to write review: %w", err)
+		}
+	}
+	for _, c := range commits {
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			Commit
+		}{TypeCommit, c}); err != nil {
+			return fmt.Errorf("failed to write commit: %w", err)
+		}
+	}
+	for _, ci := range commentedItems {
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			CommentedItem
+		}{TypeComment, ci}); err != nil {
+			return fmt.Errorf("failed to write commented item: %w", err)
+		}
+	}
+	for _, d := range discussions {
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			Discussion
+		}{TypeDiscussion, d}); err != nil {
+			return fmt.Errorf("failed to write discussion: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ReadJSONLines parses activity written by WriteJSONLines
+func ReadJSONLines(r io.Reader) (ActivityLoadedMsg, error) {
+	var msg ActivityLoadedMsg
+
+	scanner := bufio.NewScanner(r)
+	// Items with long bodies can exceed the default 64KB line limit
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Bytes()
+		if strings.TrimSpace(string(line)) == "" {
+			continue
+		}
+
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(line, &header); err != nil {
+			return ActivityLoadedMsg{}, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		var err error
+		switch header.Type {
+		case TypePR:
+			var pr PullRequest
+			if err = json.Unmarshal(line, &pr); err == nil {
+				msg.PRs = append(msg.PRs, pr)
+			}
+		case TypeIssue:
+			var issue Issue
+			if err = json.Unmarshal(line, &issue); err == nil {
+				msg.Issues = append(msg.Issues, issue)
+			}
+		case TypeReview:
+			var review Review
+			if err = json.Unmarshal(line, &review); err == nil {
+				msg.Reviews = append(msg.Reviews, review)
+			}
+		case TypeCommit:
+			var commit Commit
+			if err = json.Unmarshal(line, &commit); err == nil {
+				msg.Commits = append(msg.Commits, commit)
+			}
+		case TypeComment:
+			var item CommentedItem
+			if err = json.Unmarshal(line, &item); err == nil {
+				msg.CommentedItems = append(msg.CommentedItems, item)
+			}
+		case TypeDiscussion:
+			var d Discussion
+			if err = json.Unmarshal(line, &d); err == nil {
+				msg.Discussions = append(msg.Discussions, d)
+			}
+		default:
+			err = fmt.Errorf("unknown activity type %q", header.Type)
+		}
+		if err != nil {
+			return ActivityLoadedMsg{}, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ActivityLoadedMsg{}, fmt.Errorf("failed to read import: %w", err)
+	}
+
+	return msg, nil
+}
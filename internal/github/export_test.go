@@ -0,0 +1,59 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteJSONLines(t *testing.T) {
+	created := time.Date(2025, time.March, 3, 10, 0, 0, 0, time.UTC)
+	repo := Repository{Name: "web", NameWithOwner: "acme/web"}
+	prs := []PullRequest{{Number: 1, Title: "Add caching", State: "merged", CreatedAt: created, Repository: repo}}
+	issues := []Issue{{Number: 2, Title: "Slow page", State: "open", CreatedAt: created, Repository: repo}}
+	commits := []Commit{{SHA: "abc123", Commit: CommitDetail{Message: "Fix typo"}, Repository: CommitRepository{FullName: "acme/web"}}}
+
+	var buf bytes.Buffer
+	if err := WriteJSONLines(&buf, prs, issues, nil, commits, nil, nil); err != nil {
+		t.Fatalf("WriteJSONLines: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	wantTypes := []string{TypePR, TypeIssue, TypeCommit}
+	if len(lines) != len(wantTypes) {
+		t.Fatalf("wrote %d lines, want %d:\n%s", len(lines), len(wantTypes), buf.String())
+	}
+	// Each line must parse on its own, as a tool like jq would read it
+	for i, line := range lines {
+		var item map[string]any
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Fatalf("line %d does not parse on its own: %v", i+1, err)
+		}
+		if item["type"] != wantTypes[i] {
+			t.Errorf("line %d has type %v, want %q", i+1, item["type"], wantTypes[i])
+		}
+	}
+
+	msg, err := ReadJSONLines(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSONLines: %v", err)
+	}
+	if len(msg.PRs) != 1 || msg.PRs[0].Title != "Add caching" {
+		t.Errorf("read back PRs %+v, want the written PR", msg.PRs)
+	}
+	if len(msg.Issues) != 1 || msg.Issues[0].Number != 2 {
+		t.Errorf("read back issues %+v, want the written issue", msg.Issues)
+	}
+	if len(msg.Commits) != 1 || msg.Commits[0].SHA != "abc123" {
+		t.Errorf("read back commits %+v, want the written commit", msg.Commits)
+	}
+}
+
+func TestReadJSONLinesUnknownType(t *testing.T) {
+	_, err := ReadJSONLines(strings.NewReader(`{"type":"pr","number":1}` + "\n" + `{"type":"gist"}` + "\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("error = %v, want one naming line 2", err)
+	}
+}
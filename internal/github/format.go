@@ -0,0 +1,619 @@
+package github
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+)
+
+// DefaultMaxBodyLength is the default number of runes kept from PR and issue descriptions
+const DefaultMaxBodyLength = 500
+
+// FormatOptions controls what FormatActivityForClaude includes and how
+type FormatOptions struct {
+	IncludePRs      bool
+	IncludeIssues   bool
+	IncludeReviews  bool
+	IncludeCommits  bool
+	IncludeComments bool
+	// IncludeDiscussions only has an effect when discussions were fetched
+	IncludeDiscussions bool
+
+	// MaxBodyLength truncates descriptions to this many runes, DefaultMaxBodyLength if zero
+	MaxBodyLength int
+	// FocusRepos are listed first within each section
+	FocusRepos []string
+	// FullCommitMessages includes each commit's body, truncated to
+	// MaxBodyLength, instead of only its subject line
+	FullCommitMessages bool
+	// Highlights are one-line descriptions of the PRs the report should
+	// call out, listed before the PRs when they are included
+	Highlights []string
+	// Preamble is a house-style instruction sent ahead of the prompt
+	Preamble string
+	// MaxItemsPerType keeps only the most recent items of each type, with
+	// a note in each section of how many there were; zero sends them all
+	MaxItemsPerType int
+	// GroupByLabel lists PRs under the LabelSections headings their labels
+	// fall in, as FormatActivityByLabel does, instead of in a single list
+	GroupByLabel  bool
+	LabelSections []LabelSection
+	// MaxPromptChars caps the formatted activity at about this many
+	// characters, leaving out bodies and then whole items with a note when
+	// it would run over; zero for no limit
+	MaxPromptChars int
+	// OldestFirst lists each section's items oldest first instead of
+	// newest first
+	OldestFirst bool
+	// DateFormat is the Go layout dates are written in,
+	// daterange.DefaultDateFormat if empty
+	DateFormat string
+	// RepoAliases are labels repositories are named by in place of
+	// "owner/name", see RepoLabel
+	RepoAliases map[string]string
+}
+
+// LabelSection is a heading PRs with any of its labels are grouped under
+type LabelSection struct {
+	Heading string
+	Labels  []string
+}
+
+// OtherLabelSection is the heading for PRs with no configured label
+const OtherLabelSection = "Other"
+
+// DefaultFormatOptions includes every section
+func DefaultFormatOptions() FormatOptions {
+	return FormatOptions{
+		IncludePRs:         true,
+		IncludeIssues:      true,
+		IncludeReviews:     true,
+		IncludeCommits:     true,
+		IncludeComments:    true,
+		IncludeDiscussions: true,
+		MaxBodyLength:      DefaultMaxBodyLength,
+	}
+}
+
+// IncludeOnly returns opts with only the named sections included. Valid names
+// are prs, issues, reviews, commits, comments, and discussions; unknown names
+// are ignored.
+func (opts FormatOptions) IncludeOnly(sections []string) FormatOptions {
+	opts.IncludePRs, opts.IncludeIssues, opts.IncludeReviews = false, false, false
+	opts.IncludeCommits, opts.IncludeComments, opts.IncludeDiscussions = false, false, false
+	for _, s := range sections {
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "prs":
+			opts.IncludePRs = true
+		case "issues":
+			opts.IncludeIssues = true
+		case "reviews":
+			opts.IncludeReviews = true
+		case "commits":
+			opts.IncludeCommits = true
+		case "comments":
+			opts.IncludeComments = true
+		case "discussions":
+			opts.IncludeDiscussions = true
+		}
+	}
+	return opts
+}
+
+// sortByFocus stably moves items from focus repos to the front, in focus order
+func sortByFocus[T any](items []T, repo func(T) string, focus []string) []T {
+	if len(focus) == 0 {
+		return items
+	}
+	rank := make(map[string]int, len(focus))
+	for i, r := range focus {
+		rank[r] = i
+	}
+	rankOf := func(item T) int {
+		if r, ok := rank[repo(item)]; ok {
+			return r
+		}
+		return len(focus)
+	}
+
+	sorted := append([]T(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rankOf(sorted[i]) < rankOf(sorted[j])
+	})
+	return sorted
+}
+
+// truncateRunes shortens s to at most n runes, adding "..." if it was cut.
+// Only the kept runes are walked, so long bodies aren't copied.
+func truncateRunes(s string, n int) string {
+	count := 0
+	for i := range s {
+		if count < n {
+			count++
+			continue
+		}
+		kept := s[:i]
+		// Invalid bytes become U+FFFD, as converting by rune would
+		if !utf8.ValidString(kept) {
+			kept = string([]rune(kept))
+		}
+		return kept + "..."
+	}
+	return s
+}
+
+// linkedIssues returns the issues a PR closes: the resolved ones if they
+// were looked up, otherwise the references parsed from its body
+func linkedIssues(pr PullRequest) []LinkedIssue {
+	if pr.LinkedIssues != nil {
+		return pr.LinkedIssues
+	}
+	return ParseLinkedIssues(pr.Body, pr.Repository.NameWithOwner)
+}
+
+// byMember returns ", by @member" for items fetched for a team member, or ""
+func byMember(member string) string {
+	if member == "" {
+		return ""
+	}
+	return ", by @" + member
+}
+
+// mostRecent returns the n most recent items, in their original order, along
+// with how many there were. Every item is kept if n is zero.
+func mostRecent[T any](items []T, n int, at func(T) time.Time) ([]T, int) {
+	if n <= 0 || len(items) <= n {
+		return items, len(items)
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return at(items[order[a]]).After(at(items[order[b]]))
+	})
+
+	keep := make([]bool, len(items))
+	for _, i := range order[:n] {
+		keep[i] = true
+	}
+	kept := make([]T, 0, n)
+	for i, item := range items {
+		if keep[i] {
+			kept = append(kept, item)
+		}
+	}
+	return kept, len(items)
+}
+
+// writeHeading writes an item's heading, like "### PR #3: Title", at the
+// given level
+func writeHeading(w itemWriter, level, kind string, n int, title string) {
+	w.WriteString(level)
+	w.WriteString(" ")
+	w.WriteString(kind)
+	w.WriteString(" #")
+	w.WriteString(strconv.Itoa(n))
+	w.WriteString(": ")
+	w.WriteString(title)
+	w.WriteString("\n")
+}
+
+// writeField writes a "- name: value" line. Items are written piece by
+// piece rather than with fmt, as formatting thousands of them is otherwise
+// dominated by allocations.
+func writeField(w itemWriter, name, value string) {
+	w.WriteString("- ")
+	w.WriteString(name)
+	w.WriteString(": ")
+	w.WriteString(value)
+	w.WriteString("\n")
+}
+
+// writeExcerpts writes each excerpt as an indented "  > " quote line
+func writeExcerpts(w itemWriter, excerpts []string) {
+	for _, excerpt := range excerpts {
+		w.WriteString("  > ")
+		w.WriteString(excerpt)
+		w.WriteString("\n")
+	}
+}
+
+// writePR writes pr as the nth entry of a list, under a heading of the given
+// level like "###", with up to maxBody runes of its description, or none if
+// maxBody is zero. Its labels are listed only when labels is set, for PRs
+// grouped by label. Dates are in opts.DateFormat.
+func writePR(w itemWriter, level string, n int, pr PullRequest, maxBody int, labels bool, opts FormatOptions) {
+	writeHeading(w, level, "PR", n, pr.Title)
+	writeField(w, "Repository", RepoLabel(opts.RepoAliases, pr.Repository.NameWithOwner))
+	writeField(w, "Author", pr.Author.DisplayLogin())
+	writeField(w, "State", pr.State)
+	writeField(w, "Created", daterange.FormatDate(pr.CreatedAt, opts.DateFormat))
+
+	if mt := pr.MergeTime(); mt != nil {
+		writeField(w, "Merged", daterange.FormatDate(*mt, opts.DateFormat))
+	} else if pr.ClosedAt != nil {
+		writeField(w, "Closed", daterange.FormatDate(*pr.ClosedAt, opts.DateFormat))
+	}
+
+	if names := pr.LabelNames(); labels && len(names) > 0 {
+		writeField(w, "Labels", strings.Join(names, ", "))
+	}
+
+	reviewers := pr.Reviewers()
+	if len(reviewers) > 0 {
+		writeField(w, "Reviewers", strings.Join(reviewers, ", "))
+	}
+
+	for _, issue := range linkedIssues(pr) {
+		if issue.Title != "" {
+			writeField(w, "Resolves", issue.Title+" ("+issue.String()+")")
+		} else {
+			writeField(w, "Resolves", issue.String())
+		}
+	}
+
+	if pr.Body != "" && maxBody > 0 {
+		writeField(w, "Description", truncateRunes(pr.Body, maxBody))
+	}
+
+	w.WriteString("\n")
+}
+
+// labelSection returns the heading of the first section with one of pr's
+// labels, compared case-insensitively, or OtherLabelSection
+func labelSection(pr PullRequest, sections []LabelSection) string {
+	for _, section := range sections {
+		for _, want := range section.Labels {
+			for _, label := range pr.Labels {
+				if strings.EqualFold(label.Name, want) {
+					return section.Heading
+				}
+			}
+		}
+	}
+	return OtherLabelSection
+}
+
+// FormatActivityByLabel lists PRs under a "###" heading for each of
+// opts.LabelSections, in order, with those matching no section under "Other"
+// last. A PR with labels from several sections is listed under the first.
+// Empty sections are left out.
+func FormatActivityByLabel(prs []PullRequest, maxBody int, opts FormatOptions) string {
+	var sb strings.Builder
+	formatByLabel(&promptBudget{sb: &sb}, prs, maxBody, opts)
+	return sb.String()
+}
+
+// formatByLabel writes PRs grouped as FormatActivityByLabel does, within budget
+func formatByLabel(budget *promptBudget, prs []PullRequest, maxBody int, opts FormatOptions) {
+	sections := opts.LabelSections
+	grouped := make(map[string][]PullRequest)
+	for _, pr := range prs {
+		heading := labelSection(pr, sections)
+		grouped[heading] = append(grouped[heading], pr)
+	}
+
+	headings := make([]string, 0, len(sections)+1)
+	for _, section := range sections {
+		headings = append(headings, section.Heading)
+	}
+	headings = append(headings, OtherLabelSection)
+
+	n := 0
+	for _, heading := range headings {
+		group := grouped[heading]
+		if len(group) == 0 {
+			continue
+		}
+		delete(grouped, heading)
+		budget.write(fmt.Sprintf("### %s (%d)\n\n", heading, len(group)))
+		for _, pr := range group {
+			n++
+			budget.item(maxBody, func(w itemWriter, maxBody int) {
+				writePR(w, "####", n, pr, maxBody, true, opts)
+			})
+		}
+	}
+}
+
+// sampleNote returns " (showing n of total)" when a section was capped
+func sampleNote(n, total int) string {
+	if n == total {
+		return ""
+	}
+	return fmt.Sprintf(" (showing %d of %d)", n, total)
+}
+
+// itemSizeEstimate is roughly how many bytes of an item's formatting are
+// not its title or description
+const itemSizeEstimate = 160
+
+// formatSizeEstimate guesses the size of the formatted activity from its
+// titles and descriptions, so it is built without repeatedly regrowing
+func formatSizeEstimate(prs []PullRequest, issues []Issue, reviews []Review, commits []Commit, commentedItems []CommentedItem, discussions []Discussion, metricsText string, maxBody int, opts FormatOptions) int {
+	size := len(metricsText) + 512
+	body := func(s string) int { return min(len(s), maxBody+3) }
+	if opts.IncludePRs {
+		for _, pr := range prs {
+			size += itemSizeEstimate + len(pr.Title) + body(pr.Body)
+		}
+	}
+	if opts.IncludeIssues {
+		for _, issue := range issues {
+			size += itemSizeEstimate + len(issue.Title) + body(issue.Body)
+		}
+	}
+	if opts.IncludeReviews {
+		for _, r := range reviews {
+			size += itemSizeEstimate + len(r.Title)
+		}
+	}
+	if opts.IncludeCommits {
+		for _, c := range commits {
+			size += itemSizeEstimate / 2
+			if opts.FullCommitMessages {
+				size += body(c.Commit.Message)
+			} else {
+				size += min(len(c.Commit.Message), 103)
+			}
+		}
+	}
+	if opts.IncludeComments {
+		size += len(commentedItems) * itemSizeEstimate
+	}
+	if opts.IncludeDiscussions {
+		for _, d := range discussions {
+			size += itemSizeEstimate + len(d.Title) + body(d.Body)
+		}
+	}
+	// A budget caps the output, give or take its truncation note
+	if opts.MaxPromptChars > 0 {
+		size = min(size, opts.MaxPromptChars+truncationNoteReserve)
+	}
+	return size
+}
+
+// FormatActivityForClaude converts all activity data to a text format suitable for Claude API.
+// Sections excluded by opts are omitted entirely, including their headers and totals.
+func FormatActivityForClaude(prs []PullRequest, issues []Issue, reviews []Review, commits []Commit, commentedItems []CommentedItem, discussions []Discussion, metricsText string, opts FormatOptions) string {
+	var sb strings.Builder
+
+	maxBody := opts.MaxBodyLength
+	if maxBody <= 0 {
+		maxBody = DefaultMaxBodyLength
+	}
+
+	// Sort so the formatted activity is the same however concurrent fetches
+	// happened to return it
+	sorted := ActivityLoadedMsg{PRs: prs, Issues: issues, Reviews: reviews, Commits: commits, CommentedItems: commentedItems, Discussions: discussions}
+	SortActivity(&sorted, opts.OldestFirst)
+	prs, issues, reviews, commits = sorted.PRs, sorted.Issues, sorted.Reviews, sorted.Commits
+	commentedItems, discussions = sorted.CommentedItems, sorted.Discussions
+
+	// Totals and review outcomes cover every item, not just those sent
+	reviewOutcomes := ReviewOutcomes(reviews)
+	prs, totalPRs := mostRecent(prs, opts.MaxItemsPerType, func(pr PullRequest) time.Time { return pr.CreatedAt })
+	issues, totalIssues := mostRecent(issues, opts.MaxItemsPerType, func(i Issue) time.Time {
+		if i.ClosedAt != nil {
+			return *i.ClosedAt
+		}
+		return i.CreatedAt
+	})
+	reviews, totalReviews := mostRecent(reviews, opts.MaxItemsPerType, func(r Review) time.Time { return r.CreatedAt })
+	commits, totalCommits := mostRecent(commits, opts.MaxItemsPerType, func(c Commit) time.Time { return c.Commit.Author.Date })
+	commentedItems, totalCommented := mostRecent(commentedItems, opts.MaxItemsPerType, func(ci CommentedItem) time.Time { return ci.UpdatedAt })
+	discussions, totalDiscussions := mostRecent(discussions, opts.MaxItemsPerType, func(d Discussion) time.Time { return d.CreatedAt })
+
+	prs = sortByFocus(prs, func(pr PullRequest) string { return pr.Repository.NameWithOwner }, opts.FocusRepos)
+	issues = sortByFocus(issues, func(i Issue) string { return i.Repository.NameWithOwner }, opts.FocusRepos)
+	reviews = sortByFocus(reviews, func(r Review) string { return r.Repository.NameWithOwner }, opts.FocusRepos)
+	commits = sortByFocus(commits, func(c Commit) string { return c.Repository.FullName }, opts.FocusRepos)
+	commentedItems = sortByFocus(commentedItems, func(ci CommentedItem) string { return ci.Repository.NameWithOwner }, opts.FocusRepos)
+	discussions = sortByFocus(discussions, func(d Discussion) string { return d.Repository.NameWithOwner }, opts.FocusRepos)
+
+	sb.Grow(formatSizeEstimate(prs, issues, reviews, commits, commentedItems, discussions, metricsText, maxBody, opts))
+	sb.WriteString("# GitHub Activity Report\n\n")
+
+	// Metrics summary at top
+	if metricsText != "" {
+		sb.WriteString("## Metrics Summary\n\n")
+		sb.WriteString(metricsText)
+		sb.WriteString("\n\n")
+	}
+
+	if opts.IncludePRs {
+		fmt.Fprintf(&sb, "Total PRs: %d\n", totalPRs)
+	}
+	if opts.IncludeIssues {
+		fmt.Fprintf(&sb, "Total Closed Issues: %d\n", totalIssues)
+	}
+	if opts.IncludeReviews {
+		fmt.Fprintf(&sb, "Total Reviews Given: %d\n", totalReviews)
+	}
+	if opts.IncludeCommits {
+		fmt.Fprintf(&sb, "Total Commits: %d\n", totalCommits)
+	}
+	if opts.IncludeComments {
+		fmt.Fprintf(&sb, "Total Items Commented On: %d\n", totalCommented)
+	}
+	// Discussions are only fetched when enabled, so none may mean not fetched
+	if opts.IncludeDiscussions && totalDiscussions > 0 {
+		fmt.Fprintf(&sb, "Total Discussions Started: %d\n", totalDiscussions)
+	}
+	sb.WriteString("\n")
+
+	if opts.IncludePRs && len(opts.Highlights) > 0 {
+		sb.WriteString("## Highlight These\n\n")
+		for _, h := range opts.Highlights {
+			sb.WriteString("- " + h + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	// The budget covers the header, metrics, and totals already written too
+	budget := &promptBudget{sb: &sb, max: opts.MaxPromptChars, used: utf8.RuneCountInString(sb.String())}
+
+	// Format PRs
+	if opts.IncludePRs && len(prs) > 0 {
+		budget.write("## Pull Requests" + sampleNote(len(prs), totalPRs) + "\n\n")
+		if opts.GroupByLabel && len(opts.LabelSections) > 0 {
+			formatByLabel(budget, prs, maxBody, opts)
+		} else {
+			for i, pr := range prs {
+				budget.item(maxBody, func(w itemWriter, maxBody int) {
+					writePR(w, "###", i+1, pr, maxBody, false, opts)
+				})
+			}
+		}
+	}
+
+	// Format Issues
+	if opts.IncludeIssues && len(issues) > 0 {
+		budget.write("## Closed Issues" + sampleNote(len(issues), totalIssues) + "\n\n")
+		for i, issue := range issues {
+			budget.item(maxBody, func(w itemWriter, maxBody int) {
+				writeHeading(w, "###", "Issue", i+1, issue.Title)
+				writeField(w, "Repository", RepoLabel(opts.RepoAliases, issue.Repository.NameWithOwner))
+				writeField(w, "Author", issue.Author.DisplayLogin())
+				if issue.Assigned {
+					w.WriteString("- Role: assignee (resolved someone else's issue)\n")
+				}
+				writeField(w, "State", issue.State)
+				writeField(w, "Created", daterange.FormatDate(issue.CreatedAt, opts.DateFormat))
+
+				if issue.ClosedAt != nil {
+					writeField(w, "Closed", daterange.FormatDate(*issue.ClosedAt, opts.DateFormat))
+				}
+
+				if issue.Body != "" && maxBody > 0 {
+					writeField(w, "Description", truncateRunes(issue.Body, maxBody))
+				}
+
+				w.WriteString("\n")
+			})
+		}
+	}
+
+	// Format Reviews
+	if opts.IncludeReviews && len(reviews) > 0 {
+		heading := "## Code Reviews Given"
+		if reviewOutcomes != "" {
+			heading += " (" + reviewOutcomes + ")"
+		}
+		budget.write(heading + sampleNote(len(reviews), totalReviews) + "\n\n")
+		for i, r := range reviews {
+			budget.item(maxBody, func(w itemWriter, maxBody int) {
+				writeHeading(w, "###", "Review", i+1, r.Title)
+				writeField(w, "Repository", RepoLabel(opts.RepoAliases, r.Repository.NameWithOwner))
+				writeField(w, "PR Author", r.Author.DisplayLogin())
+				if r.Member != "" {
+					writeField(w, "Reviewer", "@"+r.Member)
+				}
+				writeField(w, "State", r.State)
+				writeField(w, "Created", daterange.FormatDate(r.CreatedAt, opts.DateFormat))
+				if len(r.ReviewExcerpts) > 0 && maxBody > 0 {
+					w.WriteString("- My review comments:\n")
+					writeExcerpts(w, r.ReviewExcerpts)
+				}
+				w.WriteString("\n")
+			})
+		}
+	}
+
+	// Format Commits
+	if opts.IncludeCommits && len(commits) > 0 {
+		budget.write("## Commits" + sampleNote(len(commits), totalCommits) + "\n\n")
+		for _, c := range commits {
+			budget.item(maxBody, func(w itemWriter, maxBody int) {
+				msg, body, _ := strings.Cut(c.Commit.Message, "\n")
+				w.WriteString("- ")
+				w.WriteString(c.SHA[:min(7, len(c.SHA))])
+				w.WriteString(" ")
+				w.WriteString(truncateRunes(msg, 100))
+				w.WriteString(" (")
+				w.WriteString(RepoLabel(opts.RepoAliases, c.Repository.FullName))
+				w.WriteString(", ")
+				w.WriteString(daterange.FormatDate(c.Commit.Author.Date, opts.DateFormat))
+				w.WriteString(byMember(c.Member))
+				w.WriteString(")\n")
+				if body = strings.TrimSpace(body); opts.FullCommitMessages && body != "" && maxBody > 0 {
+					for _, line := range strings.Split(truncateRunes(body, maxBody), "\n") {
+						// Indented, with blank lines left empty
+						if line = strings.TrimRight(line, " "); line != "" {
+							w.WriteString("  ")
+							w.WriteString(line)
+						}
+						w.WriteString("\n")
+					}
+				}
+			})
+		}
+		budget.write("\n")
+	}
+
+	// Format Commented Items
+	if opts.IncludeComments && len(commentedItems) > 0 {
+		budget.write("## Items Commented On" + sampleNote(len(commentedItems), totalCommented) + "\n\n")
+		for _, item := range commentedItems {
+			budget.item(maxBody, func(w itemWriter, maxBody int) {
+				kind := "Issue"
+				if item.IsPR {
+					kind = "PR"
+				}
+				w.WriteString("- [")
+				w.WriteString(kind)
+				w.WriteString("] ")
+				w.WriteString(item.Title)
+				w.WriteString(" (")
+				w.WriteString(RepoLabel(opts.RepoAliases, item.Repository.NameWithOwner))
+				w.WriteString(", ")
+				w.WriteString(strconv.Itoa(item.Comments))
+				w.WriteString(" comments")
+				w.WriteString(byMember(item.Member))
+				w.WriteString(")\n")
+				if maxBody == 0 {
+					return
+				}
+				writeExcerpts(w, item.CommentExcerpts)
+			})
+		}
+		budget.write("\n")
+	}
+
+	// Format Discussions
+	if opts.IncludeDiscussions && len(discussions) > 0 {
+		budget.write("## Discussions Started" + sampleNote(len(discussions), totalDiscussions) + "\n\n")
+		for i, d := range discussions {
+			budget.item(maxBody, func(w itemWriter, maxBody int) {
+				writeHeading(w, "###", "Discussion", i+1, d.Title)
+				writeField(w, "Repository", RepoLabel(opts.RepoAliases, d.Repository.NameWithOwner))
+				if d.Member != "" {
+					writeField(w, "Author", "@"+d.Member)
+				}
+				if d.Category != "" {
+					writeField(w, "Category", d.Category)
+				}
+				writeField(w, "Comments", strconv.Itoa(d.Comments))
+				if d.Answered {
+					w.WriteString("- Answered: yes\n")
+				}
+				writeField(w, "Created", daterange.FormatDate(d.CreatedAt, opts.DateFormat))
+				if d.Body != "" && maxBody > 0 {
+					writeField(w, "Description", truncateRunes(d.Body, maxBody))
+				}
+				w.WriteString("\n")
+			})
+		}
+	}
+
+	sb.WriteString(budget.note())
+
+	return sb.String()
+}
@@ -0,0 +1,240 @@
+package github
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testActivity holds one item of each type, all in acme/web
+type testActivity struct {
+	prs            []PullRequest
+	issues         []Issue
+	reviews        []Review
+	commits        []Commit
+	commentedItems []CommentedItem
+	discussions    []Discussion
+}
+
+func newTestActivity() testActivity {
+	day := func(d int) time.Time { return time.Date(2025, time.March, d, 10, 0, 0, 0, time.UTC) }
+	closed := day(5)
+	repo := Repository{Name: "web", NameWithOwner: "acme/web"}
+	return testActivity{
+		prs:            []PullRequest{{Number: 1, Title: "Add caching", State: "merged", Body: "Caches pages.", CreatedAt: day(3), ClosedAt: &closed, Repository: repo}},
+		issues:         []Issue{{Number: 2, Title: "Slow page", State: "closed", CreatedAt: day(3), ClosedAt: &closed, Repository: repo}},
+		reviews:        []Review{{Number: 3, Title: "Fix login", State: "merged", CreatedAt: day(4), Repository: repo}},
+		commits:        []Commit{{SHA: "abc1234def", Commit: CommitDetail{Message: "Fix typo", Author: CommitAuthor{Date: day(6)}}, Repository: CommitRepository{FullName: "acme/web"}}},
+		commentedItems: []CommentedItem{{Number: 4, Title: "Flaky test", Comments: 2, UpdatedAt: day(7), Repository: repo}},
+		discussions:    []Discussion{{Number: 5, Title: "Roadmap", CreatedAt: day(8), Repository: repo}},
+	}
+}
+
+// format formats the activity with opts
+func (a testActivity) format(opts FormatOptions) string {
+	return FormatActivityForClaude(a.prs, a.issues, a.reviews, a.commits, a.commentedItems, a.discussions, "", opts)
+}
+
+func TestFormatSectionCombinations(t *testing.T) {
+	sections := []struct {
+		name    string
+		include func(*FormatOptions)
+		header  string
+		total   string
+	}{
+		{"prs", func(o *FormatOptions) { o.IncludePRs = true }, "## Pull Requests", "Total PRs:"},
+		{"issues", func(o *FormatOptions) { o.IncludeIssues = true }, "## Closed Issues", "Total Closed Issues:"},
+		{"reviews", func(o *FormatOptions) { o.IncludeReviews = true }, "## Code Reviews Given", "Total Reviews Given:"},
+		{"commits", func(o *FormatOptions) { o.IncludeCommits = true }, "## Commits", "Total Commits:"},
+		{"comments", func(o *FormatOptions) { o.IncludeComments = true }, "## Items Commented On", "Total Items Commented On:"},
+		{"discussions", func(o *FormatOptions) { o.IncludeDiscussions = true }, "## Discussions Started", "Total Discussions Started:"},
+	}
+	activity := newTestActivity()
+
+	// Every subset of the sections, as a bit mask
+	for mask := 0; mask < 1<<len(sections); mask++ {
+		var opts FormatOptions
+		var included []string
+		for i, s := range sections {
+			if mask&(1<<i) != 0 {
+				s.include(&opts)
+				included = append(included, s.name)
+			}
+		}
+		out := activity.format(opts)
+
+		for i, s := range sections {
+			want := mask&(1<<i) != 0
+			if got := strings.Contains(out, s.header); got != want {
+				t.Errorf("including %v: %q header present = %v, want %v", included, s.header, got, want)
+			}
+			if got := strings.Contains(out, s.total); got != want {
+				t.Errorf("including %v: %q present = %v, want %v", included, s.total, got, want)
+			}
+		}
+	}
+}
+
+func TestIncludeOnly(t *testing.T) {
+	opts := DefaultFormatOptions().IncludeOnly([]string{"prs", "commits", "unknown"})
+	if !opts.IncludePRs || !opts.IncludeCommits {
+		t.Errorf("IncludeOnly dropped a named section: %+v", opts)
+	}
+	if opts.IncludeIssues || opts.IncludeReviews || opts.IncludeComments || opts.IncludeDiscussions {
+		t.Errorf("IncludeOnly kept an unnamed section: %+v", opts)
+	}
+}
+
+func TestFormatFullCommitMessages(t *testing.T) {
+	activity := newTestActivity()
+	activity.commits[0].Commit.Message = "Rework caching (#12)\n\nMoves the page cache into Redis.\n\nAlso drops the old TTL setting."
+	opts := FormatOptions{IncludeCommits: true, MaxBodyLength: DefaultMaxBodyLength}
+
+	out := activity.format(opts)
+	if !strings.Contains(out, "- abc1234 Rework caching (#12) (acme/web,") {
+		t.Errorf("subject line missing:\n%s", out)
+	}
+	if strings.Contains(out, "Redis") || strings.Contains(out, "TTL") {
+		t.Errorf("body included without FullCommitMessages:\n%s", out)
+	}
+
+	opts.FullCommitMessages = true
+	out = activity.format(opts)
+	want := "  Moves the page cache into Redis.\n\n  Also drops the old TTL setting.\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("full body missing or not indented, want %q in:\n%s", want, out)
+	}
+
+	// The body is truncated to MaxBodyLength runes
+	opts.MaxBodyLength = 10
+	out = activity.format(opts)
+	if strings.Contains(out, "Redis") || !strings.Contains(out, "  Moves the") {
+		t.Errorf("body not truncated to 10 runes:\n%s", out)
+	}
+}
+
+func TestFormatMaxItemsPerType(t *testing.T) {
+	activity := newTestActivity()
+	for d := 10; d <= 13; d++ {
+		activity.prs = append(activity.prs, PullRequest{Number: d, Title: fmt.Sprintf("PR from the %dth", d), State: "open",
+			CreatedAt: time.Date(2025, time.March, d, 10, 0, 0, 0, time.UTC), Repository: activity.prs[0].Repository})
+	}
+	opts := DefaultFormatOptions()
+	opts.MaxItemsPerType = 2
+
+	out := activity.format(opts)
+	if !strings.Contains(out, "## Pull Requests (showing 2 of 5)") {
+		t.Errorf("output is missing the PR sample note:\n%s", out)
+	}
+	if n := strings.Count(out, "### PR #"); n != 2 {
+		t.Errorf("output has %d PRs, want 2", n)
+	}
+	for _, title := range []string{"PR from the 13th", "PR from the 12th"} {
+		if !strings.Contains(out, title) {
+			t.Errorf("output is missing the recent %q", title)
+		}
+	}
+	if strings.Contains(out, "Add caching") {
+		t.Error("output has the oldest PR past the cap")
+	}
+	// Sections under the cap have no note
+	if !strings.Contains(out, "## Closed Issues\n") {
+		t.Errorf("the uncapped issues section has a note:\n%s", out)
+	}
+}
+
+func TestFormatDateFormat(t *testing.T) {
+	tests := []struct {
+		layout string
+		want   []string
+		absent string
+	}{
+		{"", []string{"- Created: 2025-03-03", "- Closed: 2025-03-05", "2025-03-06", "- Created: 2025-03-08"}, "Mar 2025"},
+		{"02 Jan 2006", []string{"- Created: 03 Mar 2025", "- Closed: 05 Mar 2025", "06 Mar 2025", "- Created: 08 Mar 2025"}, "2025-03"},
+	}
+	a := newTestActivity()
+	for _, tt := range tests {
+		opts := DefaultFormatOptions()
+		opts.DateFormat = tt.layout
+		got := a.format(opts)
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("with layout %q, missing %q:\n%s", tt.layout, want, got)
+			}
+		}
+		if strings.Contains(got, tt.absent) {
+			t.Errorf("with layout %q, has %q:\n%s", tt.layout, tt.absent, got)
+		}
+	}
+}
+
+func TestFormatStableUnderShuffle(t *testing.T) {
+	same := time.Date(2025, time.March, 3, 10, 0, 0, 0, time.UTC)
+	web, api := Repository{Name: "web", NameWithOwner: "acme/web"}, Repository{Name: "api", NameWithOwner: "acme/api"}
+	activity := newTestActivity()
+	for i, repo := range []Repository{web, api, web, api} {
+		activity.prs = append(activity.prs, PullRequest{Number: 10 + i, Title: fmt.Sprintf("PR %d", i), State: "open", CreatedAt: same, Repository: repo})
+		activity.issues = append(activity.issues, Issue{Number: 20 + i, Title: fmt.Sprintf("Issue %d", i), State: "closed", CreatedAt: same, Repository: repo})
+		activity.reviews = append(activity.reviews, Review{Number: 30 + i, Title: fmt.Sprintf("Review %d", i), State: "open", CreatedAt: same, Repository: repo})
+		activity.commits = append(activity.commits, Commit{SHA: fmt.Sprintf("%040d", i), Commit: CommitDetail{Message: "Commit", Author: CommitAuthor{Date: same}},
+			Repository: CommitRepository{FullName: repo.NameWithOwner}})
+		activity.commentedItems = append(activity.commentedItems, CommentedItem{Number: 40 + i, Title: "Commented", UpdatedAt: same, Repository: repo})
+	}
+	focused := DefaultFormatOptions()
+	focused.FocusRepos = []string{"acme/api"}
+	for _, opts := range []FormatOptions{DefaultFormatOptions(), focused} {
+		want := activity.format(opts)
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < 5; i++ {
+			shuffle(rng, activity.prs)
+			shuffle(rng, activity.issues)
+			shuffle(rng, activity.reviews)
+			shuffle(rng, activity.commits)
+			shuffle(rng, activity.commentedItems)
+			if got := activity.format(opts); got != want {
+				t.Fatalf("shuffle %d changed the output (focus repos %v):\n%s\nwant\n%s", i+1, opts.FocusRepos, got, want)
+			}
+		}
+	}
+}
+
+// shuffle reorders items in place
+func shuffle[T any](rng *rand.Rand, items []T) {
+	rng.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+}
+
+func TestFormatByLabel(t *testing.T) {
+	a := newTestActivity()
+	fix, docs, other := a.prs[0], a.prs[0], a.prs[0]
+	fix.Title, fix.Labels = "Fix crash", []Label{{Name: "BUG"}}
+	docs.Title, docs.Labels = "Update guide", []Label{{Name: "docs"}}
+	other.Title = "Tidy up"
+	a.prs = []PullRequest{other, docs, fix}
+	sections := []LabelSection{
+		{Heading: "Bug Fixes", Labels: []string{"bug", "bugfix"}},
+		{Heading: "Documentation", Labels: []string{"docs"}},
+	}
+
+	got := FormatActivityByLabel(a.prs, 0, FormatOptions{LabelSections: sections})
+	want := []string{"### Bug Fixes (1)", "PR #1: Fix crash", "- Labels: BUG", "### Documentation (1)", "PR #2: Update guide", "### Other (1)", "PR #3: Tidy up"}
+	last := -1
+	for _, s := range want {
+		i := strings.Index(got, s)
+		if i < last {
+			t.Fatalf("%q is missing or out of order in:\n%s", s, got)
+		}
+		last = i
+	}
+
+	// Labels are only listed when PRs are grouped by them
+	opts := DefaultFormatOptions().IncludeOnly([]string{"prs"})
+	if out := a.format(opts); strings.Contains(out, "- Labels:") {
+		t.Errorf("ungrouped PRs list their labels:\n%s", out)
+	}
+	opts.GroupByLabel, opts.LabelSections = true, sections
+	if out := a.format(opts); !strings.Contains(out, "### Bug Fixes (1)") || !strings.Contains(out, "- Labels: BUG") {
+		t.Errorf("grouped PRs are missing the Bug Fixes heading or labels:\n%s", out)
+	}
+}
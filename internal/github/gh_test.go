@@ -0,0 +1,18 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeGH puts a gh on the PATH that runs script, a POSIX shell script
+// given gh's arguments, in place of the real one
+func fakeGH(t *testing.T, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "gh"), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
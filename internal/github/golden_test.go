@@ -0,0 +1,215 @@
+package github
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// updateGolden rewrites the golden files from the current output, e.g. with
+// go test ./internal/github -run Golden -update
+var updateGolden = flag.Bool("update", false, "rewrite the golden files")
+
+// largeActivity returns n of each kind of activity across a few repos, with
+// every optional field set on some items
+func largeActivity(n int) testActivity {
+	start := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+	at := func(i int) time.Time { return start.Add(time.Duration(i) * 37 * time.Minute) }
+	repos := []Repository{
+		{Name: "web", NameWithOwner: "acme/web"},
+		{Name: "api", NameWithOwner: "acme/api"},
+		{Name: "docs", NameWithOwner: "octocat/docs"},
+	}
+	members := []string{"", "", "alice"}
+	states := []string{"merged", "closed", "open"}
+	labels := [][]Label{nil, {{Name: "bug"}}, {{Name: "feature"}, {Name: "ui"}}, {{Name: "chore"}}}
+
+	var a testActivity
+	for i := range n {
+		repo, member := repos[i%len(repos)], members[i%len(members)]
+		closed := at(i + 90)
+		body := fmt.Sprintf("Change %d touches the cache — naïve eviction replaced. ", i) + strings.Repeat("More detail. ", i%7)
+
+		pr := PullRequest{
+			Number:     i + 1,
+			Title:      fmt.Sprintf("Improve caching, part %d", i+1),
+			State:      states[i%len(states)],
+			Body:       body,
+			CreatedAt:  at(i),
+			Author:     Author{Login: "octocat"},
+			Repository: repo,
+			Labels:     labels[i%len(labels)],
+			Member:     member,
+		}
+		if pr.State != "open" {
+			pr.ClosedAt = &closed
+		}
+		if i%4 == 0 {
+			pr.ReviewRequests = []ReviewRequest{{Login: "hubot"}, {Login: "monalisa"}}
+			pr.LinkedIssues = []LinkedIssue{{Repo: repo.NameWithOwner, Number: 1000 + i, Title: "Slow pages"}}
+			pr.Additions, pr.Deletions = 10*i, 3*i
+		}
+		a.prs = append(a.prs, pr)
+
+		a.issues = append(a.issues, Issue{
+			Number:     2000 + i,
+			Title:      fmt.Sprintf("Page %d is slow", i),
+			State:      "closed",
+			Body:       body,
+			CreatedAt:  at(i),
+			ClosedAt:   &closed,
+			Author:     Author{Login: []string{"octocat", "", "hubot"}[i%3]},
+			Repository: repo,
+			Assigned:   i%5 == 0,
+			Member:     member,
+		})
+
+		review := Review{
+			Number:     3000 + i,
+			Title:      fmt.Sprintf("Fix login flow %d", i),
+			State:      states[i%len(states)],
+			Author:     Author{Login: "hubot"},
+			Repository: repo,
+			CreatedAt:  at(i),
+			Member:     member,
+		}
+		if i%3 == 1 {
+			review.ReviewExcerpts = []string{"auth.go: Check the error here.", fmt.Sprintf("Looks good after round %d.", i)}
+		}
+		a.reviews = append(a.reviews, review)
+
+		message := fmt.Sprintf("Tune cache size %d", i)
+		if i%2 == 0 {
+			message += "\n\nThe old limit evicted hot pages.  \n\nMeasured on staging:\n- p50 down 12%\n- p99 down 30%"
+		}
+		a.commits = append(a.commits, Commit{
+			SHA:        fmt.Sprintf("%040x", 0xabc123+i),
+			Commit:     CommitDetail{Message: message, Author: CommitAuthor{Date: at(i)}},
+			Repository: CommitRepository{FullName: repo.NameWithOwner},
+			Member:     member,
+		})
+
+		item := CommentedItem{
+			Number:     4000 + i,
+			Title:      fmt.Sprintf("Flaky test %d", i),
+			Repository: repo,
+			Comments:   i%9 + 1,
+			UpdatedAt:  at(i),
+			IsPR:       i%2 == 0,
+			Member:     member,
+		}
+		if i%3 == 2 {
+			item.CommentExcerpts = []string{"Retrying fixed it locally.", "Still flaky on CI."}
+		}
+		a.commentedItems = append(a.commentedItems, item)
+
+		a.discussions = append(a.discussions, Discussion{
+			Number:     5000 + i,
+			Title:      fmt.Sprintf("Roadmap idea %d", i),
+			Body:       body,
+			Category:   []string{"Ideas", "", "Q&A"}[i%3],
+			Repository: repo,
+			Comments:   i % 4,
+			CreatedAt:  at(i),
+			Answered:   i%6 == 2,
+			Member:     member,
+		})
+	}
+	return a
+}
+
+// goldenOptions are the option sets the golden output covers, by name
+func goldenOptions() []struct {
+	name string
+	opts FormatOptions
+} {
+	full := DefaultFormatOptions()
+	full.FullCommitMessages = true
+	full.FocusRepos = []string{"acme/api"}
+	full.Highlights = []string{"Shipped the cache"}
+	full.Preamble = "I lead the web team."
+
+	byLabel := DefaultFormatOptions()
+	byLabel.GroupByLabel = true
+	byLabel.LabelSections = []LabelSection{
+		{Heading: "Bugs", Labels: []string{"bug"}},
+		{Heading: "Features", Labels: []string{"feature"}},
+	}
+
+	sampled := DefaultFormatOptions()
+	sampled.MaxItemsPerType = 5
+	sampled.OldestFirst = true
+
+	budget := DefaultFormatOptions()
+	budget.MaxPromptChars = 6000
+
+	noBodies := DefaultFormatOptions()
+	noBodies.MaxBodyLength = 0
+
+	return []struct {
+		name string
+		opts FormatOptions
+	}{
+		{"default", DefaultFormatOptions()},
+		{"full", full},
+		{"by label", byLabel},
+		{"sampled", sampled},
+		{"budget", budget},
+		{"no bodies", noBodies},
+	}
+}
+
+// TestFormatGolden checks formatting is unchanged byte for byte against
+// testdata/format.golden
+func TestFormatGolden(t *testing.T) {
+	activity := largeActivity(40)
+	var out strings.Builder
+	for _, g := range goldenOptions() {
+		fmt.Fprintf(&out, "=== %s ===\n", g.name)
+		out.WriteString(FormatActivityForClaude(activity.prs, activity.issues, activity.reviews, activity.commits, activity.commentedItems, activity.discussions, "PRs: 40 opened", g.opts))
+	}
+
+	path := filepath.Join("testdata", "format.golden")
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(out.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != string(want) {
+		gotLines, wantLines := strings.Split(got, "\n"), strings.Split(string(want), "\n")
+		for i := range min(len(gotLines), len(wantLines)) {
+			if gotLines[i] != wantLines[i] {
+				t.Fatalf("output differs from %s at line %d:\ngot:  %q\nwant: %q", path, i+1, gotLines[i], wantLines[i])
+			}
+		}
+		t.Fatalf("output has %d lines, %s has %d", len(gotLines), path, len(wantLines))
+	}
+}
+
+func BenchmarkFormatActivity(b *testing.B) {
+	activity := largeActivity(2000)
+	budget := DefaultFormatOptions()
+	budget.MaxPromptChars = 200000
+	for _, bb := range []struct {
+		name string
+		opts FormatOptions
+	}{
+		{"unlimited", DefaultFormatOptions()},
+		{"budget", budget},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				activity.format(bb.opts)
+			}
+		})
+	}
+}
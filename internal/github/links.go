@@ -0,0 +1,92 @@
+package github
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"strconv"
+)
+
+// closingRefPattern matches GitHub's closing keywords followed by an issue
+// reference, e.g. "Fixes #12" or "closes acme/web#34"
+var closingRefPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+(?:([\w.-]+/[\w.-]+))?#(\d+)\b`)
+
+// ParseLinkedIssues returns the issues a PR body says it closes, in order
+// and without repeats. References without a repository are in repo.
+func ParseLinkedIssues(body, repo string) []LinkedIssue {
+	var linked []LinkedIssue
+	seen := make(map[LinkedIssue]bool)
+	for _, match := range closingRefPattern.FindAllStringSubmatch(body, -1) {
+		number, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+		ref := LinkedIssue{Repo: repo, Number: number}
+		if match[1] != "" {
+			ref.Repo = match[1]
+		}
+		if !seen[ref] {
+			seen[ref] = true
+			linked = append(linked, ref)
+		}
+	}
+	return linked
+}
+
+// ResolveLinkedIssues sets LinkedIssues on each PR to the issues its body
+// closes, with titles looked up with gh issue view, running at most
+// concurrency lookups at a time. Each issue is looked up once however many
+// PRs reference it. PRs are updated in place; issues that couldn't be looked
+// up are kept without a title, and the first lookup error is returned after
+// all lookups finish.
+func ResolveLinkedIssues(ctx context.Context, prs []PullRequest, concurrency int) error {
+	var refs []LinkedIssue
+	seen := make(map[LinkedIssue]bool)
+	for i := range prs {
+		prs[i].LinkedIssues = ParseLinkedIssues(prs[i].Body, prs[i].Repository.NameWithOwner)
+		for _, ref := range prs[i].LinkedIssues {
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	err := lookupAll(refs, concurrency, func(ref *LinkedIssue) error {
+		title, err := fetchIssueTitle(ctx, *ref)
+		ref.Title = title
+		return err
+	})
+
+	titles := make(map[LinkedIssue]string, len(refs))
+	for _, ref := range refs {
+		title := ref.Title
+		ref.Title = ""
+		titles[ref] = title
+	}
+	for i := range prs {
+		for j, ref := range prs[i].LinkedIssues {
+			prs[i].LinkedIssues[j].Title = titles[ref]
+		}
+	}
+	return err
+}
+
+// fetchIssueTitle looks up the title of a single issue
+func fetchIssueTitle(ctx context.Context, ref LinkedIssue) (string, error) {
+	output, err := exec.CommandContext(ctx, "gh", "issue", "view", strconv.Itoa(ref.Number),
+		"--repo", ref.Repo,
+		"--json", "title",
+	).Output()
+	if err != nil {
+		return "", ghError(err)
+	}
+
+	var view struct {
+		Title string `json:"title"`
+	}
+	if err := parseJSON("issue data for "+ref.String(), output, &view); err != nil {
+		return "", err
+	}
+	return view.Title, nil
+}
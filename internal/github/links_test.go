@@ -0,0 +1,68 @@
+package github
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseLinkedIssues(t *testing.T) {
+	tests := []struct {
+		body string
+		want []LinkedIssue
+	}{
+		{"Fixes #12", []LinkedIssue{{Repo: "acme/web", Number: 12}}},
+		{"closes acme/api#34 and resolves #5", []LinkedIssue{{Repo: "acme/api", Number: 34}, {Repo: "acme/web", Number: 5}}},
+		{"Resolved: #7\nFIXED #7 again", []LinkedIssue{{Repo: "acme/web", Number: 7}}},
+		{"Related to #9, see acme/api#10", nil},
+		{"prefixes #3 and unfixes #4", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseLinkedIssues(tt.body, "acme/web"); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseLinkedIssues(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestFormatLinkedIssues(t *testing.T) {
+	activity := newTestActivity()
+	activity.prs[0].Body = "Fixes #2 and closes acme/api#8"
+	opts := FormatOptions{IncludePRs: true}
+
+	out := activity.format(opts)
+	for _, want := range []string{"Resolves: acme/web#2", "Resolves: acme/api#8"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("formatted PR is missing %q:\n%s", want, out)
+		}
+	}
+
+	activity.prs[0].LinkedIssues = []LinkedIssue{{Repo: "acme/web", Number: 2, Title: "Slow page"}}
+	if out := activity.format(opts); !strings.Contains(out, "Resolves: Slow page (acme/web#2)") {
+		t.Errorf("formatted PR is missing the resolved title:\n%s", out)
+	}
+}
+
+func TestResolveLinkedIssues(t *testing.T) {
+	// gh issue view N --repo R --json title
+	fakeGH(t, `echo "{\"title\":\"Issue $3 in $5\"}"`)
+	prs := []PullRequest{
+		{Number: 1, Body: "Fixes #2", Repository: Repository{NameWithOwner: "acme/web"}},
+		{Number: 3, Body: "closes acme/api#8, fixes acme/web#2", Repository: Repository{NameWithOwner: "acme/web"}},
+		{Number: 4, Body: "No references"},
+	}
+
+	if err := ResolveLinkedIssues(context.Background(), prs, 4); err != nil {
+		t.Fatalf("ResolveLinkedIssues: %v", err)
+	}
+	want := [][]LinkedIssue{
+		{{Repo: "acme/web", Number: 2, Title: "Issue 2 in acme/web"}},
+		{{Repo: "acme/api", Number: 8, Title: "Issue 8 in acme/api"}, {Repo: "acme/web", Number: 2, Title: "Issue 2 in acme/web"}},
+		nil,
+	}
+	for i, pr := range prs {
+		if !slices.Equal(pr.LinkedIssues, want[i]) {
+			t.Errorf("PR %d LinkedIssues = %v, want %v", pr.Number, pr.LinkedIssues, want[i])
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package github
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -17,19 +18,58 @@ type PullRequest struct {
 	Repository Repository `json:"repository"`
 	// ReviewRequests contains users who were requested to review
 	ReviewRequests []ReviewRequest `json:"reviewRequests"`
-}
-
-// Author represents a GitHub user
+	// LinkedIssues are the issues the body says it closes, only set when
+	// FetchOptions.ResolveLinkedIssues is
+	LinkedIssues []LinkedIssue `json:"linkedIssues,omitempty"`
+	Comments     int           `json:"commentsCount"`
+	Labels       []Label       `json:"labels,omitempty"`
+	// Additions and Deletions are only set when FetchOptions.ResolvePRSizes is
+	Additions int `json:"additions,omitempty"`
+	Deletions int `json:"deletions,omitempty"`
+	// Member is the team member the item was fetched for, only set when
+	// fetching for FetchOptions.Members
+	Member string `json:"member,omitempty"`
+}
+
+// LinkedIssue is an issue a PR closes, with its title if it was looked up
+type LinkedIssue struct {
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	Title  string `json:"title,omitempty"`
+}
+
+// String returns the reference as "owner/repo#N"
+func (l LinkedIssue) String() string {
+	return fmt.Sprintf("%s#%d", l.Repo, l.Number)
+}
+
+// Author represents a GitHub user. A null author, as gh returns for some
+// automation-created items, decodes to the zero Author; the item still
+// counts everywhere, only its login is empty.
 type Author struct {
 	Login string `json:"login"`
 }
 
+// DisplayLogin returns the login prefixed with "@", or "(unknown)" for
+// deleted (ghost) accounts and null authors, which have an empty login
+func (a Author) DisplayLogin() string {
+	if a.Login == "" {
+		return "(unknown)"
+	}
+	return "@" + a.Login
+}
+
 // Repository represents a GitHub repository
 type Repository struct {
 	Name          string `json:"name"`
 	NameWithOwner string `json:"nameWithOwner"`
 }
 
+// Label is a label applied to a PR
+type Label struct {
+	Name string `json:"name"`
+}
+
 // ReviewRequest represents a review request
 type ReviewRequest struct {
 	Login string `json:"login"`
@@ -63,6 +103,20 @@ func (pr PullRequest) MergeTime() *time.Time {
 	return pr.ClosedAt
 }
 
+// LinesChanged returns the lines added plus the lines deleted
+func (pr PullRequest) LinesChanged() int {
+	return pr.Additions + pr.Deletions
+}
+
+// LabelNames returns the names of the PR's labels
+func (pr PullRequest) LabelNames() []string {
+	names := make([]string, len(pr.Labels))
+	for i, l := range pr.Labels {
+		names[i] = l.Name
+	}
+	return names
+}
+
 // Reviewers returns a list of reviewer logins
 func (pr PullRequest) Reviewers() []string {
 	reviewers := make([]string, len(pr.ReviewRequests))
@@ -82,6 +136,11 @@ type Issue struct {
 	ClosedAt   *time.Time `json:"closedAt,omitempty"`
 	Author     Author     `json:"author"`
 	Repository Repository `json:"repository"`
+	// Assigned is set for issues closed as the assignee rather than the author
+	Assigned bool `json:"assigned,omitempty"`
+	// Member is the team member the item was fetched for, only set when
+	// fetching for FetchOptions.Members
+	Member string `json:"member,omitempty"`
 }
 
 // IsOpen returns true if the issue is open
@@ -103,6 +162,12 @@ type Review struct {
 	Repository Repository `json:"repository"`
 	CreatedAt  time.Time  `json:"createdAt"`
 	ClosedAt   *time.Time `json:"closedAt,omitempty"`
+	// ReviewExcerpts holds the reviewer's own most recent review bodies and
+	// inline comments, only fetched when FetchOptions.ReviewExcerpts is set
+	ReviewExcerpts []string `json:"reviewExcerpts,omitempty"`
+	// Member is the team member the item was fetched for, only set when
+	// fetching for FetchOptions.Members
+	Member string `json:"member,omitempty"`
 }
 
 // Commit represents a commit from gh search commits
@@ -110,6 +175,18 @@ type Commit struct {
 	SHA        string           `json:"sha"`
 	Commit     CommitDetail     `json:"commit"`
 	Repository CommitRepository `json:"repository"`
+	// Stats is only set when FetchOptions.ResolveCommitStats is, and stays
+	// nil for commits whose repository couldn't be read
+	Stats *CommitStats `json:"stats,omitempty"`
+	// Member is the team member the item was fetched for, only set when
+	// fetching for FetchOptions.Members
+	Member string `json:"member,omitempty"`
+}
+
+// CommitStats holds the lines a commit added and deleted
+type CommitStats struct {
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
 }
 
 // CommitDetail contains the commit message and author info
@@ -129,6 +206,23 @@ type CommitRepository struct {
 	Owner    Author `json:"owner"`
 }
 
+// Discussion represents a GitHub Discussion the user started
+type Discussion struct {
+	Number     int        `json:"number"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body"`
+	Category   string     `json:"category"`
+	Author     Author     `json:"author"`
+	Repository Repository `json:"repository"`
+	Comments   int        `json:"commentsCount"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	// Answered is set when a comment has been marked as the answer
+	Answered bool `json:"isAnswered"`
+	// Member is the team member the item was fetched for, only set when
+	// fetching for FetchOptions.Members
+	Member string `json:"member,omitempty"`
+}
+
 // CommentedItem represents a PR or issue the user commented on
 type CommentedItem struct {
 	Number     int        `json:"number"`
@@ -137,5 +231,12 @@ type CommentedItem struct {
 	Author     Author     `json:"author"`
 	Repository Repository `json:"repository"`
 	Comments   int        `json:"commentsCount"`
-	IsPR       bool       // set programmatically, not from JSON
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	IsPR       bool       `json:"isPR"` // set programmatically, not returned by gh
+	// CommentExcerpts holds the user's own most recent comments, only
+	// fetched when FetchOptions.CommentExcerpts is set
+	CommentExcerpts []string `json:"commentExcerpts,omitempty"`
+	// Member is the team member the item was fetched for, only set when
+	// fetching for FetchOptions.Members
+	Member string `json:"member,omitempty"`
 }
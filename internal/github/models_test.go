@@ -0,0 +1,52 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDisplayLogin(t *testing.T) {
+	if got := (Author{Login: "octocat"}).DisplayLogin(); got != "@octocat" {
+		t.Errorf("DisplayLogin() = %q, want %q", got, "@octocat")
+	}
+	if got := (Author{}).DisplayLogin(); got != "(unknown)" {
+		t.Errorf("DisplayLogin() of a ghost author = %q, want %q", got, "(unknown)")
+	}
+}
+
+func TestNullAuthor(t *testing.T) {
+	fakeGH(t, `echo '[{"number":1,"title":"Bump deps","state":"open","author":null,"repository":{"name":"web","nameWithOwner":"acme/web"}},{"number":2,"title":"Add caching","state":"open","author":{"login":"octocat"},"repository":{"name":"web","nameWithOwner":"acme/web"}}]'`)
+
+	prs, err := FetchPRs(context.Background(), testRange(), FetchOptions{})
+	if err != nil {
+		t.Fatalf("FetchPRs: %v", err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("got %d PRs, want 2", len(prs))
+	}
+	if got := prs[0].Author.DisplayLogin(); got != "(unknown)" {
+		t.Errorf("DisplayLogin() of a null author = %q, want %q", got, "(unknown)")
+	}
+	if prs[1].Author.Login != "octocat" {
+		t.Errorf("Login = %q, want %q", prs[1].Author.Login, "octocat")
+	}
+}
+
+func TestFormatGhostAuthor(t *testing.T) {
+	repo := Repository{Name: "web", NameWithOwner: "acme/web"}
+	created := time.Date(2025, time.March, 3, 0, 0, 0, 0, time.UTC)
+	prs := []PullRequest{{Number: 1, Title: "Fix login", State: "open", CreatedAt: created, Repository: repo}}
+	issues := []Issue{{Number: 2, Title: "Crash", State: "closed", CreatedAt: created, Repository: repo}}
+	reviews := []Review{{Number: 3, Title: "Add cache", State: "open", CreatedAt: created, Repository: repo}}
+
+	out := FormatActivityForClaude(prs, issues, reviews, nil, nil, nil, "", DefaultFormatOptions())
+
+	if n := strings.Count(out, "(unknown)"); n != 3 {
+		t.Errorf("output has %d (unknown) authors, want 3:\n%s", n, out)
+	}
+	if strings.Contains(out, "@\n") {
+		t.Errorf("output has a bare @ author:\n%s", out)
+	}
+}
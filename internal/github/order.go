@@ -0,0 +1,64 @@
+package github
+
+import (
+	"sort"
+	"time"
+)
+
+// itemOrder is the key activity is sorted on: time, then repository, then
+// number or SHA
+type itemOrder struct {
+	at     time.Time
+	repo   string
+	number int
+	sha    string
+}
+
+// less orders by time, newest first unless oldestFirst, breaking ties in
+// ascending repository, number, and SHA order
+func (a itemOrder) less(b itemOrder, oldestFirst bool) bool {
+	switch {
+	case !a.at.Equal(b.at):
+		return a.at.Before(b.at) == oldestFirst
+	case a.repo != b.repo:
+		return a.repo < b.repo
+	case a.number != b.number:
+		return a.number < b.number
+	}
+	return a.sha < b.sha
+}
+
+// sortedByOrder returns a copy of items sorted by key, so the order is the
+// same however concurrent fetches happened to return them
+func sortedByOrder[T any](items []T, key func(T) itemOrder, oldestFirst bool) []T {
+	sorted := append([]T(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return key(sorted[i]).less(key(sorted[j]), oldestFirst)
+	})
+	return sorted
+}
+
+// SortActivity sorts each type of activity in msg newest first by when it
+// happened: PRs, issues, reviews, and discussions by creation, commits by
+// author date, and commented items by their last update. With oldestFirst
+// the order is reversed.
+func SortActivity(msg *ActivityLoadedMsg, oldestFirst bool) {
+	msg.PRs = sortedByOrder(msg.PRs, func(pr PullRequest) itemOrder {
+		return itemOrder{pr.CreatedAt, pr.Repository.NameWithOwner, pr.Number, ""}
+	}, oldestFirst)
+	msg.Issues = sortedByOrder(msg.Issues, func(i Issue) itemOrder {
+		return itemOrder{i.CreatedAt, i.Repository.NameWithOwner, i.Number, ""}
+	}, oldestFirst)
+	msg.Reviews = sortedByOrder(msg.Reviews, func(r Review) itemOrder {
+		return itemOrder{r.CreatedAt, r.Repository.NameWithOwner, r.Number, ""}
+	}, oldestFirst)
+	msg.Commits = sortedByOrder(msg.Commits, func(c Commit) itemOrder {
+		return itemOrder{c.Commit.Author.Date, c.Repository.FullName, 0, c.SHA}
+	}, oldestFirst)
+	msg.CommentedItems = sortedByOrder(msg.CommentedItems, func(ci CommentedItem) itemOrder {
+		return itemOrder{ci.UpdatedAt, ci.Repository.NameWithOwner, ci.Number, ""}
+	}, oldestFirst)
+	msg.Discussions = sortedByOrder(msg.Discussions, func(d Discussion) itemOrder {
+		return itemOrder{d.CreatedAt, d.Repository.NameWithOwner, d.Number, ""}
+	}, oldestFirst)
+}
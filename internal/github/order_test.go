@@ -0,0 +1,43 @@
+package github
+
+import (
+	"slices"
+	"testing"
+)
+
+// orderScript is a fake gh returning PRs and commits out of date order
+const orderScript = `case "$*" in
+"search prs --author "*) echo '[{"number":1,"title":"Middle","state":"open","createdAt":"2025-03-10T10:00:00Z","repository":{"name":"web","nameWithOwner":"acme/web"}},{"number":2,"title":"Oldest","state":"open","createdAt":"2025-03-02T10:00:00Z","repository":{"name":"web","nameWithOwner":"acme/web"}},{"number":3,"title":"Newest","state":"open","createdAt":"2025-03-20T10:00:00Z","repository":{"name":"web","nameWithOwner":"acme/web"}}]' ;;
+"search commits "*) echo '[{"sha":"aaa","commit":{"message":"Oldest","author":{"date":"2025-03-03T10:00:00Z"}},"repository":{"fullName":"acme/web"}},{"sha":"bbb","commit":{"message":"Newest","author":{"date":"2025-03-25T10:00:00Z"}},"repository":{"fullName":"acme/web"}}]' ;;
+*) echo '[]' ;;
+esac`
+
+func TestFetchedActivityOrder(t *testing.T) {
+	fakeGH(t, orderScript)
+
+	tests := []struct {
+		oldestFirst bool
+		prs         []int
+		commits     []string
+	}{
+		{false, []int{3, 1, 2}, []string{"bbb", "aaa"}},
+		{true, []int{2, 1, 3}, []string{"aaa", "bbb"}},
+	}
+	for _, tt := range tests {
+		msg := FetchActivityCmd(testRange(), FetchOptions{Author: "alice", OldestFirst: tt.oldestFirst})().(ActivityLoadedMsg)
+		if msg.Error != nil {
+			t.Fatalf("FetchActivityCmd: %v", msg.Error)
+		}
+		var prs []int
+		for _, pr := range msg.PRs {
+			prs = append(prs, pr.Number)
+		}
+		var commits []string
+		for _, c := range msg.Commits {
+			commits = append(commits, c.SHA)
+		}
+		if !slices.Equal(prs, tt.prs) || !slices.Equal(commits, tt.commits) {
+			t.Errorf("oldestFirst %v: PRs %v, commits %v, want %v, %v", tt.oldestFirst, prs, commits, tt.prs, tt.commits)
+		}
+	}
+}
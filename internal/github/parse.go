@@ -0,0 +1,103 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// outputExcerptBytes is how much of the start and end of unparseable output
+// is quoted in errors
+const outputExcerptBytes = 200
+
+// secretPattern matches GitHub tokens and bearer credentials that must not
+// be echoed in errors
+var secretPattern = regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})\b|(?i)\bbearer\s+\S+`)
+
+// parseJSON decodes gh output for source, e.g. "PR data", into v. Anything
+// after the first JSON value, such as notices some gh versions print, is
+// ignored. An API error object in place of the results is reported by its
+// message. On other failures the error names the source and quotes the
+// start and end of the output.
+func parseJSON(source string, output []byte, v any) error {
+	if msg := errorObjectMessage(output); msg != "" {
+		return apiMessageError(source, msg)
+	}
+	if err := json.NewDecoder(bytes.NewReader(output)).Decode(v); err != nil {
+		return &ErrParse{Source: source, Err: err, excerpt: outputExcerpt(output)}
+	}
+	return nil
+}
+
+// outputExcerpt quotes output with secrets masked, keeping only the first
+// and last outputExcerptBytes bytes of long output
+func outputExcerpt(output []byte) string {
+	s := secretPattern.ReplaceAllString(string(output), "[redacted]")
+	if len(s) > 2*outputExcerptBytes {
+		s = s[:outputExcerptBytes] + " … " + s[len(s)-outputExcerptBytes:]
+	}
+	return strconv.Quote(s)
+}
+
+// apiError is the body of a GitHub API error, which gh can print in place of
+// the expected results, e.g. for a malformed query. GraphQL responses can
+// carry errors alongside partial data, so those are not treated as errors.
+type apiError struct {
+	Message string            `json:"message"`
+	Errors  []json.RawMessage `json:"errors"`
+	Data    json.RawMessage   `json:"data"`
+}
+
+// errorObjectMessage returns the message of an API error object at the start
+// of output, or "" if output is anything else
+func errorObjectMessage(output []byte) string {
+	trimmed := bytes.TrimSpace(output)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return ""
+	}
+	var e apiError
+	if err := json.NewDecoder(bytes.NewReader(trimmed)).Decode(&e); err != nil || e.Data != nil {
+		return ""
+	}
+
+	var messages []string
+	if e.Message != "" {
+		messages = append(messages, e.Message)
+	}
+	for _, raw := range e.Errors {
+		// Entries are usually objects with a message, but can be plain strings
+		var detail struct {
+			Message string `json:"message"`
+		}
+		var text string
+		if json.Unmarshal(raw, &detail) == nil && detail.Message != "" {
+			text = detail.Message
+		} else if json.Unmarshal(raw, &text) != nil {
+			continue
+		}
+		if text != "" {
+			messages = append(messages, text)
+		}
+	}
+	return secretPattern.ReplaceAllString(strings.Join(messages, "; "), "[redacted]")
+}
+
+// parseJSONValues decodes gh output for source holding a sequence of JSON
+// values, such as gh api --paginate --jq '.[]' prints for every element of
+// every page, into a slice. Failures are reported like parseJSON's.
+func parseJSONValues[T any](source string, output []byte) ([]T, error) {
+	var values []T
+	dec := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var v T
+		if err := dec.Decode(&v); err == io.EOF {
+			return values, nil
+		} else if err != nil {
+			return nil, &ErrParse{Source: source, Err: err, excerpt: outputExcerpt(output)}
+		}
+		values = append(values, v)
+	}
+}
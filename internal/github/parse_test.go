@@ -0,0 +1,88 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONTruncated(t *testing.T) {
+	output := []byte(`[{"number":1,"title":"Add caching"},{"number":2,"ti`)
+
+	var prs []PullRequest
+	err := parseJSON("PR data", output, &prs)
+	var parseErr *ErrParse
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("error = %v, want an *ErrParse", err)
+	}
+	if parseErr.Source != "PR data" {
+		t.Errorf("Source = %q, want %q", parseErr.Source, "PR data")
+	}
+	for _, want := range []string{"failed to parse PR data", `{\"number\":2,\"ti`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't contain %q", err, want)
+		}
+	}
+}
+
+func TestParseJSONTrailingGarbage(t *testing.T) {
+	output := []byte(`[{"number":1,"title":"Add caching"}]` + "\nA new release of gh is available: 2.40.0 → 2.62.0\n")
+
+	var prs []PullRequest
+	if err := parseJSON("PR data", output, &prs); err != nil {
+		t.Fatalf("parseJSON: %v", err)
+	}
+	if len(prs) != 1 || prs[0].Title != "Add caching" {
+		t.Errorf("prs = %+v, want the one PR before the notice", prs)
+	}
+}
+
+func TestParseJSONExcerpt(t *testing.T) {
+	token := "ghp_" + strings.Repeat("a", 36)
+	output := []byte("{" + token + " " + strings.Repeat("x", 1000) + " tail")
+
+	var v map[string]any
+	err := parseJSON("PR data", output, &v)
+	if err == nil {
+		t.Fatal("parseJSON of invalid output returned no error")
+	}
+	msg := err.Error()
+	if strings.Contains(msg, token) {
+		t.Errorf("error echoes a token: %s", msg)
+	}
+	if !strings.Contains(msg, "[redacted]") || !strings.Contains(msg, " … ") || !strings.Contains(msg, "tail") {
+		t.Errorf("error should quote the redacted start and the end of the output: %s", msg)
+	}
+	if len(msg) > 3*outputExcerptBytes {
+		t.Errorf("error is %d bytes, want long output cut down", len(msg))
+	}
+}
+
+func TestParseJSONErrorObject(t *testing.T) {
+	fakeGH(t, `echo '{"message":"Validation Failed","errors":[{"message":"The listed users cannot be searched"},"Query is too long"],"documentation_url":"https://docs.github.com"}'`)
+
+	_, err := FetchPRs(context.Background(), testRange(), FetchOptions{})
+	var parseErr *ErrParse
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("error = %v, want an *ErrParse", err)
+	}
+	want := "gh returned an error for PR data: Validation Failed; The listed users cannot be searched; Query is too long"
+	if err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
+
+func TestParseJSONPartialGraphQLData(t *testing.T) {
+	output := []byte(`{"data":{"r0":{"isFork":true}},"errors":[{"message":"Could not resolve to a Repository"}]}`)
+
+	var v struct {
+		Data map[string]map[string]bool `json:"data"`
+	}
+	if err := parseJSON("repository data", output, &v); err != nil {
+		t.Fatalf("parseJSON of partial data: %v", err)
+	}
+	if !v.Data["r0"]["isFork"] {
+		t.Errorf("data = %v, want the partial results", v.Data)
+	}
+}
@@ -0,0 +1,194 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// RepoInfo holds the repository flags used to exclude activity
+type RepoInfo struct {
+	IsFork     bool `json:"isFork"`
+	IsArchived bool `json:"isArchived"`
+}
+
+// repoLookupBatchSize is the number of repositories looked up per GraphQL query
+const repoLookupBatchSize = 50
+
+// FetchRepoInfo looks up the fork and archived status of repositories, named
+// as "owner/name", with batched GraphQL queries. Repositories that can't be
+// found are left out of the result.
+func FetchRepoInfo(ctx context.Context, names []string) (map[string]RepoInfo, error) {
+	info := make(map[string]RepoInfo)
+
+	for start := 0; start < len(names); start += repoLookupBatchSize {
+		batch := names[start:min(start+repoLookupBatchSize, len(names))]
+
+		var query strings.Builder
+		query.WriteString("query {")
+		for i, name := range batch {
+			owner, repo, ok := strings.Cut(name, "/")
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&query, " r%d: repository(owner: %q, name: %q) { isFork isArchived }", i, owner, repo)
+		}
+		query.WriteString(" }")
+
+		// gh exits non-zero when any repository can't be resolved, but still
+		// prints the data for the rest
+		output, err := exec.CommandContext(ctx, "gh", "api", "graphql", "-f", "query="+query.String()).Output()
+		if err != nil && len(output) == 0 {
+			return nil, ghError(err)
+		}
+
+		var resp struct {
+			Data map[string]*RepoInfo `json:"data"`
+		}
+		if err := parseJSON("repository data", output, &resp); err != nil {
+			return nil, err
+		}
+
+		for i, name := range batch {
+			if r := resp.Data[fmt.Sprintf("r%d", i)]; r != nil {
+				info[name] = *r
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// repoNames returns the distinct repositories with activity, sorted
+func (msg ActivityLoadedMsg) repoNames() []string {
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if name != "" {
+			seen[name] = true
+		}
+	}
+	for _, pr := range msg.PRs {
+		add(pr.Repository.NameWithOwner)
+	}
+	for _, issue := range msg.Issues {
+		add(issue.Repository.NameWithOwner)
+	}
+	for _, review := range msg.Reviews {
+		add(review.Repository.NameWithOwner)
+	}
+	for _, commit := range msg.Commits {
+		add(commit.Repository.FullName)
+	}
+	for _, item := range msg.CommentedItems {
+		add(item.Repository.NameWithOwner)
+	}
+	for _, d := range msg.Discussions {
+		add(d.Repository.NameWithOwner)
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExcludeRepos removes all activity in the given repositories
+func (msg *ActivityLoadedMsg) ExcludeRepos(excluded map[string]bool) {
+	msg.PRs = filterByRepo(msg.PRs, excluded, func(pr PullRequest) string { return pr.Repository.NameWithOwner })
+	msg.Issues = filterByRepo(msg.Issues, excluded, func(issue Issue) string { return issue.Repository.NameWithOwner })
+	msg.Reviews = filterByRepo(msg.Reviews, excluded, func(review Review) string { return review.Repository.NameWithOwner })
+	msg.Commits = filterByRepo(msg.Commits, excluded, func(commit Commit) string { return commit.Repository.FullName })
+	msg.CommentedItems = filterByRepo(msg.CommentedItems, excluded, func(item CommentedItem) string { return item.Repository.NameWithOwner })
+	msg.Discussions = filterByRepo(msg.Discussions, excluded, func(d Discussion) string { return d.Repository.NameWithOwner })
+}
+
+// filterByRepo returns the items whose repository is not excluded
+func filterByRepo[T any](items []T, excluded map[string]bool, repo func(T) string) []T {
+	var kept []T
+	for _, item := range items {
+		if !excluded[repo(item)] {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// filterItems returns the items keep reports true for
+func filterItems[T any](items []T, keep func(T) bool) []T {
+	var kept []T
+	for _, item := range items {
+		if keep(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// Modes for FetchOptions.OwnRepos
+const (
+	OwnReposInclude = "include"
+	OwnReposExclude = "exclude"
+	OwnReposOnly    = "only"
+)
+
+// filterOwnRepos drops activity in repositories owned by the user whose
+// activity it is for mode OwnReposExclude, or keeps only that for
+// OwnReposOnly. Items fetched for a team member belong to that member and
+// the rest to login, looked up once with gh api user if it is "@me". If the
+// lookup fails nothing is dropped and a warning is added.
+func filterOwnRepos(ctx context.Context, msg *ActivityLoadedMsg, mode, login string) {
+	if mode != OwnReposExclude && mode != OwnReposOnly {
+		return
+	}
+
+	if login != "" {
+		resolved, err := resolveLogin(ctx, login)
+		if err != nil {
+			msg.Warnings = append(msg.Warnings, fmt.Sprintf("Could not look up your login, so own_repos was not applied: %v", err))
+			return
+		}
+		login = resolved
+	}
+
+	keep := func(repo, member string) bool {
+		if member == "" {
+			member = login
+		}
+		owner, _, _ := strings.Cut(repo, "/")
+		// Logins are case-insensitive
+		own := member != "" && strings.EqualFold(owner, member)
+		return own == (mode == OwnReposOnly)
+	}
+	msg.PRs = filterItems(msg.PRs, func(pr PullRequest) bool { return keep(pr.Repository.NameWithOwner, pr.Member) })
+	msg.Issues = filterItems(msg.Issues, func(issue Issue) bool { return keep(issue.Repository.NameWithOwner, issue.Member) })
+	msg.Reviews = filterItems(msg.Reviews, func(review Review) bool { return keep(review.Repository.NameWithOwner, review.Member) })
+	msg.Commits = filterItems(msg.Commits, func(commit Commit) bool { return keep(commit.Repository.FullName, commit.Member) })
+	msg.CommentedItems = filterItems(msg.CommentedItems, func(item CommentedItem) bool { return keep(item.Repository.NameWithOwner, item.Member) })
+	msg.Discussions = filterItems(msg.Discussions, func(d Discussion) bool { return keep(d.Repository.NameWithOwner, d.Member) })
+}
+
+// excludeRepos drops activity in forked or archived repositories, as set in
+// opts. If the lookup fails nothing is dropped and a warning is added.
+func excludeRepos(ctx context.Context, msg *ActivityLoadedMsg, opts FetchOptions) {
+	if !opts.ExcludeForks && !opts.ExcludeArchived {
+		return
+	}
+
+	info, err := FetchRepoInfo(ctx, msg.repoNames())
+	if err != nil {
+		msg.Warnings = append(msg.Warnings, fmt.Sprintf("Could not check for forked or archived repos, so none were excluded: %v", err))
+		return
+	}
+
+	excluded := make(map[string]bool)
+	for name, r := range info {
+		if (opts.ExcludeForks && r.IsFork) || (opts.ExcludeArchived && r.IsArchived) {
+			excluded[name] = true
+		}
+	}
+	msg.ExcludeRepos(excluded)
+}
@@ -0,0 +1,88 @@
+package github
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// forkActivity returns PRs and a commit in acme/web and in the fork me/web
+func forkActivity() ActivityLoadedMsg {
+	upstream := Repository{Name: "web", NameWithOwner: "acme/web"}
+	fork := Repository{Name: "web", NameWithOwner: "me/web"}
+	return ActivityLoadedMsg{
+		PRs: []PullRequest{
+			{Number: 1, Title: "Upstream fix", Repository: upstream},
+			{Number: 2, Title: "Fork tweak", Repository: fork},
+			{Number: 3, Title: "Another fork tweak", Repository: fork},
+		},
+		Commits: []Commit{{SHA: "abc1234", Repository: CommitRepository{FullName: "me/web"}}},
+	}
+}
+
+func TestExcludeForks(t *testing.T) {
+	// Repos are looked up in sorted order, so r1 is me/web
+	fakeGH(t, `echo '{"data":{"r0":{"isFork":false,"isArchived":false},"r1":{"isFork":true,"isArchived":false}}}'`)
+
+	kept := forkActivity()
+	excludeRepos(context.Background(), &kept, FetchOptions{ExcludeArchived: true})
+	if len(kept.PRs) != 3 || len(kept.Commits) != 1 {
+		t.Errorf("excluding only archived repos left %d PRs and %d commits, want all 3 and 1", len(kept.PRs), len(kept.Commits))
+	}
+
+	msg := forkActivity()
+	excludeRepos(context.Background(), &msg, FetchOptions{ExcludeForks: true})
+	if len(msg.PRs) != 1 || msg.PRs[0].Repository.NameWithOwner != "acme/web" {
+		t.Errorf("PRs = %+v, want only the upstream PR", msg.PRs)
+	}
+	if len(msg.Commits) != 0 {
+		t.Errorf("commits = %+v, want the fork's commit dropped", msg.Commits)
+	}
+	if len(msg.Warnings) != 0 {
+		t.Errorf("warnings = %q, want none", msg.Warnings)
+	}
+}
+
+func TestExcludeReposLookupFails(t *testing.T) {
+	fakeGH(t, `echo "HTTP 502" >&2; exit 1`)
+
+	msg := forkActivity()
+	excludeRepos(context.Background(), &msg, FetchOptions{ExcludeForks: true})
+	if len(msg.PRs) != 3 {
+		t.Errorf("a failed lookup left %d PRs, want all 3", len(msg.PRs))
+	}
+	if len(msg.Warnings) != 1 || !strings.Contains(msg.Warnings[0], "none were excluded") {
+		t.Errorf("warnings = %q, want one saying nothing was excluded", msg.Warnings)
+	}
+}
+
+func TestFilterOwnRepos(t *testing.T) {
+	// Logins are case-insensitive
+	fakeGH(t, `echo 'Me'`)
+
+	tests := []struct {
+		mode    string
+		prs     []int
+		commits int
+	}{
+		{"", []int{1, 2, 3, 4}, 1},
+		{OwnReposInclude, []int{1, 2, 3, 4}, 1},
+		{OwnReposExclude, []int{1}, 0},
+		{OwnReposOnly, []int{2, 3, 4}, 1},
+	}
+	for _, tt := range tests {
+		msg := forkActivity()
+		// A team member's PR in their own repo
+		msg.PRs = append(msg.PRs, PullRequest{Number: 4, Title: "Bob's tool", Member: "bob", Repository: Repository{Name: "tool", NameWithOwner: "bob/tool"}})
+		filterOwnRepos(context.Background(), &msg, tt.mode, "@me")
+
+		var prs []int
+		for _, pr := range msg.PRs {
+			prs = append(prs, pr.Number)
+		}
+		if !slices.Equal(prs, tt.prs) || len(msg.Commits) != tt.commits {
+			t.Errorf("own_repos %q kept PRs %v and %d commits, want %v and %d", tt.mode, prs, len(msg.Commits), tt.prs, tt.commits)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// retryScript is a fake gh whose review search runs reviews, given the
+// number of earlier review searches in $n, and logs each search to log
+func retryScript(log, reviews string) string {
+	return `echo "$*" >> '` + log + `'
+case "$*" in
+"search prs --reviewed-by "*)
+	n=$(grep -c -- '--reviewed-by' '` + log + `')
+	n=$((n - 1))
+	` + reviews + ` ;;
+*) echo '[]' ;;
+esac`
+}
+
+// searches counts the logged gh runs containing arg
+func searches(t *testing.T, log, arg string) int {
+	t.Helper()
+	output, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(output), arg)
+}
+
+// noRetryBackoff retries failed fetches without pausing for the test
+func noRetryBackoff(t *testing.T) {
+	backoff := fetchRetryBackoff
+	fetchRetryBackoff = 0
+	t.Cleanup(func() { fetchRetryBackoff = backoff })
+}
+
+func TestFailedSearchRetried(t *testing.T) {
+	noRetryBackoff(t)
+	log := filepath.Join(t.TempDir(), "log")
+	// The review search fails once, then succeeds
+	fakeGH(t, retryScript(log, `if [ "$n" -eq 0 ]; then echo 'HTTP 502: Bad Gateway' >&2; exit 1; fi
+	echo '[{"number":7,"title":"Add caching","state":"open","createdAt":"2025-03-10T10:00:00Z","repository":{"name":"web","nameWithOwner":"acme/web"}}]'`))
+
+	msg := FetchActivityCmd(testRange(), FetchOptions{Author: "alice", Retries: 2})().(ActivityLoadedMsg)
+	if msg.Error != nil {
+		t.Fatalf("FetchActivityCmd: %v", msg.Error)
+	}
+	if len(msg.Reviews) != 1 || msg.Reviews[0].Number != 7 {
+		t.Errorf("Reviews = %+v, want the retried search's review", msg.Reviews)
+	}
+	if n := searches(t, log, "--reviewed-by"); n != 2 {
+		t.Errorf("review search ran %d times, want 2", n)
+	}
+	// Searches that succeeded aren't run again
+	if n := searches(t, log, "search commits"); n != 1 {
+		t.Errorf("commit search ran %d times, want once", n)
+	}
+}
+
+func TestParseErrorNotRetried(t *testing.T) {
+	noRetryBackoff(t)
+	log := filepath.Join(t.TempDir(), "log")
+	fakeGH(t, retryScript(log, `echo 'not json'`))
+
+	msg := FetchActivityCmd(testRange(), FetchOptions{Author: "alice", Retries: 2})().(ActivityLoadedMsg)
+	var parseErr *ErrParse
+	if !errors.As(msg.Error, &parseErr) {
+		t.Fatalf("Error = %v, want an ErrParse", msg.Error)
+	}
+	if n := searches(t, log, "--reviewed-by"); n != 1 {
+		t.Errorf("review search ran %d times, want once as the output would be the same", n)
+	}
+}
@@ -0,0 +1,130 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Review outcomes returned by Review.Outcome
+const (
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+	ReviewCommented        = "COMMENTED"
+	ReviewOther            = "OTHER"
+)
+
+// Outcome returns the reviewer's verdict from State: ReviewApproved,
+// ReviewChangesRequested, ReviewCommented, or ReviewOther when the state is
+// empty or anything else, like DISMISSED
+func (r Review) Outcome() string {
+	switch s := strings.ToUpper(r.State); s {
+	case ReviewApproved, ReviewChangesRequested, ReviewCommented:
+		return s
+	}
+	return ReviewOther
+}
+
+// ReviewOutcomes summarizes reviews by outcome, e.g. "3 approved, 1 changes
+// requested", or returns "" if no review has one
+func ReviewOutcomes(reviews []Review) string {
+	counts := make(map[string]int)
+	for _, r := range reviews {
+		counts[r.Outcome()]++
+	}
+	if counts[ReviewOther] == len(reviews) {
+		return ""
+	}
+
+	var parts []string
+	for _, o := range []struct{ outcome, label string }{
+		{ReviewApproved, "approved"},
+		{ReviewChangesRequested, "changes requested"},
+		{ReviewCommented, "commented"},
+		{ReviewOther, "other"},
+	} {
+		if counts[o.outcome] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[o.outcome], o.label))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FetchReviewExcerpts fills in ReviewExcerpts on each review with up to
+// perItem of login's most recent review bodies and inline review comments on
+// the PR, or the authenticated user's if login is "@me", running at most
+// concurrency lookups at a time. Reviews are updated in place; the first
+// lookup error is returned after all lookups finish.
+func FetchReviewExcerpts(ctx context.Context, reviews []Review, login string, perItem, concurrency int) error {
+	login, err := resolveLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+	return lookupAll(reviews, concurrency, func(r *Review) error {
+		return fetchReviewComments(ctx, r, login, perItem)
+	})
+}
+
+// reviewComment is a review body or an inline review comment from the
+// GitHub REST API. Review bodies have SubmittedAt, inline comments a Path
+// and CreatedAt.
+type reviewComment struct {
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Body        string    `json:"body"`
+	Path        string    `json:"path"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// fetchReviewComments fetches every review body and inline review comment on
+// a single PR and keeps the most recent ones by login
+func fetchReviewComments(ctx context.Context, r *Review, login string, perItem int) error {
+	var all []reviewComment
+	for _, kind := range []string{"reviews", "comments"} {
+		endpoint := fmt.Sprintf("repos/%s/pulls/%d/%s?per_page=100", r.Repository.NameWithOwner, r.Number, kind)
+		comments, err := fetchAllPages[reviewComment](ctx, fmt.Sprintf("review %s on %s#%d", kind, r.Repository.NameWithOwner, r.Number), endpoint)
+		if err != nil {
+			return err
+		}
+		all = append(all, comments...)
+	}
+
+	r.ReviewExcerpts = reviewExcerpts(all, login, perItem)
+	return nil
+}
+
+// reviewExcerpts returns the last perItem non-empty review bodies and inline
+// comments written by login, oldest first, each collapsed to a single line
+// and truncated. Inline comments are prefixed with the file they are on.
+func reviewExcerpts(comments []reviewComment, login string, perItem int) []string {
+	var mine []reviewComment
+	for _, c := range comments {
+		if c.User.Login == login && strings.TrimSpace(c.Body) != "" {
+			mine = append(mine, c)
+		}
+	}
+	at := func(c reviewComment) time.Time {
+		if c.SubmittedAt.IsZero() {
+			return c.CreatedAt
+		}
+		return c.SubmittedAt
+	}
+	sort.SliceStable(mine, func(i, j int) bool { return at(mine[i]).Before(at(mine[j])) })
+	if len(mine) > perItem {
+		mine = mine[len(mine)-perItem:]
+	}
+
+	excerpts := make([]string, 0, len(mine))
+	for _, c := range mine {
+		text := strings.Join(strings.Fields(c.Body), " ")
+		if c.Path != "" {
+			text = c.Path + ": " + text
+		}
+		excerpts = append(excerpts, truncateRunes(text, commentExcerptLength))
+	}
+	return excerpts
+}
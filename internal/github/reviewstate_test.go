@@ -0,0 +1,36 @@
+package github
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFetchReviewExcerpts(t *testing.T) {
+	reviews, err := filepath.Abs("testdata/pr_reviews.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	comments, err := filepath.Abs("testdata/pr_review_comments.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The fixtures are every page's review bodies and inline comments as gh
+	// api --paginate --jq '.[]' prints them
+	fakeGH(t, `case "$*" in
+*"--paginate repos/acme/web/pulls/7/reviews"*) cat '`+reviews+`' ;;
+*"--paginate repos/acme/web/pulls/7/comments"*) cat '`+comments+`' ;;
+*) echo "unexpected gh $*" >&2; exit 1 ;;
+esac`)
+
+	items := []Review{{Number: 7, Title: "Add caching", Repository: Repository{Name: "web", NameWithOwner: "acme/web"}}}
+	if err := FetchReviewExcerpts(context.Background(), items, "octocat", 3, 4); err != nil {
+		t.Fatalf("FetchReviewExcerpts: %v", err)
+	}
+
+	want := []string{"cache.go: Off by one?", "cache_test.go: Missing the empty case.", "Looks good now, thanks!"}
+	if !slices.Equal(items[0].ReviewExcerpts, want) {
+		t.Errorf("ReviewExcerpts = %q, want the last three by octocat %q", items[0].ReviewExcerpts, want)
+	}
+}
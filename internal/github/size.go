@@ -0,0 +1,40 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// ResolvePRSizes sets Additions and Deletions on each PR with gh pr view,
+// which gh search does not return, running at most concurrency lookups at a
+// time. PRs are updated in place; the first lookup error is returned after
+// all lookups finish.
+func ResolvePRSizes(ctx context.Context, prs []PullRequest, concurrency int) error {
+	return lookupAll(prs, concurrency, func(pr *PullRequest) error {
+		return resolvePRSize(ctx, pr)
+	})
+}
+
+// resolvePRSize fetches the lines added and deleted by a single PR
+func resolvePRSize(ctx context.Context, pr *PullRequest) error {
+	output, err := exec.CommandContext(ctx, "gh", "pr", "view", strconv.Itoa(pr.Number),
+		"--repo", pr.Repository.NameWithOwner,
+		"--json", "additions,deletions",
+	).Output()
+	if err != nil {
+		return ghError(err)
+	}
+
+	var view struct {
+		Additions int `json:"additions"`
+		Deletions int `json:"deletions"`
+	}
+	if err := parseJSON(fmt.Sprintf("size of %s#%d", pr.Repository.NameWithOwner, pr.Number), output, &view); err != nil {
+		return err
+	}
+
+	pr.Additions, pr.Deletions = view.Additions, view.Deletions
+	return nil
+}
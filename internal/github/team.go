@@ -0,0 +1,85 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+)
+
+// FetchTeamMembers returns the logins of the members of team, given as
+// "org/team-slug", with gh api
+func FetchTeamMembers(ctx context.Context, team string) ([]string, error) {
+	org, slug, ok := strings.Cut(team, "/")
+	if !ok || org == "" || slug == "" {
+		return nil, fmt.Errorf("invalid team %q (expected org/team)", team)
+	}
+
+	output, err := exec.CommandContext(ctx, "gh", "api", "--paginate",
+		fmt.Sprintf("orgs/%s/teams/%s/members", org, slug),
+		"--jq", ".[].login",
+	).Output()
+	if err != nil {
+		return nil, ghError(err)
+	}
+
+	members := strings.Fields(string(output))
+	if len(members) == 0 {
+		return nil, fmt.Errorf("team %s has no members", team)
+	}
+	return members, nil
+}
+
+// fetchTeamActivity fetches each of opts.Members' activity in turn, so no
+// more than opts.Concurrency searches run at once, and merges it. Every item
+// is tagged with the member it was fetched for, and warnings are prefixed
+// with the member's login.
+func fetchTeamActivity(ctx context.Context, dateRange daterange.Range, opts FetchOptions) ActivityLoadedMsg {
+	var team ActivityLoadedMsg
+	for _, member := range opts.Members {
+		memberOpts := opts
+		memberOpts.Author = member
+		memberOpts.Members = nil
+
+		msg := fetchActivity(ctx, dateRange, memberOpts)
+		if msg.Error != nil {
+			return ActivityLoadedMsg{Error: fmt.Errorf("could not fetch activity for %s: %w", member, msg.Error)}
+		}
+		msg.tagMember(member)
+
+		team.PRs = append(team.PRs, msg.PRs...)
+		team.Issues = append(team.Issues, msg.Issues...)
+		team.Reviews = append(team.Reviews, msg.Reviews...)
+		team.Commits = append(team.Commits, msg.Commits...)
+		team.CommentedItems = append(team.CommentedItems, msg.CommentedItems...)
+		team.Discussions = append(team.Discussions, msg.Discussions...)
+		for _, w := range msg.Warnings {
+			team.Warnings = append(team.Warnings, member+": "+w)
+		}
+	}
+	return team
+}
+
+// tagMember sets Member on every item to member
+func (msg *ActivityLoadedMsg) tagMember(member string) {
+	for i := range msg.PRs {
+		msg.PRs[i].Member = member
+	}
+	for i := range msg.Issues {
+		msg.Issues[i].Member = member
+	}
+	for i := range msg.Reviews {
+		msg.Reviews[i].Member = member
+	}
+	for i := range msg.Commits {
+		msg.Commits[i].Member = member
+	}
+	for i := range msg.CommentedItems {
+		msg.CommentedItems[i].Member = member
+	}
+	for i := range msg.Discussions {
+		msg.Discussions[i].Member = member
+	}
+}
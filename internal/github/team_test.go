@@ -0,0 +1,33 @@
+package github
+
+import "testing"
+
+func TestFetchTeamActivity(t *testing.T) {
+	fakeGH(t, `case "$*" in
+"search prs --author alice "*) echo '[{"number":1,"title":"Add caching","state":"open","repository":{"name":"web","nameWithOwner":"acme/web"}},{"number":2,"title":"Fix login","state":"open","repository":{"name":"web","nameWithOwner":"acme/web"}}]' ;;
+"search prs --author bob "*) echo '[{"number":3,"title":"Add metrics","state":"open","repository":{"name":"api","nameWithOwner":"acme/api"}}]' ;;
+*) echo '[]' ;;
+esac`)
+
+	msg := FetchActivityCmd(testRange(), FetchOptions{Members: []string{"alice", "bob"}})().(ActivityLoadedMsg)
+	if msg.Error != nil {
+		t.Fatalf("FetchActivityCmd: %v", msg.Error)
+	}
+	if len(msg.PRs) != 3 {
+		t.Fatalf("got %d PRs, want both members' 3", len(msg.PRs))
+	}
+	counts := make(map[string]int)
+	for _, pr := range msg.PRs {
+		counts[pr.Member]++
+		want := "alice"
+		if pr.Number == 3 {
+			want = "bob"
+		}
+		if pr.Member != want {
+			t.Errorf("PR #%d Member = %q, want %q", pr.Number, pr.Member, want)
+		}
+	}
+	if counts["alice"] != 2 || counts["bob"] != 1 {
+		t.Errorf("PRs per member = %v, want alice 2 and bob 1", counts)
+	}
+}
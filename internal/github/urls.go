@@ -0,0 +1,54 @@
+package github
+
+import (
+	"fmt"
+	"os"
+)
+
+// webURL returns where repositories are browsed: the GitHub Enterprise
+// Server host in GH_HOST, which gh sends its requests to, or github.com
+func webURL() string {
+	if host := os.Getenv("GH_HOST"); host != "" {
+		return "https://" + host
+	}
+	return "https://github.com"
+}
+
+// itemURL returns the web URL of a numbered item in repo, where kind is the
+// path segment for its type, e.g. "pull"
+func itemURL(repo, kind string, number int) string {
+	return fmt.Sprintf("%s/%s/%s/%d", webURL(), repo, kind, number)
+}
+
+// URL returns the PR's page on GitHub
+func (pr PullRequest) URL() string {
+	return itemURL(pr.Repository.NameWithOwner, "pull", pr.Number)
+}
+
+// URL returns the issue's page on GitHub
+func (i Issue) URL() string {
+	return itemURL(i.Repository.NameWithOwner, "issues", i.Number)
+}
+
+// URL returns the reviewed PR's page on GitHub
+func (r Review) URL() string {
+	return itemURL(r.Repository.NameWithOwner, "pull", r.Number)
+}
+
+// URL returns the commit's page on GitHub
+func (c Commit) URL() string {
+	return fmt.Sprintf("%s/%s/commit/%s", webURL(), c.Repository.FullName, c.SHA)
+}
+
+// URL returns the commented PR or issue's page on GitHub
+func (ci CommentedItem) URL() string {
+	if ci.IsPR {
+		return itemURL(ci.Repository.NameWithOwner, "pull", ci.Number)
+	}
+	return itemURL(ci.Repository.NameWithOwner, "issues", ci.Number)
+}
+
+// URL returns the discussion's page on GitHub
+func (d Discussion) URL() string {
+	return itemURL(d.Repository.NameWithOwner, "discussions", d.Number)
+}
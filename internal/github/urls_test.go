@@ -0,0 +1,33 @@
+package github
+
+import "testing"
+
+func TestURLs(t *testing.T) {
+	repo := Repository{Name: "web", NameWithOwner: "acme/web"}
+	tests := []struct {
+		name string
+		url  func() string
+		want string
+	}{
+		{"PR", PullRequest{Number: 1, Repository: repo}.URL, "/acme/web/pull/1"},
+		{"issue", Issue{Number: 2, Repository: repo}.URL, "/acme/web/issues/2"},
+		{"review", Review{Number: 3, Repository: repo}.URL, "/acme/web/pull/3"},
+		{"commit", Commit{SHA: "abc1234", Repository: CommitRepository{FullName: "acme/web"}}.URL, "/acme/web/commit/abc1234"},
+		{"commented PR", CommentedItem{Number: 4, IsPR: true, Repository: repo}.URL, "/acme/web/pull/4"},
+		{"commented issue", CommentedItem{Number: 5, Repository: repo}.URL, "/acme/web/issues/5"},
+		{"discussion", Discussion{Number: 6, Repository: repo}.URL, "/acme/web/discussions/6"},
+	}
+
+	for _, host := range []string{"", "github.example.com"} {
+		t.Setenv("GH_HOST", host)
+		base := "https://github.com"
+		if host != "" {
+			base = "https://" + host
+		}
+		for _, tt := range tests {
+			if got := tt.url(); got != base+tt.want {
+				t.Errorf("GH_HOST %q: %s URL = %q, want %q", host, tt.name, got, base+tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package keys holds the key bindings shared by every screen
+package keys
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// KeyMap holds a binding for each remappable action
+type KeyMap struct {
+	Up        key.Binding
+	Down      key.Binding
+	Select    key.Binding
+	Back      key.Binding
+	Quit      key.Binding
+	Save      key.Binding
+	SaveQuit  key.Binding
+	Edit      key.Binding
+	Duplicate key.Binding
+	Filter    key.Binding
+	Retry     key.Binding
+	Toggle    key.Binding
+	PageUp    key.Binding
+	PageDown  key.Binding
+	Top       key.Binding
+	Bottom    key.Binding
+	Sort      key.Binding
+	Timeline  key.Binding
+	Settings  key.Binding
+	Yank      key.Binding
+	Render    key.Binding
+}
+
+// Default returns the built-in bindings
+func Default() KeyMap {
+	return KeyMap{
+		Up:        key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑", "up")),
+		Down:      key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓", "down")),
+		Select:    key.NewBinding(key.WithKeys("enter"), key.WithHelp("Enter", "select")),
+		Back:      key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "back")),
+		Quit:      key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+		Save:      key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "save")),
+		SaveQuit:  key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "save and quit")),
+		Edit:      key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
+		Duplicate: key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "duplicate")),
+		Filter:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("Tab", "filter")),
+		Retry:     key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "retry")),
+		Toggle:    key.NewBinding(key.WithKeys(" "), key.WithHelp("Space", "toggle")),
+		PageUp:    key.NewBinding(key.WithKeys("pgup"), key.WithHelp("PgUp", "page up")),
+		PageDown:  key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("PgDn", "page down")),
+		Top:       key.NewBinding(key.WithKeys("home"), key.WithHelp("Home", "top")),
+		Bottom:    key.NewBinding(key.WithKeys("end"), key.WithHelp("End", "bottom")),
+		Sort:      key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "sort")),
+		Timeline:  key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "timeline")),
+		Settings:  key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "switch model")),
+		Yank:      key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy link")),
+		Render:    key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "render markdown")),
+	}
+}
+
+// Map is the active key map, replaced at startup by Load
+var Map = Default()
+
+// actions maps config action names to their binding in a KeyMap
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":        &k.Up,
+		"down":      &k.Down,
+		"select":    &k.Select,
+		"back":      &k.Back,
+		"quit":      &k.Quit,
+		"save":      &k.Save,
+		"save_quit": &k.SaveQuit,
+		"edit":      &k.Edit,
+		"duplicate": &k.Duplicate,
+		"filter":    &k.Filter,
+		"retry":     &k.Retry,
+		"toggle":    &k.Toggle,
+		"page_up":   &k.PageUp,
+		"page_down": &k.PageDown,
+		"top":       &k.Top,
+		"bottom":    &k.Bottom,
+		"sort":      &k.Sort,
+		"timeline":  &k.Timeline,
+		"settings":  &k.Settings,
+		"yank":      &k.Yank,
+		"render":    &k.Render,
+	}
+}
+
+// New returns the default bindings with overrides applied. Overrides map
+// action names to comma-separated keys, e.g. "back": "h,left". An error is
+// returned for unknown actions, empty bindings, or a key bound to two actions.
+func New(overrides map[string]string) (KeyMap, error) {
+	k := Default()
+	actions := k.actions()
+
+	for name, spec := range overrides {
+		b, ok := actions[strings.ToLower(name)]
+		if !ok {
+			return KeyMap{}, fmt.Errorf("unknown key action %q", name)
+		}
+		var bound []string
+		for _, s := range strings.Split(spec, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				bound = append(bound, s)
+			}
+		}
+		if len(bound) == 0 {
+			return KeyMap{}, fmt.Errorf("no keys given for action %q", name)
+		}
+		b.SetKeys(bound...)
+		b.SetHelp(strings.Join(bound, "/"), b.Help().Desc)
+	}
+
+	// Check every key belongs to a single action, in a stable order
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	owner := make(map[string]string)
+	for _, name := range names {
+		for _, k := range actions[name].Keys() {
+			if other, ok := owner[k]; ok {
+				return KeyMap{}, fmt.Errorf("key %q is bound to both %q and %q", k, other, name)
+			}
+			owner[k] = name
+		}
+	}
+
+	return k, nil
+}
+
+// Load replaces Map with the default bindings plus overrides
+func Load(overrides map[string]string) error {
+	k, err := New(overrides)
+	if err != nil {
+		return err
+	}
+	Map = k
+	return nil
+}
+
+// Nav returns the help text for the up and down bindings, e.g. "↑/↓"
+func (k KeyMap) Nav() string {
+	return k.Up.Help().Key + "/" + k.Down.Help().Key
+}
+
+// Paging returns the help text for the paging bindings, e.g. "PgUp/PgDn/Home/End"
+func (k KeyMap) Paging() string {
+	return strings.Join([]string{k.PageUp.Help().Key, k.PageDown.Help().Key, k.Top.Help().Key, k.Bottom.Help().Key}, "/")
+}
+
+// Page scrolls vp a page at a time or to either end when msg matches one of
+// the paging bindings, and reports whether it did
+func Page(vp *viewport.Model, msg tea.KeyMsg) bool {
+	switch {
+	case key.Matches(msg, Map.PageUp):
+		vp.PageUp()
+	case key.Matches(msg, Map.PageDown):
+		vp.PageDown()
+	case key.Matches(msg, Map.Top):
+		vp.GotoTop()
+	case key.Matches(msg, Map.Bottom):
+		vp.GotoBottom()
+	default:
+		return false
+	}
+	return true
+}
+
+// ViewportKeyMap returns the default viewport bindings with scrolling by line
+// following the Up and Down bindings
+func ViewportKeyMap() viewport.KeyMap {
+	km := viewport.DefaultKeyMap()
+	km.Up = Map.Up
+	km.Down = Map.Down
+	return km
+}
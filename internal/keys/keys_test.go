@@ -0,0 +1,55 @@
+package keys
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewRemapsAction(t *testing.T) {
+	k, err := New(map[string]string{"back": "h, left"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	h := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")}
+	b := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")}
+	if !key.Matches(h, k.Back) || !key.Matches(tea.KeyMsg{Type: tea.KeyLeft}, k.Back) {
+		t.Error("remapped back binding doesn't match h and left")
+	}
+	if key.Matches(b, k.Back) {
+		t.Error("remapped back binding still matches b")
+	}
+	if got := k.Back.Help().Key; got != "h/left" {
+		t.Errorf("help key = %q, want %q", got, "h/left")
+	}
+}
+
+func TestNewRejectsBadOverrides(t *testing.T) {
+	tests := []struct {
+		overrides map[string]string
+		want      string
+	}{
+		{map[string]string{"back": "q"}, `key "q" is bound to both "back" and "quit"`},
+		{map[string]string{"save": "x", "sort": "x"}, `key "x" is bound to both "save" and "sort"`},
+		{map[string]string{"jump": "g"}, `unknown key action "jump"`},
+		{map[string]string{"back": " , "}, `no keys given for action "back"`},
+	}
+	for _, tt := range tests {
+		_, err := New(tt.overrides)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("New(%v) error = %v, want %q", tt.overrides, err, tt.want)
+		}
+	}
+}
+
+func TestLoadKeepsMapOnError(t *testing.T) {
+	t.Cleanup(func() { Map = Default() })
+	if err := Load(map[string]string{"back": "q"}); err == nil {
+		t.Fatal("Load of conflicting bindings returned no error")
+	}
+	if got := Map.Back.Keys(); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Map.Back keys = %v after a failed Load, want [b]", got)
+	}
+}
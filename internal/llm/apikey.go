@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// resolveAPIKey returns the key in the envVar environment variable, or else
+// the trimmed contents of the file named by the fileEnvVar environment
+// variable or by file. It returns an empty key when none is configured.
+func resolveAPIKey(envVar, fileEnvVar, file string) (string, error) {
+	if key := os.Getenv(envVar); key != "" {
+		return key, nil
+	}
+	if path := os.Getenv(fileEnvVar); path != "" {
+		file = path
+	}
+	if file == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("could not read API key file: %w", err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("API key file %s is empty", file)
+	}
+	return key, nil
+}
+
+// AnthropicAPIKey returns the Anthropic API key from ANTHROPIC_API_KEY, or
+// else from the file named by ANTHROPIC_API_KEY_FILE or keyFile
+func AnthropicAPIKey(keyFile string) (string, error) {
+	key, err := resolveAPIKey("ANTHROPIC_API_KEY", "ANTHROPIC_API_KEY_FILE", keyFile)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", fmt.Errorf("ANTHROPIC_API_KEY environment variable not set and no API key file configured")
+	}
+	return key, nil
+}
@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// keyFile writes content to a file in a temporary directory and returns its path
+func keyFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAnthropicAPIKeyFromFile(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("ANTHROPIC_API_KEY_FILE", "")
+
+	key, err := AnthropicAPIKey(keyFile(t, "  sk-from-file\n"))
+	if err != nil {
+		t.Fatalf("AnthropicAPIKey: %v", err)
+	}
+	if key != "sk-from-file" {
+		t.Errorf("key = %q, want the trimmed file contents", key)
+	}
+
+	if _, err := AnthropicAPIKey(keyFile(t, "\n")); err == nil {
+		t.Error("an empty key file returned no error")
+	}
+	if _, err := AnthropicAPIKey(""); err == nil {
+		t.Error("no key at all returned no error")
+	}
+}
+
+func TestAnthropicAPIKeyPrecedence(t *testing.T) {
+	configured := keyFile(t, "sk-config-file")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+
+	// The environment's file beats the config's
+	t.Setenv("ANTHROPIC_API_KEY_FILE", keyFile(t, "sk-env-file"))
+	if key, err := AnthropicAPIKey(configured); err != nil || key != "sk-env-file" {
+		t.Errorf("AnthropicAPIKey = %q, %v, want the ANTHROPIC_API_KEY_FILE key", key, err)
+	}
+
+	// And the key itself beats both
+	t.Setenv("ANTHROPIC_API_KEY", "sk-env")
+	if key, err := AnthropicAPIKey(configured); err != nil || key != "sk-env" {
+		t.Errorf("AnthropicAPIKey = %q, %v, want the ANTHROPIC_API_KEY key", key, err)
+	}
+}
+
+func TestAPIKeyFileMissing(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_API_KEY_FILE", "")
+
+	_, err := resolveAPIKey("OPENAI_API_KEY", "OPENAI_API_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+	if err == nil {
+		t.Fatal("a missing key file returned no error")
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("error %v does not wrap a not-exist error", err)
+	}
+}
@@ -4,50 +4,147 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
 )
 
+// claudeMaxTokens is the output token limit of a report
+const claudeMaxTokens = 4096
+
 // ClaudeProvider implements Provider using the Anthropic API.
 type ClaudeProvider struct {
-	model string
+	model   string
+	keyFile string
+	baseURL string
+	timeout time.Duration
 }
 
-// NewClaudeProvider creates a ClaudeProvider with the given model name.
-func NewClaudeProvider(model string) *ClaudeProvider {
-	return &ClaudeProvider{model: model}
+// NewClaudeProvider creates a ClaudeProvider with the given model name. The
+// API key is read from keyFile when ANTHROPIC_API_KEY is not set. Requests go
+// to baseURL, overridden by ANTHROPIC_BASE_URL, or the SDK's default endpoint
+// if both are empty. Each request attempt is abandoned after timeout, unless
+// it is zero.
+func NewClaudeProvider(model, keyFile, baseURL string, timeout time.Duration) *ClaudeProvider {
+	if url := os.Getenv("ANTHROPIC_BASE_URL"); url != "" {
+		baseURL = url
+	}
+	return &ClaudeProvider{model: model, keyFile: keyFile, baseURL: baseURL, timeout: timeout}
 }
 
-// CheckAPIKey verifies that the ANTHROPIC_API_KEY environment variable is set.
-func CheckAPIKey() error {
-	if os.Getenv("ANTHROPIC_API_KEY") == "" {
-		return fmt.Errorf("ANTHROPIC_API_KEY environment variable not set")
+// knownClaudeModels lists the model names the Anthropic SDK knows about
+var knownClaudeModels = []anthropic.Model{
+	anthropic.ModelClaudeOpus4_5_20251101,
+	anthropic.ModelClaudeOpus4_5,
+	anthropic.ModelClaude3_7SonnetLatest,
+	anthropic.ModelClaude3_7Sonnet20250219,
+	anthropic.ModelClaude3_5HaikuLatest,
+	anthropic.ModelClaude3_5Haiku20241022,
+	anthropic.ModelClaudeHaiku4_5,
+	anthropic.ModelClaudeHaiku4_5_20251001,
+	anthropic.ModelClaudeSonnet4_20250514,
+	anthropic.ModelClaudeSonnet4_0,
+	anthropic.ModelClaude4Sonnet20250514,
+	anthropic.ModelClaudeSonnet4_5,
+	anthropic.ModelClaudeSonnet4_5_20250929,
+	anthropic.ModelClaudeOpus4_0,
+	anthropic.ModelClaudeOpus4_20250514,
+	anthropic.ModelClaude4Opus20250514,
+	anthropic.ModelClaudeOpus4_1_20250805,
+	anthropic.ModelClaude3OpusLatest,
+	anthropic.ModelClaude_3_Opus_20240229,
+	anthropic.ModelClaude_3_Haiku_20240307,
+}
+
+// ValidateClaudeModel checks the model against the allow-list, falling back to
+// the SDK's known models when the allow-list is empty.
+func ValidateClaudeModel(model string, allowed []string) error {
+	if len(allowed) == 0 {
+		for _, m := range knownClaudeModels {
+			allowed = append(allowed, string(m))
+		}
+	}
+	if slices.Contains(allowed, model) {
+		return nil
 	}
-	return nil
+	return fmt.Errorf("unknown Claude model %q (valid models: %s)", model, strings.Join(allowed, ", "))
 }
 
-// GenerateReport sends the user message to Claude and returns the response.
-func (c *ClaudeProvider) GenerateReport(ctx context.Context, userMessage string) (string, error) {
-	if err := CheckAPIKey(); err != nil {
-		return "", err
+// CheckAPIKey verifies that an Anthropic API key is available, either in the
+// ANTHROPIC_API_KEY environment variable or in a readable key file.
+func CheckAPIKey(keyFile string) error {
+	_, err := AnthropicAPIKey(keyFile)
+	return err
+}
+
+// requestOptions configures the SDK client with the key, base URL, and timeout
+func (c *ClaudeProvider) requestOptions(apiKey string) []option.RequestOption {
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if c.baseURL != "" {
+		opts = append(opts, option.WithBaseURL(c.baseURL))
 	}
+	if c.timeout > 0 {
+		opts = append(opts, option.WithRequestTimeout(c.timeout))
+	}
+	return opts
+}
 
-	client := anthropic.NewClient()
+// GenerateReport sends the messages to Claude and returns the response,
+// streamed as it is generated.
+func (c *ClaudeProvider) GenerateReport(ctx context.Context, systemPrompt, userMessage string) (string, Usage, error) {
+	apiKey, err := AnthropicAPIKey(c.keyFile)
+	if err != nil {
+		return "", Usage{}, err
+	}
 
-	message, err := client.Messages.New(ctx, anthropic.MessageNewParams{
+	client := anthropic.NewClient(c.requestOptions(apiKey)...)
+
+	params := anthropic.MessageNewParams{
 		Model:     anthropic.Model(c.model),
-		MaxTokens: 4096,
+		MaxTokens: claudeMaxTokens,
 		Messages: []anthropic.MessageParam{
 			anthropic.NewUserMessage(anthropic.NewTextBlock(userMessage)),
 		},
-	})
-	if err != nil {
-		return "", fmt.Errorf("Claude API error: %w", err)
 	}
+	if systemPrompt != "" {
+		params.System = []anthropic.TextBlockParam{{Text: systemPrompt}}
+	}
+
+	// Stream the reply so a cancelled request keeps the text received so far
+	stream := client.Messages.NewStreaming(ctx, params)
+	defer stream.Close()
 
-	if len(message.Content) == 0 {
-		return "", fmt.Errorf("no content in Claude API response")
+	var (
+		sb    strings.Builder
+		usage Usage
+		runes int
+	)
+	for stream.Next() {
+		event := stream.Current()
+		switch event.Type {
+		case "message_start":
+			usage.InputTokens = int(event.Message.Usage.InputTokens)
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" {
+				sb.WriteString(event.Delta.Text)
+				// Output tokens are only counted at the end, so estimate them
+				runes += utf8.RuneCountInString(event.Delta.Text)
+				reportProgress(ctx, runes/charsPerToken, claudeMaxTokens)
+			}
+		case "message_delta":
+			usage.OutputTokens = int(event.Usage.OutputTokens)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return sb.String(), usage, fmt.Errorf("Claude API error: %w", err)
+	}
+	if sb.Len() == 0 {
+		return "", usage, fmt.Errorf("no content in Claude API response")
 	}
 
-	return message.Content[0].Text, nil
+	return sb.String(), usage, nil
 }
@@ -0,0 +1,190 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/burritocatai/activitycat/internal/config"
+)
+
+func TestValidateClaudeModel(t *testing.T) {
+	if err := ValidateClaudeModel("claude-sonnet-4-5", nil); err != nil {
+		t.Errorf("ValidateClaudeModel of a known model: %v", err)
+	}
+
+	err := ValidateClaudeModel("claude-sonet-4-5", nil)
+	if err == nil {
+		t.Fatal("ValidateClaudeModel of an unknown model returned no error")
+	}
+	if !strings.Contains(err.Error(), "claude-sonnet-4-5") {
+		t.Errorf("error %q doesn't list the valid models", err)
+	}
+}
+
+func TestValidateClaudeModelAllowList(t *testing.T) {
+	allowed := []string{"claude-internal-preview"}
+	if err := ValidateClaudeModel("claude-internal-preview", allowed); err != nil {
+		t.Errorf("ValidateClaudeModel of an allowed model: %v", err)
+	}
+	if err := ValidateClaudeModel("claude-sonnet-4-5", allowed); err == nil {
+		t.Error("ValidateClaudeModel of a model missing from the allow-list returned no error")
+	}
+}
+
+// claudeStream is a streamed Messages API reply with the text "# Report"
+const claudeStream = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[],"stop_reason":null,"usage":{"input_tokens":12,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"# Report"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":3}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+// claudeServer serves the Messages API, streaming claudeStream, and passes
+// each decoded request body to inspect. ANTHROPIC_BASE_URL and
+// ANTHROPIC_API_KEY are pointed at it.
+func claudeServer(t *testing.T, inspect func(body map[string]any)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			http.NotFound(w, r)
+			return
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		inspect(body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(claudeStream))
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("ANTHROPIC_BASE_URL", srv.URL)
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	return srv
+}
+
+func TestClaudeSystemPrompt(t *testing.T) {
+	var body map[string]any
+	claudeServer(t, func(b map[string]any) { body = b })
+	provider := NewClaudeProvider("claude-sonnet-4-5", "", "", 0)
+
+	report, usage, err := provider.GenerateReport(context.Background(), "Summarize my week.", "activity")
+	if err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if report != "# Report" || usage.InputTokens != 12 || usage.OutputTokens != 3 {
+		t.Errorf("GenerateReport = %q, %+v, want the streamed report and usage", report, usage)
+	}
+	system, _ := body["system"].([]any)
+	if len(system) != 1 || system[0].(map[string]any)["text"] != "Summarize my week." {
+		t.Errorf("system = %v, want the prompt", body["system"])
+	}
+	if got := claudeUserText(body); got != "activity" {
+		t.Errorf("user message = %q, want only the activity", got)
+	}
+
+	if _, _, err := provider.GenerateReport(context.Background(), "", "prompt and activity"); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if _, ok := body["system"]; ok {
+		t.Errorf("system = %v with no system prompt, want it left out", body["system"])
+	}
+	if got := claudeUserText(body); got != "prompt and activity" {
+		t.Errorf("user message = %q, want %q", got, "prompt and activity")
+	}
+}
+
+// claudeUserText returns the text of the single user message in a Messages
+// API request body
+func claudeUserText(body map[string]any) string {
+	messages, _ := body["messages"].([]any)
+	if len(messages) != 1 {
+		return ""
+	}
+	content, _ := messages[0].(map[string]any)["content"].([]any)
+	if len(content) != 1 {
+		return ""
+	}
+	text, _ := content[0].(map[string]any)["text"].(string)
+	return text
+}
+
+func TestClaudeBaseURL(t *testing.T) {
+	requests := 0
+	srv := claudeServer(t, func(map[string]any) { requests++ })
+
+	// From the config, with ANTHROPIC_BASE_URL unset
+	t.Setenv("ANTHROPIC_BASE_URL", "")
+	provider, err := NewProvider(config.Config{Provider: "claude", Model: "claude-sonnet-4-5", AnthropicBaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	if got := provider.(*ClaudeProvider).baseURL; got != srv.URL {
+		t.Errorf("baseURL = %q, want the configured %q", got, srv.URL)
+	}
+	if _, _, err := provider.GenerateReport(context.Background(), "", "activity"); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("the gateway got %d requests, want 1", requests)
+	}
+
+	// ANTHROPIC_BASE_URL overrides the config
+	t.Setenv("ANTHROPIC_BASE_URL", srv.URL)
+	if _, _, err := NewClaudeProvider("claude-sonnet-4-5", "", "http://127.0.0.1:1", 0).GenerateReport(context.Background(), "", "activity"); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("the gateway got %d requests, want 2", requests)
+	}
+
+	if err := CheckAPIKey(""); err != nil {
+		t.Errorf("CheckAPIKey with a base URL set: %v", err)
+	}
+}
+
+func TestCancelMidStream(t *testing.T) {
+	// The server sends the text, then stalls without finishing the message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		partial, _, _ := strings.Cut(claudeStream, "event: content_block_stop")
+		w.Write([]byte(partial))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("ANTHROPIC_BASE_URL", srv.URL)
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+
+	// Cancel as soon as the first chunk has been received
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = WithProgress(ctx, func(float64) { cancel() })
+
+	msg := SendMessageCmd(ctx, NewClaudeProvider("claude-sonnet-4-5", "", "", 0), nil, "", "activity")().(ReportGeneratedMsg)
+	if !msg.Cancelled || msg.Error != nil {
+		t.Fatalf("message = %+v, want a cancelled report with no error", msg)
+	}
+	if msg.Report != "# Report" {
+		t.Errorf("Report = %q, want the chunk received before cancelling", msg.Report)
+	}
+}
@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/burritocatai/activitycat/internal/config"
+)
+
+// charsPerToken is the rough number of characters in a token of English text
+const charsPerToken = 4
+
+// modelPrices are Claude prices in US dollars per million tokens, keyed by
+// model alias. Dated model IDs match the alias they start with.
+var modelPrices = map[string]config.ModelPrice{
+	"claude-opus-4-5":   {Input: 5, Output: 25},
+	"claude-opus-4-1":   {Input: 15, Output: 75},
+	"claude-opus-4":     {Input: 15, Output: 75},
+	"claude-sonnet-4-5": {Input: 3, Output: 15},
+	"claude-sonnet-4":   {Input: 3, Output: 15},
+	"claude-3-7-sonnet": {Input: 3, Output: 15},
+	"claude-haiku-4-5":  {Input: 1, Output: 5},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+	"claude-3-opus":     {Input: 15, Output: 75},
+}
+
+// ModelPrice looks up the price of model, preferring overrides to the
+// built-in table. An exact match wins, then the longest name the model starts
+// with, so "claude-opus-4-1-20250805" uses "claude-opus-4-1" and not
+// "claude-opus-4". It reports false if the model has no known price.
+func ModelPrice(model string, overrides map[string]config.ModelPrice) (config.ModelPrice, bool) {
+	for _, prices := range []map[string]config.ModelPrice{overrides, modelPrices} {
+		if p, ok := prices[model]; ok {
+			return p, true
+		}
+		best := ""
+		for name := range prices {
+			if strings.HasPrefix(model, name) && len(name) > len(best) {
+				best = name
+			}
+		}
+		if best != "" {
+			return prices[best], true
+		}
+	}
+	return config.ModelPrice{}, false
+}
+
+// EstimateTokens roughly estimates the number of tokens in s from its length
+func EstimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + charsPerToken - 1) / charsPerToken
+}
+
+// Cost returns the price in US dollars of the tokens in usage
+func Cost(price config.ModelPrice, usage Usage) float64 {
+	return (float64(usage.InputTokens)*price.Input + float64(usage.OutputTokens)*price.Output) / 1_000_000
+}
+
+// EstimateCost estimates the input cost in US dollars of sending the system
+// prompt and user message. Output length can't be known in advance, so it is
+// not included.
+func EstimateCost(price config.ModelPrice, systemPrompt, userMessage string) float64 {
+	return Cost(price, Usage{InputTokens: EstimateTokens(systemPrompt) + EstimateTokens(userMessage)})
+}
+
+// FormatCost formats a cost in US dollars, e.g. "$0.03". Costs under a cent
+// are shown as "<$0.01".
+func FormatCost(usd float64) string {
+	if usd > 0 && usd < 0.005 {
+		return "<$0.01"
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/burritocatai/activitycat/internal/config"
+)
+
+func TestEstimateCost(t *testing.T) {
+	price := config.ModelPrice{Input: 3, Output: 15}
+	// 40,000 characters are about 10,000 tokens, at $3 per million
+	message := strings.Repeat("abcd", 10_000)
+
+	if got := EstimateTokens(message); got != 10_000 {
+		t.Errorf("EstimateTokens = %d, want 10000", got)
+	}
+	if got := EstimateCost(price, "", message); math.Abs(got-0.03) > 1e-9 {
+		t.Errorf("EstimateCost = %v, want 0.03", got)
+	}
+	if got := FormatCost(EstimateCost(price, "", message)); got != "$0.03" {
+		t.Errorf("FormatCost = %q, want %q", got, "$0.03")
+	}
+	// Partial tokens round up, and the system prompt counts too
+	if got := EstimateCost(price, "a", "abcde"); math.Abs(got-9e-6) > 1e-12 {
+		t.Errorf("EstimateCost = %v, want 3 tokens' worth", got)
+	}
+}
+
+func TestCost(t *testing.T) {
+	price := config.ModelPrice{Input: 3, Output: 15}
+	got := Cost(price, Usage{InputTokens: 2_000, OutputTokens: 1_000})
+	if math.Abs(got-0.021) > 1e-9 {
+		t.Errorf("Cost = %v, want 0.021", got)
+	}
+}
+
+func TestModelPrice(t *testing.T) {
+	overrides := map[string]config.ModelPrice{"claude-sonnet-4-5": {Input: 1, Output: 2}}
+	tests := []struct {
+		model string
+		want  config.ModelPrice
+		ok    bool
+	}{
+		{"claude-opus-4-1-20250805", config.ModelPrice{Input: 15, Output: 75}, true},
+		{"claude-opus-4-5", config.ModelPrice{Input: 5, Output: 25}, true},
+		{"claude-sonnet-4-5-20250929", config.ModelPrice{Input: 1, Output: 2}, true},
+		{"llama3", config.ModelPrice{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ModelPrice(tt.model, overrides)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ModelPrice(%q) = %v, %v, want %v, %v", tt.model, got, ok, tt.want, tt.ok)
+		}
+	}
+}
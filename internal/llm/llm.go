@@ -3,6 +3,8 @@ package llm
 import (
 	"context"
 	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 
@@ -12,53 +14,230 @@ import (
 )
 
 // Provider is the interface for LLM report generation backends.
+// systemPrompt may be empty, in which case no system prompt is sent.
+//
+// Providers stream the report as it is generated, so when ctx is cancelled
+// part way through, GenerateReport returns the text received so far along
+// with the error.
 type Provider interface {
-	GenerateReport(ctx context.Context, userMessage string) (string, error)
+	GenerateReport(ctx context.Context, systemPrompt, userMessage string) (string, Usage, error)
+}
+
+// Usage is the token count a provider reported for a request, zero if unknown
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
+// progressKey is the context key for the function streaming progress is
+// reported to
+type progressKey struct{}
+
+// WithProgress returns a context whose requests report their progress to
+// report, as the approximate fraction of the output token limit received so
+// far. Providers that set no token limit report nothing.
+func WithProgress(ctx context.Context, report func(fraction float64)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// reportProgress reports received of limit tokens to ctx's progress function, if any
+func reportProgress(ctx context.Context, received, limit int) {
+	if report, ok := ctx.Value(progressKey{}).(func(float64)); ok {
+		report(min(float64(received)/float64(limit), 1))
+	}
 }
 
 // ReportGeneratedMsg is sent when the report generation completes.
 type ReportGeneratedMsg struct {
 	Report string
 	Error  error
+	// Redactions is the number of substitutions made before sending
+	Redactions int
+	Usage      Usage
+	// Cancelled is set when the request was cancelled, in which case
+	// Report holds whatever was generated before then, if anything
+	Cancelled bool
 }
 
-// NewProvider creates a Provider based on the given config.
+// NewProvider creates a Provider based on the given config. A model naming a
+// [models] entry is resolved to that entry's provider and model.
 func NewProvider(cfg config.Config) (Provider, error) {
+	cfg = cfg.ResolveModel()
+	// The timeout was checked by Validate at startup
+	timeout, _ := cfg.RequestTimeoutDuration()
 	switch cfg.Provider {
 	case "claude":
-		return NewClaudeProvider(cfg.Model), nil
+		return NewClaudeProvider(cfg.Model, cfg.AnthropicAPIKeyFile, cfg.AnthropicBaseURL, timeout), nil
 	case "ollama":
-		return NewOllamaProvider(cfg.OllamaHost, cfg.Model), nil
+		return NewOllamaProvider(cfg.OllamaHost, cfg.Model, ollamaOptions(cfg)), nil
 	case "openai":
-		return NewOpenAIProvider(cfg.OpenAIBaseURL, cfg.OpenAIAPIKey, cfg.Model), nil
+		return NewOpenAIProvider(cfg.OpenAIBaseURL, cfg.OpenAIAPIKey, cfg.OpenAIAPIKeyFile, cfg.Model, timeout), nil
 	default:
 		return nil, fmt.Errorf("unknown LLM provider: %q (expected \"claude\", \"ollama\", or \"openai\")", cfg.Provider)
 	}
 }
 
-// GenerateReportCmd wraps any Provider in a bubbletea Cmd.
+// CheckProvider verifies that cfg's provider is ready to generate with
+// before switching to it: that its API key can be found and, for Claude and
+// Ollama, that its model is known or pulled
+func CheckProvider(ctx context.Context, cfg config.Config) error {
+	cfg = cfg.ResolveModel()
+	switch cfg.Provider {
+	case "claude":
+		if err := CheckAPIKey(cfg.AnthropicAPIKeyFile); err != nil {
+			return err
+		}
+		return ValidateClaudeModel(cfg.Model, cfg.AllowedModels)
+	case "ollama":
+		return NewOllamaProvider(cfg.OllamaHost, cfg.Model, ollamaOptions(cfg)).CheckModel(ctx)
+	case "openai":
+		if cfg.OpenAIBaseURL == "" {
+			return fmt.Errorf("openai_base_url is not set")
+		}
+		if cfg.OpenAIAPIKey != "" {
+			return nil
+		}
+		_, err := resolveAPIKey("OPENAI_API_KEY", "OPENAI_API_KEY_FILE", cfg.OpenAIAPIKeyFile)
+		return err
+	default:
+		_, err := NewProvider(cfg)
+		return err
+	}
+}
+
+// ValidateModel checks the configured model before any work is done so that
+// typos fail fast instead of surfacing as an API error after a full fetch.
+func ValidateModel(ctx context.Context, cfg config.Config) error {
+	cfg = cfg.ResolveModel()
+	switch cfg.Provider {
+	case "claude":
+		return ValidateClaudeModel(cfg.Model, cfg.AllowedModels)
+	case "ollama":
+		if !cfg.VerifyOllamaModel {
+			return nil
+		}
+		return NewOllamaProvider(cfg.OllamaHost, cfg.Model, ollamaOptions(cfg)).CheckModel(ctx)
+	default:
+		return nil
+	}
+}
+
+// ollamaOptions collects the Ollama settings from config
+func ollamaOptions(cfg config.Config) OllamaOptions {
+	timeout, _ := cfg.RequestTimeoutDuration()
+	return OllamaOptions{
+		APIKey:      cfg.OllamaAPIKey,
+		Headers:     cfg.OllamaHeaders,
+		KeepAlive:   cfg.OllamaKeepAlive,
+		NumCtx:      cfg.OllamaNumCtx,
+		Temperature: cfg.OllamaTemperature,
+		Timeout:     timeout,
+		Attempts:    cfg.OllamaAttempts,
+	}
+}
+
+// activityIntro introduces the formatted activity in the user message
+const activityIntro = "Here is my GitHub activity data:\n\n"
+
+// BuildMessages assembles the prompt and formatted activity into the messages
+// sent to the provider. With useSystem the prompt becomes the system prompt and
+// the user message holds only the activity; otherwise both are in the user
+// message and the system prompt is empty.
+func BuildMessages(
+	prs []github.PullRequest,
+	issues []github.Issue,
+	reviews []github.Review,
+	commits []github.Commit,
+	commentedItems []github.CommentedItem,
+	discussions []github.Discussion,
+	metrics *analytics.Metrics,
+	prompt string,
+	opts github.FormatOptions,
+	useSystem bool,
+) (systemPrompt, userMessage string) {
+	metricsText := ""
+	if metrics != nil {
+		metricsText = metrics.Format()
+		if members := metrics.FormatMemberStats(); members != "" {
+			metricsText += "\n\n" + members
+		}
+	}
+	instructions := prompt
+	if len(opts.FocusRepos) > 0 {
+		instructions = focusDirective(opts.FocusRepos, opts.RepoAliases) + "\n\n" + instructions
+	}
+	if preamble := strings.TrimSpace(opts.Preamble); preamble != "" {
+		instructions = preamble + "\n\n" + instructions
+	}
+
+	// max_prompt_chars covers the whole message, so the activity gets what
+	// the instructions leave, and at least a character so it stays capped
+	if opts.MaxPromptChars > 0 {
+		used := utf8.RuneCountInString(instructions + "\n\n" + activityIntro)
+		opts.MaxPromptChars = max(opts.MaxPromptChars-used, 1)
+	}
+	activityData := github.FormatActivityForClaude(prs, issues, reviews, commits, commentedItems, discussions, metricsText, opts)
+
+	if useSystem {
+		return instructions, activityIntro + activityData
+	}
+	return "", instructions + "\n\n" + activityIntro + activityData
+}
+
+// focusDirective tells the model which repositories to emphasize, by the
+// labels the activity shows them under, from aliases
+func focusDirective(focusRepos []string, aliases map[string]string) string {
+	labels := make([]string, len(focusRepos))
+	for i, repo := range focusRepos {
+		labels[i] = github.RepoLabel(aliases, repo)
+	}
+	return fmt.Sprintf("Focus repositories: %s. Prioritize and expand on my work in these repositories, "+
+		"and summarize contributions to other repositories briefly.", strings.Join(labels, ", "))
+}
+
+// GenerateReportCmd wraps any Provider in a bubbletea Cmd. Cancelling ctx
+// stops generation early.
 func GenerateReportCmd(
+	ctx context.Context,
 	provider Provider,
+	redactor *Redactor,
 	prs []github.PullRequest,
 	issues []github.Issue,
 	reviews []github.Review,
 	commits []github.Commit,
 	commentedItems []github.CommentedItem,
+	discussions []github.Discussion,
 	metrics *analytics.Metrics,
 	prompt string,
+	opts github.FormatOptions,
+	useSystem bool,
 ) tea.Cmd {
 	return func() tea.Msg {
-		metricsText := ""
-		if metrics != nil {
-			metricsText = metrics.Format()
-		}
-		activityData := github.FormatActivityForClaude(prs, issues, reviews, commits, commentedItems, metricsText)
-		userMessage := fmt.Sprintf("%s\n\nHere is my GitHub activity data:\n\n%s", prompt, activityData)
+		systemPrompt, userMessage := BuildMessages(prs, issues, reviews, commits, commentedItems, discussions, metrics, prompt, opts, useSystem)
+		return SendMessageCmd(ctx, provider, redactor, systemPrompt, userMessage)()
+	}
+}
 
-		report, err := provider.GenerateReport(context.Background(), userMessage)
+// SendMessageCmd redacts the user message and sends already assembled
+// messages to the provider. When ctx is cancelled, the message reports the
+// cancellation with the partial report instead of an error.
+func SendMessageCmd(ctx context.Context, provider Provider, redactor *Redactor, systemPrompt, userMessage string) tea.Cmd {
+	return func() tea.Msg {
+		userMessage, redactions := redactor.Redact(userMessage)
+		report, usage, err := provider.GenerateReport(ctx, systemPrompt, userMessage)
+		if ctx.Err() != nil {
+			return ReportGeneratedMsg{
+				Report:     report,
+				Redactions: redactions,
+				Usage:      usage,
+				Cancelled:  true,
+			}
+		}
 		return ReportGeneratedMsg{
-			Report: report,
-			Error:  err,
+			Report:     report,
+			Error:      err,
+			Redactions: redactions,
+			Usage:      usage,
 		}
 	}
 }
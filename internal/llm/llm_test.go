@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/burritocatai/activitycat/internal/github"
+)
+
+func TestBuildMessagesFocusRepos(t *testing.T) {
+	created := time.Date(2025, time.March, 3, 0, 0, 0, 0, time.UTC)
+	prs := []github.PullRequest{
+		{Number: 1, Title: "Fix docs typo", State: "open", CreatedAt: created,
+			Repository: github.Repository{Name: "docs", NameWithOwner: "acme/docs"}},
+		{Number: 2, Title: "Add caching", State: "open", CreatedAt: created,
+			Repository: github.Repository{Name: "web", NameWithOwner: "acme/web"}},
+	}
+	opts := github.FormatOptions{IncludePRs: true, FocusRepos: []string{"acme/web"}}
+
+	_, userMessage := BuildMessages(prs, nil, nil, nil, nil, nil, nil, "Summarize my week.", opts, false)
+
+	directive := "Focus repositories: acme/web."
+	if !strings.HasPrefix(userMessage, directive) {
+		t.Errorf("user message does not start with the focus directive:\n%s", userMessage)
+	}
+	if !strings.Contains(userMessage, "\n\nSummarize my week.") {
+		t.Errorf("the prompt should follow the focus directive:\n%s", userMessage)
+	}
+	focused, other := strings.Index(userMessage, "Add caching"), strings.Index(userMessage, "Fix docs typo")
+	if focused < 0 || other < 0 || focused > other {
+		t.Errorf("the acme/web PR should be listed before the acme/docs PR:\n%s", userMessage)
+	}
+}
+
+func TestBuildMessagesFocusRepoAlias(t *testing.T) {
+	prs := []github.PullRequest{{Number: 2, Title: "Add caching", State: "open", CreatedAt: time.Date(2025, time.March, 3, 0, 0, 0, 0, time.UTC),
+		Repository: github.Repository{Name: "web", NameWithOwner: "acme/web"}}}
+	opts := github.FormatOptions{IncludePRs: true, FocusRepos: []string{"acme/web"}, RepoAliases: map[string]string{"Acme/Web": "Web app"}}
+
+	_, userMessage := BuildMessages(prs, nil, nil, nil, nil, nil, nil, "Summarize my week.", opts, false)
+	if !strings.HasPrefix(userMessage, "Focus repositories: Web app.") {
+		t.Errorf("focus directive does not name the repo by its alias:\n%s", userMessage)
+	}
+	if !strings.Contains(userMessage, "- Repository: Web app\n") || strings.Contains(userMessage, "acme/web") {
+		t.Errorf("activity should show the alias in place of acme/web:\n%s", userMessage)
+	}
+}
+
+func TestBuildMessagesNoFocusRepos(t *testing.T) {
+	_, userMessage := BuildMessages(nil, nil, nil, nil, nil, nil, nil, "Summarize my week.", github.FormatOptions{}, false)
+	if strings.Contains(userMessage, "Focus repositories") {
+		t.Errorf("user message has a focus directive without focus repos:\n%s", userMessage)
+	}
+}
+
+func TestBuildMessagesPreamble(t *testing.T) {
+	opts := github.FormatOptions{Preamble: " Write in first person. \n", FocusRepos: []string{"acme/web"}}
+
+	_, userMessage := BuildMessages(nil, nil, nil, nil, nil, nil, nil, "Summarize my week.", opts, false)
+
+	if !strings.HasPrefix(userMessage, "Write in first person.\n\nFocus repositories: acme/web.") {
+		t.Errorf("user message should start with the preamble, then the focus directive:\n%s", userMessage)
+	}
+	preamble, prompt := strings.Index(userMessage, "Write in first person."), strings.Index(userMessage, "Summarize my week.")
+	if prompt < 0 || preamble > prompt {
+		t.Errorf("the preamble should come before the prompt, not replace it:\n%s", userMessage)
+	}
+}
+
+func TestBuildMessagesMaxPromptChars(t *testing.T) {
+	created := time.Date(2025, time.March, 3, 0, 0, 0, 0, time.UTC)
+	var prs []github.PullRequest
+	for i := range 500 {
+		prs = append(prs, github.PullRequest{Number: i + 1, Title: "Add caching", State: "open", CreatedAt: created,
+			Body: strings.Repeat("Lots of detail. ", 25), Repository: github.Repository{Name: "web", NameWithOwner: "acme/web"}})
+	}
+	opts := github.DefaultFormatOptions()
+	opts.MaxPromptChars = 8000
+	opts.Preamble = strings.Repeat("Write in first person. ", 50)
+	opts.FocusRepos = []string{"acme/web"}
+	prompt := strings.Repeat("Summarize my week. ", 50)
+
+	for _, useSystem := range []bool{false, true} {
+		systemPrompt, userMessage := BuildMessages(prs, nil, nil, nil, nil, nil, nil, prompt, opts, useSystem)
+		n := utf8.RuneCountInString(systemPrompt + userMessage)
+		if n > opts.MaxPromptChars {
+			t.Errorf("useSystem %v: sent %d characters, want at most max_prompt_chars %d", useSystem, n, opts.MaxPromptChars)
+		}
+		if !strings.Contains(userMessage, "[Truncated to fit max_prompt_chars") {
+			t.Errorf("useSystem %v: user message is missing the truncation note", useSystem)
+		}
+	}
+}
+
+func TestReportProgress(t *testing.T) {
+	// Without a progress function, nothing happens
+	reportProgress(context.Background(), 10, 100)
+
+	var got []float64
+	ctx := WithProgress(context.Background(), func(fraction float64) { got = append(got, fraction) })
+	reportProgress(ctx, 25, 100)
+	reportProgress(ctx, 150, 100)
+	if len(got) != 2 || got[0] != 0.25 || got[1] != 1 {
+		t.Errorf("reported %v, want [0.25 1]", got)
+	}
+}
+
+func TestBuildMessagesUseSystem(t *testing.T) {
+	system, user := BuildMessages(nil, nil, nil, nil, nil, nil, nil, "Summarize my week.", github.FormatOptions{}, true)
+	if system != "Summarize my week." {
+		t.Errorf("system prompt = %q, want the prompt", system)
+	}
+	if strings.Contains(user, "Summarize my week.") || !strings.HasPrefix(user, "Here is my GitHub activity data:") {
+		t.Errorf("user message should hold only the activity:\n%s", user)
+	}
+
+	system, user = BuildMessages(nil, nil, nil, nil, nil, nil, nil, "Summarize my week.", github.FormatOptions{}, false)
+	if system != "" {
+		t.Errorf("system prompt = %q without useSystem, want none", system)
+	}
+	if !strings.HasPrefix(user, "Summarize my week.\n\nHere is my GitHub activity data:") {
+		t.Errorf("user message should start with the prompt:\n%s", user)
+	}
+}
@@ -7,23 +7,90 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 )
 
 // OllamaProvider implements Provider using the Ollama HTTP API.
 type OllamaProvider struct {
-	host  string
-	model string
+	host   string
+	model  string
+	opts   OllamaOptions
+	client *http.Client
 }
 
+// OllamaOptions holds optional Ollama connection and generation settings
+type OllamaOptions struct {
+	// APIKey is sent as a bearer token and Headers are added to every
+	// request, for servers behind an authenticating reverse proxy
+	APIKey  string
+	Headers map[string]string
+
+	// KeepAlive is how long the model stays loaded, e.g. "10m"
+	KeepAlive string
+	// NumCtx sets the context window size in tokens, the model default if zero
+	NumCtx int
+	// Temperature overrides the model's sampling temperature if set
+	Temperature *float64
+
+	// Timeout abandons a request that takes longer, none if zero
+	Timeout time.Duration
+
+	// Attempts is how many times a chat that comes back empty, as it can
+	// while the model is still loading, is sent before giving up.
+	// DefaultOllamaAttempts if zero.
+	Attempts int
+}
+
+// DefaultOllamaAttempts allows two retries of an empty response
+const DefaultOllamaAttempts = 3
+
+// ollamaRetryDelay is the pause before retrying an empty response
+var ollamaRetryDelay = 2 * time.Second
+
 // NewOllamaProvider creates an OllamaProvider with the given host and model.
-func NewOllamaProvider(host, model string) *OllamaProvider {
-	return &OllamaProvider{host: host, model: model}
+func NewOllamaProvider(host, model string, opts OllamaOptions) *OllamaProvider {
+	return &OllamaProvider{
+		host:   host,
+		model:  model,
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+	}
+}
+
+// setHeaders adds the configured auth and extra headers to req
+func (o *OllamaProvider) setHeaders(req *http.Request) {
+	for k, v := range o.opts.Headers {
+		req.Header.Set(k, v)
+	}
+	if o.opts.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.opts.APIKey)
+	}
+}
+
+// modelOptions returns the request's "options" object, or nil if no
+// generation settings are configured
+func (o *OllamaProvider) modelOptions() map[string]any {
+	options := make(map[string]any)
+	if o.opts.NumCtx > 0 {
+		options["num_ctx"] = o.opts.NumCtx
+	}
+	if o.opts.Temperature != nil {
+		options["temperature"] = *o.opts.Temperature
+	}
+	if len(options) == 0 {
+		return nil
+	}
+	return options
 }
 
 type ollamaChatRequest struct {
-	Model    string          `json:"model"`
-	Messages []ollamaMessage `json:"messages"`
-	Stream   bool            `json:"stream"`
+	Model     string          `json:"model"`
+	Messages  []ollamaMessage `json:"messages"`
+	Stream    bool            `json:"stream"`
+	KeepAlive string          `json:"keep_alive,omitempty"`
+	Options   map[string]any  `json:"options,omitempty"`
 }
 
 type ollamaMessage struct {
@@ -31,46 +98,187 @@ type ollamaMessage struct {
 	Content string `json:"content"`
 }
 
+// ollamaChatResponse is one line of a streamed chat reply. The last line
+// has Done set and the token counts.
 type ollamaChatResponse struct {
-	Message ollamaMessage `json:"message"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	Error           string        `json:"error"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+}
+
+type ollamaGenerateRequest struct {
+	Model     string `json:"model"`
+	KeepAlive string `json:"keep_alive,omitempty"`
+}
+
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+// CheckModel verifies that the Ollama server is reachable and has the model pulled.
+func (o *OllamaProvider) CheckModel(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.host+"/api/tags", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create Ollama request: %w", err)
+	}
+	o.setHeaders(req)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach Ollama at %s (is it running?): %w", o.host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var tags ollamaTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return fmt.Errorf("failed to decode Ollama response: %w", err)
+	}
+
+	var available []string
+	for _, m := range tags.Models {
+		available = append(available, m.Name)
+	}
+	// Ollama reports untagged models with an implicit ":latest" suffix
+	if slices.Contains(available, o.model) || slices.Contains(available, o.model+":latest") {
+		return nil
+	}
+	if len(available) == 0 {
+		return fmt.Errorf("Ollama model %q is not pulled and no models are available (run 'ollama pull %s')", o.model, o.model)
+	}
+	return fmt.Errorf("Ollama model %q is not pulled (available models: %s)", o.model, strings.Join(available, ", "))
 }
 
-// GenerateReport sends the user message to an Ollama model and returns the response.
-func (o *OllamaProvider) GenerateReport(ctx context.Context, userMessage string) (string, error) {
+// GenerateReport sends the messages to an Ollama model and returns the
+// streamed response. A model that isn't loaded yet can answer with an empty message,
+// so an empty response loads the model and is retried up to Attempts times.
+func (o *OllamaProvider) GenerateReport(ctx context.Context, systemPrompt, userMessage string) (string, Usage, error) {
+	var messages []ollamaMessage
+	if systemPrompt != "" {
+		messages = append(messages, ollamaMessage{Role: "system", Content: systemPrompt})
+	}
 	reqBody := ollamaChatRequest{
-		Model: o.model,
-		Messages: []ollamaMessage{
-			{Role: "user", Content: userMessage},
-		},
-		Stream: false,
+		Model:     o.model,
+		Messages:  append(messages, ollamaMessage{Role: "user", Content: userMessage}),
+		Stream:    true,
+		KeepAlive: o.opts.KeepAlive,
+		Options:   o.modelOptions(),
+	}
+
+	attempts := o.opts.Attempts
+	if attempts <= 0 {
+		attempts = DefaultOllamaAttempts
+	}
+
+	var total Usage
+	for attempt := 1; ; attempt++ {
+		content, usage, err := o.chat(ctx, reqBody)
+		total.InputTokens += usage.InputTokens
+		total.OutputTokens += usage.OutputTokens
+		if err != nil || strings.TrimSpace(content) != "" {
+			return content, total, err
+		}
+		if attempt == attempts {
+			return "", total, fmt.Errorf("Ollama returned an empty response %d times (the model %q may still be loading)", attempts, o.model)
+		}
+
+		// Make sure the model is loaded, then give it a moment before retrying
+		if err := o.load(ctx); err != nil {
+			return "", total, err
+		}
+		select {
+		case <-ctx.Done():
+			return "", total, ctx.Err()
+		case <-time.After(ollamaRetryDelay):
+		}
 	}
+}
 
+// chat sends a single chat request and returns the streamed reply, or as
+// much of it as arrived before an error
+func (o *OllamaProvider) chat(ctx context.Context, reqBody ollamaChatRequest) (string, Usage, error) {
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal Ollama request: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to marshal Ollama request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.host+"/api/chat", bytes.NewReader(bodyBytes))
 	if err != nil {
-		return "", fmt.Errorf("failed to create Ollama request: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to create Ollama request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	o.setHeaders(req)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := o.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("Ollama API error: %w", err)
+		return "", Usage{}, fmt.Errorf("Ollama API error: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(respBody))
+		return "", Usage{}, fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var (
+		sb    strings.Builder
+		usage Usage
+	)
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var chunk ollamaChatResponse
+		if err := dec.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				return sb.String(), usage, nil
+			}
+			if ctx.Err() != nil {
+				return sb.String(), usage, fmt.Errorf("Ollama API error: %w", ctx.Err())
+			}
+			return sb.String(), usage, fmt.Errorf("failed to decode Ollama response: %w", err)
+		}
+		if chunk.Error != "" {
+			return sb.String(), usage, fmt.Errorf("Ollama API error: %s", chunk.Error)
+		}
+		sb.WriteString(chunk.Message.Content)
+		if chunk.Done {
+			usage = Usage{InputTokens: chunk.PromptEvalCount, OutputTokens: chunk.EvalCount}
+			return sb.String(), usage, nil
+		}
 	}
+}
 
-	var chatResp ollamaChatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		return "", fmt.Errorf("failed to decode Ollama response: %w", err)
+// load asks Ollama to load the model into memory, which a generate request
+// with no prompt does, and waits until it has
+func (o *OllamaProvider) load(ctx context.Context) error {
+	bodyBytes, err := json.Marshal(ollamaGenerateRequest{Model: o.model, KeepAlive: o.opts.KeepAlive})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Ollama request: %w", err)
 	}
 
-	return chatResp.Message.Content, nil
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.host+"/api/generate", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("failed to create Ollama request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	o.setHeaders(req)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Ollama API error: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Ollama could not load model %q: status %d", o.model, resp.StatusCode)
+	}
+	return nil
 }
@@ -0,0 +1,203 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// tagsServer serves /api/tags listing the given models
+func tagsServer(t *testing.T, models ...string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			http.NotFound(w, r)
+			return
+		}
+		var entries []string
+		for _, m := range models {
+			entries = append(entries, `{"name":"`+m+`"}`)
+		}
+		w.Write([]byte(`{"models":[` + strings.Join(entries, ",") + `]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCheckModel(t *testing.T) {
+	srv := tagsServer(t, "llama3:latest", "mistral:7b")
+
+	if err := NewOllamaProvider(srv.URL, "llama3", OllamaOptions{}).CheckModel(context.Background()); err != nil {
+		t.Errorf("CheckModel of a pulled model: %v", err)
+	}
+
+	err := NewOllamaProvider(srv.URL, "qwen2", OllamaOptions{}).CheckModel(context.Background())
+	if err == nil {
+		t.Fatal("CheckModel of a model that isn't pulled returned no error")
+	}
+	if !strings.Contains(err.Error(), "mistral:7b") {
+		t.Errorf("error %q doesn't list the available models", err)
+	}
+}
+
+func TestCheckModelNotRunning(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	host := srv.URL
+	srv.Close()
+
+	err := NewOllamaProvider(host, "llama3", OllamaOptions{}).CheckModel(context.Background())
+	if err == nil {
+		t.Fatal("CheckModel with no server running returned no error")
+	}
+	if !strings.Contains(err.Error(), "is it running?") {
+		t.Errorf("error %q doesn't suggest Ollama isn't running", err)
+	}
+}
+
+// chatServer serves /api/chat, replying with reply as a single streamed
+// line, and passes each chat request to inspect
+func chatServer(t *testing.T, reply string, inspect func(r *http.Request)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		inspect(r)
+		w.Write([]byte(`{"message":{"role":"assistant","content":"` + reply + `"},"done":true}` + "\n"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOllamaAuthHeaders(t *testing.T) {
+	var header http.Header
+	srv := chatServer(t, "# Report", func(r *http.Request) { header = r.Header.Clone() })
+
+	opts := OllamaOptions{APIKey: "secret", Headers: map[string]string{"X-Team": "platform"}}
+	if _, _, err := NewOllamaProvider(srv.URL, "llama3", opts).GenerateReport(context.Background(), "", "Summarize"); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if got := header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+	}
+	if got := header.Get("X-Team"); got != "platform" {
+		t.Errorf("X-Team = %q, want %q", got, "platform")
+	}
+
+	if _, _, err := NewOllamaProvider(srv.URL, "llama3", OllamaOptions{}).GenerateReport(context.Background(), "", "Summarize"); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if got := header.Get("Authorization"); got != "" {
+		t.Errorf("Authorization = %q without an API key, want none", got)
+	}
+}
+
+func TestOllamaSystemPrompt(t *testing.T) {
+	var req ollamaChatRequest
+	srv := chatServer(t, "# Report", func(r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+	})
+	provider := NewOllamaProvider(srv.URL, "llama3", OllamaOptions{})
+
+	if _, _, err := provider.GenerateReport(context.Background(), "Summarize my week.", "activity"); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	want := []ollamaMessage{{Role: "system", Content: "Summarize my week."}, {Role: "user", Content: "activity"}}
+	if len(req.Messages) != 2 || req.Messages[0] != want[0] || req.Messages[1] != want[1] {
+		t.Errorf("messages = %+v, want %+v", req.Messages, want)
+	}
+
+	if _, _, err := provider.GenerateReport(context.Background(), "", "prompt and activity"); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if len(req.Messages) != 1 || req.Messages[0].Role != "user" {
+		t.Errorf("messages = %+v with no system prompt, want a single user message", req.Messages)
+	}
+}
+
+func TestOllamaOptions(t *testing.T) {
+	var body map[string]any
+	srv := chatServer(t, "# Report", func(r *http.Request) {
+		body = nil
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+	})
+
+	temperature := 0.2
+	opts := OllamaOptions{KeepAlive: "10m", NumCtx: 32768, Temperature: &temperature}
+	if _, _, err := NewOllamaProvider(srv.URL, "llama3", opts).GenerateReport(context.Background(), "", "Summarize"); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	options, ok := body["options"].(map[string]any)
+	if !ok {
+		t.Fatalf("request has no options: %v", body)
+	}
+	if options["num_ctx"] != float64(32768) {
+		t.Errorf("options.num_ctx = %v, want 32768", options["num_ctx"])
+	}
+	if options["temperature"] != 0.2 {
+		t.Errorf("options.temperature = %v, want 0.2", options["temperature"])
+	}
+	if body["keep_alive"] != "10m" {
+		t.Errorf("keep_alive = %v, want 10m", body["keep_alive"])
+	}
+
+	if _, _, err := NewOllamaProvider(srv.URL, "llama3", OllamaOptions{}).GenerateReport(context.Background(), "", "Summarize"); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if _, ok := body["options"]; ok {
+		t.Errorf("options = %v when none are configured, want them left out", body["options"])
+	}
+	if _, ok := body["keep_alive"]; ok {
+		t.Errorf("keep_alive = %v when not configured, want it left out", body["keep_alive"])
+	}
+}
+
+func TestOllamaRetriesEmptyResponse(t *testing.T) {
+	delay := ollamaRetryDelay
+	ollamaRetryDelay = 0
+	t.Cleanup(func() { ollamaRetryDelay = delay })
+
+	// The first empty chats answer with nothing, as while the model loads
+	var chats, loads int
+	empty := 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/generate":
+			loads++
+			w.Write([]byte(`{"done":true}`))
+		case "/api/chat":
+			chats++
+			content := "# Report"
+			if chats <= empty {
+				content = ""
+			}
+			w.Write([]byte(`{"message":{"role":"assistant","content":"` + content + `"},"done":true}` + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	report, _, err := NewOllamaProvider(srv.URL, "llama3", OllamaOptions{}).GenerateReport(context.Background(), "", "Summarize")
+	if err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if report != "# Report" || chats != 2 || loads != 1 {
+		t.Errorf("GenerateReport = %q after %d chats and %d loads, want the report after 2 chats and 1 load", report, chats, loads)
+	}
+
+	// Every attempt coming back empty is an error
+	chats, empty = 0, 2
+	_, _, err = NewOllamaProvider(srv.URL, "llama3", OllamaOptions{Attempts: 2}).GenerateReport(context.Background(), "", "Summarize")
+	if err == nil || !strings.Contains(err.Error(), "empty response 2 times") {
+		t.Errorf("error = %v, want one saying both attempts were empty", err)
+	}
+}
@@ -1,35 +1,52 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 )
 
 // OpenAIProvider implements Provider using an OpenAI-compatible chat completions API.
 type OpenAIProvider struct {
 	baseURL string
 	apiKey  string
+	keyFile string
 	model   string
+	client  *http.Client
+
+	// noStreamOptions is set once the endpoint has rejected stream_options
+	noStreamOptions bool
 }
 
 // NewOpenAIProvider creates an OpenAIProvider with the given base URL, API key, and model.
-func NewOpenAIProvider(baseURL, apiKey, model string) *OpenAIProvider {
+// Without an API key it falls back to OPENAI_API_KEY, then to the key file.
+// Requests are abandoned after timeout, unless it is zero.
+func NewOpenAIProvider(baseURL, apiKey, keyFile, model string, timeout time.Duration) *OpenAIProvider {
 	return &OpenAIProvider{
 		baseURL: strings.TrimRight(baseURL, "/"),
 		apiKey:  apiKey,
+		keyFile: keyFile,
 		model:   model,
+		client:  &http.Client{Timeout: timeout},
 	}
 }
 
 type openAIChatRequest struct {
-	Model    string          `json:"model"`
-	Messages []openAIMessage `json:"messages"`
+	Model         string               `json:"model"`
+	Messages      []openAIMessage      `json:"messages"`
+	Stream        bool                 `json:"stream"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type openAIMessage struct {
@@ -37,60 +54,161 @@ type openAIMessage struct {
 	Content string `json:"content"`
 }
 
-type openAIChatResponse struct {
+// openAIChatChunk is one server-sent event of a streamed reply. Usage is
+// only set on the last one.
+type openAIChatChunk struct {
 	Choices []openAIChoice `json:"choices"`
+	Usage   *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
 }
 
 type openAIChoice struct {
-	Message openAIMessage `json:"message"`
+	Delta openAIMessage `json:"delta"`
 }
 
-// GenerateReport sends the user message to an OpenAI-compatible endpoint and returns the response.
-func (o *OpenAIProvider) GenerateReport(ctx context.Context, userMessage string) (string, error) {
+// openAIChatResponse is a reply sent whole, by an endpoint that doesn't stream
+type openAIChatResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// GenerateReport sends the messages to an OpenAI-compatible endpoint and returns the streamed response.
+// An endpoint that rejects stream_options is asked again without it, and one
+// that answers with a single JSON response rather than events is read as one.
+func (o *OpenAIProvider) GenerateReport(ctx context.Context, systemPrompt, userMessage string) (string, Usage, error) {
+	var messages []openAIMessage
+	if systemPrompt != "" {
+		messages = append(messages, openAIMessage{Role: "system", Content: systemPrompt})
+	}
 	reqBody := openAIChatRequest{
-		Model: o.model,
-		Messages: []openAIMessage{
-			{Role: "user", Content: userMessage},
-		},
+		Model:    o.model,
+		Messages: append(messages, openAIMessage{Role: "user", Content: userMessage}),
+		Stream:   true,
+	}
+	if !o.noStreamOptions {
+		reqBody.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+
+	resp, err := o.send(ctx, reqBody)
+	if err != nil {
+		return "", Usage{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest && reqBody.StreamOptions != nil {
+		respBody, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(string(respBody), "stream_options") {
+			return "", Usage{}, fmt.Errorf("OpenAI API returned status %d: %s", resp.StatusCode, string(respBody))
+		}
+		// Older servers don't know stream_options; usage is then unknown
+		o.noStreamOptions = true
+		reqBody.StreamOptions = nil
+		if resp, err = o.send(ctx, reqBody); err != nil {
+			return "", Usage{}, err
+		}
+		defer resp.Body.Close()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", Usage{}, fmt.Errorf("OpenAI API returned status %d: %s", resp.StatusCode, string(respBody))
 	}
 
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/event-stream" {
+		return readOpenAIResponse(resp.Body)
+	}
+	return readOpenAIStream(resp.Body)
+}
+
+// send posts reqBody to the chat completions endpoint
+func (o *OpenAIProvider) send(ctx context.Context, reqBody openAIChatRequest) (*http.Response, error) {
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal OpenAI request: %w", err)
+		return nil, fmt.Errorf("failed to marshal OpenAI request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/v1/chat/completions", bytes.NewReader(bodyBytes))
 	if err != nil {
-		return "", fmt.Errorf("failed to create OpenAI request: %w", err)
+		return nil, fmt.Errorf("failed to create OpenAI request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	apiKey := o.apiKey
 	if apiKey == "" {
-		apiKey = os.Getenv("OPENAI_API_KEY")
+		if apiKey, err = resolveAPIKey("OPENAI_API_KEY", "OPENAI_API_KEY_FILE", o.keyFile); err != nil {
+			return nil, err
+		}
 	}
 	if apiKey != "" {
 		req.Header.Set("Authorization", "Bearer "+apiKey)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := o.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("OpenAI API error: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("OpenAI API returned status %d: %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("OpenAI API error: %w", err)
 	}
+	return resp, nil
+}
 
+// readOpenAIResponse reads a reply sent as a single JSON response, from an
+// endpoint that ignores the request to stream
+func readOpenAIResponse(body io.Reader) (string, Usage, error) {
 	var chatResp openAIChatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		return "", fmt.Errorf("failed to decode OpenAI response: %w", err)
+	if err := json.NewDecoder(body).Decode(&chatResp); err != nil {
+		return "", Usage{}, fmt.Errorf("failed to decode OpenAI response: %w", err)
 	}
-
+	usage := Usage{InputTokens: chatResp.Usage.PromptTokens, OutputTokens: chatResp.Usage.CompletionTokens}
 	if len(chatResp.Choices) == 0 {
-		return "", fmt.Errorf("no choices in OpenAI API response")
+		return "", usage, fmt.Errorf("no choices in OpenAI API response")
+	}
+	return chatResp.Choices[0].Message.Content, usage, nil
+}
+
+// readOpenAIStream reads a reply sent as server-sent events, returning the
+// text received so far if the stream is cut short
+func readOpenAIStream(body io.Reader) (string, Usage, error) {
+	var (
+		sb       strings.Builder
+		usage    Usage
+		received bool
+	)
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openAIChatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return sb.String(), usage, fmt.Errorf("failed to decode OpenAI response: %w", err)
+		}
+		for _, choice := range chunk.Choices {
+			received = true
+			sb.WriteString(choice.Delta.Content)
+		}
+		if chunk.Usage != nil {
+			usage = Usage{InputTokens: chunk.Usage.PromptTokens, OutputTokens: chunk.Usage.CompletionTokens}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return sb.String(), usage, fmt.Errorf("OpenAI API error: %w", err)
+	}
+	if !received {
+		return "", usage, fmt.Errorf("no choices in OpenAI API response")
 	}
 
-	return chatResp.Choices[0].Message.Content, nil
+	return sb.String(), usage, nil
 }
@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// openAIStream is a streamed chat completion with the text "# Report"
+const openAIStream = `data: {"choices":[{"delta":{"role":"assistant","content":"# Rep"}}]}
+
+data: {"choices":[{"delta":{"content":"ort"}}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":3}}
+
+data: [DONE]
+
+`
+
+// openAIServer serves chat completions, streaming openAIStream, and passes
+// each decoded request to inspect
+func openAIServer(t *testing.T, inspect func(req openAIChatRequest)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		var req openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		inspect(req)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(openAIStream))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenAISystemPrompt(t *testing.T) {
+	var req openAIChatRequest
+	srv := openAIServer(t, func(r openAIChatRequest) { req = r })
+	provider := NewOpenAIProvider(srv.URL, "test-key", "", "gpt-4o", 0)
+
+	report, usage, err := provider.GenerateReport(context.Background(), "Summarize my week.", "activity")
+	if err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if report != "# Report" || usage.InputTokens != 12 || usage.OutputTokens != 3 {
+		t.Errorf("GenerateReport = %q, %+v, want the streamed report and usage", report, usage)
+	}
+	want := []openAIMessage{{Role: "system", Content: "Summarize my week."}, {Role: "user", Content: "activity"}}
+	if len(req.Messages) != 2 || req.Messages[0] != want[0] || req.Messages[1] != want[1] {
+		t.Errorf("messages = %+v, want %+v", req.Messages, want)
+	}
+
+	if _, _, err := provider.GenerateReport(context.Background(), "", "prompt and activity"); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if len(req.Messages) != 1 || req.Messages[0].Role != "user" {
+		t.Errorf("messages = %+v with no system prompt, want a single user message", req.Messages)
+	}
+}
+
+func TestOpenAINonStreamingResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"# Report"}}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`))
+	}))
+	t.Cleanup(srv.Close)
+	provider := NewOpenAIProvider(srv.URL, "test-key", "", "gpt-4o", 0)
+
+	report, usage, err := provider.GenerateReport(context.Background(), "", "activity")
+	if err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	if report != "# Report" || usage.InputTokens != 12 || usage.OutputTokens != 3 {
+		t.Errorf("GenerateReport = %q, %+v, want the whole report and usage", report, usage)
+	}
+}
+
+func TestOpenAIStreamOptionsRejected(t *testing.T) {
+	var withOptions []bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		withOptions = append(withOptions, req.StreamOptions != nil)
+		if req.StreamOptions != nil {
+			http.Error(w, `{"error":"unknown field stream_options"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(openAIStream))
+	}))
+	t.Cleanup(srv.Close)
+	provider := NewOpenAIProvider(srv.URL, "test-key", "", "gpt-4o", 0)
+
+	for range 2 {
+		report, _, err := provider.GenerateReport(context.Background(), "", "activity")
+		if err != nil {
+			t.Fatalf("GenerateReport: %v", err)
+		}
+		if report != "# Report" {
+			t.Errorf("GenerateReport = %q, want the streamed report", report)
+		}
+	}
+	// Asked again without stream_options, which later requests leave out
+	if want := []bool{true, false, false}; len(withOptions) != len(want) || withOptions[0] != want[0] || withOptions[1] != want[1] || withOptions[2] != want[2] {
+		t.Errorf("requests sent stream_options %v, want %v", withOptions, want)
+	}
+}
@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// redactionRule replaces matches of pattern with replacement
+type redactionRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// Redactor masks text matching configured patterns before it is sent to a
+// provider. A nil Redactor leaves text unchanged.
+type Redactor struct {
+	rules []redactionRule
+}
+
+// NewRedactor compiles rules mapping regular expressions to replacements.
+// Rules are applied in pattern order so results don't depend on map order.
+// Replacements may refer to capture groups as $1 or ${name}.
+func NewRedactor(rules map[string]string) (*Redactor, error) {
+	patterns := make([]string, 0, len(rules))
+	for p := range rules {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+
+	r := &Redactor{}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		r.rules = append(r.rules, redactionRule{pattern: re, replacement: rules[p]})
+	}
+	return r, nil
+}
+
+// Redact applies every rule to s and returns the result with the number of
+// substitutions made
+func (r *Redactor) Redact(s string) (string, int) {
+	if r == nil {
+		return s, 0
+	}
+	count := 0
+	for _, rule := range r.rules {
+		count += len(rule.pattern.FindAllStringIndex(s, -1))
+		s = rule.pattern.ReplaceAllString(s, rule.replacement)
+	}
+	return s, count
+}
@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// recordingProvider records the user message it is sent
+type recordingProvider struct {
+	userMessage string
+}
+
+func (p *recordingProvider) GenerateReport(ctx context.Context, systemPrompt, userMessage string) (string, Usage, error) {
+	p.userMessage = userMessage
+	return "report", Usage{}, nil
+}
+
+func TestRedactInternalURL(t *testing.T) {
+	redactor, err := NewRedactor(map[string]string{
+		`https?://[a-z0-9.-]+\.corp\.example\.com\S*`: "[internal link]",
+	})
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+	provider := &recordingProvider{}
+	message := "Fixes https://jira.corp.example.com/browse/OPS-12 and see https://wiki.corp.example.com/x.\nPublic: https://github.com/acme/web"
+
+	msg := SendMessageCmd(context.Background(), provider, redactor, "", message)().(ReportGeneratedMsg)
+
+	if msg.Redactions != 2 {
+		t.Errorf("Redactions = %d, want 2", msg.Redactions)
+	}
+	if strings.Contains(provider.userMessage, "corp.example.com") {
+		t.Errorf("internal URL was sent to the provider:\n%s", provider.userMessage)
+	}
+	if !strings.Contains(provider.userMessage, "Fixes [internal link] and") {
+		t.Errorf("internal URL wasn't replaced:\n%s", provider.userMessage)
+	}
+	if !strings.Contains(provider.userMessage, "https://github.com/acme/web") {
+		t.Errorf("public URL was redacted:\n%s", provider.userMessage)
+	}
+}
+
+func TestNewRedactorInvalidPattern(t *testing.T) {
+	_, err := NewRedactor(map[string]string{`ticket-(\d+`: "ticket"})
+	if err == nil {
+		t.Fatal("NewRedactor with an unclosed group returned no error")
+	}
+	if !strings.Contains(err.Error(), `ticket-(\d+`) {
+		t.Errorf("error %q doesn't name the pattern", err)
+	}
+}
+
+func TestNilRedactor(t *testing.T) {
+	var r *Redactor
+	if got, n := r.Redact("unchanged"); got != "unchanged" || n != 0 {
+		t.Errorf("Redact = %q, %d, want the text unchanged", got, n)
+	}
+}
@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stalledServer accepts requests and never answers until the client gives up
+func stalledServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The request is only seen to be abandoned once its body is read
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRequestTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	srv := stalledServer(t)
+	t.Setenv("ANTHROPIC_BASE_URL", srv.URL)
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+
+	providers := map[string]Provider{
+		"ollama": NewOllamaProvider(srv.URL, "llama3", OllamaOptions{Timeout: timeout}),
+		"openai": NewOpenAIProvider(srv.URL, "test-key", "", "gpt-4o", timeout),
+		"claude": NewClaudeProvider("claude-sonnet-4-5", "", "", timeout),
+	}
+	for name, provider := range providers {
+		start := time.Now()
+		_, _, err := provider.GenerateReport(context.Background(), "", "Summarize my week.")
+		var netErr net.Error
+		if err == nil || !(errors.As(err, &netErr) && netErr.Timeout() || errors.Is(err, context.DeadlineExceeded)) {
+			t.Errorf("%s: error = %v, want a timeout", name, err)
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("%s: gave up after %v, want the %v timeout to stop the request", name, elapsed, timeout)
+		}
+	}
+	if http.DefaultClient.Timeout != 0 {
+		t.Errorf("http.DefaultClient.Timeout = %v, want it left alone", http.DefaultClient.Timeout)
+	}
+}
@@ -0,0 +1,123 @@
+package dateselect
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/keys"
+	"github.com/burritocatai/activitycat/internal/ui/styles"
+)
+
+// calendar picks a start and end day from a month grid. Days are midnight
+// UTC, like dates parsed by daterange.Parse.
+type calendar struct {
+	cursor time.Time
+	start  *time.Time // picked start day, nil until chosen
+}
+
+// rangeStyle highlights days between the picked start and the cursor
+var rangeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
+
+// newCalendar returns a calendar with the cursor on day
+func newCalendar(day time.Time) calendar {
+	return calendar{cursor: dateOf(day)}
+}
+
+// dateOf returns midnight UTC on t's calendar day
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// addMonths moves t by n months, keeping the day of the month where the
+// target month has it and using its last day otherwise, so Jan 31 plus one
+// month is Feb 28 (or 29) rather than early March
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	day := min(t.Day(), daysIn(first))
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// daysIn returns the number of days in t's month
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// pickedRange returns the range between the picked start and the cursor, in
+// order
+func (c calendar) pickedRange() daterange.Range {
+	start, end := *c.start, c.cursor
+	if end.Before(start) {
+		start, end = end, start
+	}
+	return daterange.Range{Start: start, End: end}
+}
+
+// update moves the cursor by a day, week, or month, and picks the start and
+// then the end day on Enter. It returns true along with the range once both
+// are picked.
+func (c calendar) update(msg tea.KeyMsg) (calendar, daterange.Range, bool) {
+	switch {
+	case msg.String() == "left" || msg.String() == "h":
+		c.cursor = c.cursor.AddDate(0, 0, -1)
+	case msg.String() == "right" || msg.String() == "l":
+		c.cursor = c.cursor.AddDate(0, 0, 1)
+	case key.Matches(msg, keys.Map.Up):
+		c.cursor = c.cursor.AddDate(0, 0, -7)
+	case key.Matches(msg, keys.Map.Down):
+		c.cursor = c.cursor.AddDate(0, 0, 7)
+	case key.Matches(msg, keys.Map.PageUp):
+		c.cursor = addMonths(c.cursor, -1)
+	case key.Matches(msg, keys.Map.PageDown):
+		c.cursor = addMonths(c.cursor, 1)
+	case msg.String() == "enter":
+		if c.start == nil {
+			start := c.cursor
+			c.start = &start
+			return c, daterange.Range{}, false
+		}
+		return c, c.pickedRange(), true
+	}
+	return c, daterange.Range{}, false
+}
+
+// view renders the cursor's month with the cursor and any picked range
+// highlighted
+func (c calendar) view() string {
+	var sb strings.Builder
+
+	first := time.Date(c.cursor.Year(), c.cursor.Month(), 1, 0, 0, 0, 0, time.UTC)
+	sb.WriteString(styles.SelectedStyle.Render(first.Format("January 2006")) + "\n")
+	sb.WriteString(styles.UnselectedStyle.Render("Su Mo Tu We Th Fr Sa") + "\n")
+
+	var picked daterange.Range
+	if c.start != nil {
+		picked = c.pickedRange()
+	}
+
+	line := strings.Repeat("   ", int(first.Weekday()))
+	for d := 1; d <= daysIn(first); d++ {
+		day := first.AddDate(0, 0, d-1)
+		cell := fmt.Sprintf("%2d", d)
+		switch {
+		case day.Equal(c.cursor):
+			cell = lipgloss.NewStyle().Reverse(true).Render(cell)
+		case c.start != nil && !day.Before(picked.Start) && !day.After(picked.End):
+			cell = rangeStyle.Render(cell)
+		}
+		line += cell
+		if day.Weekday() == time.Saturday || d == daysIn(first) {
+			sb.WriteString("  " + line + "\n")
+			line = ""
+		} else {
+			line += " "
+		}
+	}
+
+	return sb.String()
+}
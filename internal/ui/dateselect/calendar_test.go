@@ -0,0 +1,67 @@
+package dateselect
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// day returns midnight UTC on the given day
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCalendarNavigation(t *testing.T) {
+	left := tea.KeyMsg{Type: tea.KeyLeft}
+	right := tea.KeyMsg{Type: tea.KeyRight}
+	up := tea.KeyMsg{Type: tea.KeyUp}
+	pgUp := tea.KeyMsg{Type: tea.KeyPgUp}
+	pgDown := tea.KeyMsg{Type: tea.KeyPgDown}
+
+	tests := []struct {
+		name  string
+		from  time.Time
+		press tea.KeyMsg
+		want  time.Time
+	}{
+		{"left from the 1st", day(2025, time.March, 1), left, day(2025, time.February, 28)},
+		{"left from the 1st in a leap year", day(2024, time.March, 1), left, day(2024, time.February, 29)},
+		{"right from the last day", day(2025, time.January, 31), right, day(2025, time.February, 1)},
+		{"right from New Year's Eve", day(2024, time.December, 31), right, day(2025, time.January, 1)},
+		{"up a week across months", day(2025, time.March, 3), up, day(2025, time.February, 24)},
+		{"next month from the 31st", day(2025, time.January, 31), pgDown, day(2025, time.February, 28)},
+		{"next month from the 31st in a leap year", day(2024, time.January, 31), pgDown, day(2024, time.February, 29)},
+		{"previous month across years", day(2025, time.January, 15), pgUp, day(2024, time.December, 15)},
+	}
+	for _, tt := range tests {
+		c, _, done := newCalendar(tt.from).update(tt.press)
+		if done {
+			t.Errorf("%s: moving picked a range", tt.name)
+		}
+		if !c.cursor.Equal(tt.want) {
+			t.Errorf("%s: cursor = %s, want %s", tt.name, c.cursor.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestCalendarPicksRange(t *testing.T) {
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	c := newCalendar(day(2025, time.March, 10))
+
+	c, _, done := c.update(enter)
+	if done || c.start == nil {
+		t.Fatal("the first Enter didn't pick the start day")
+	}
+	// Picking an end before the start swaps them
+	for range 3 {
+		c, _, _ = c.update(tea.KeyMsg{Type: tea.KeyLeft})
+	}
+	_, r, done := c.update(enter)
+	if !done {
+		t.Fatal("the second Enter didn't pick the end day")
+	}
+	if !r.Start.Equal(day(2025, time.March, 7)) || !r.End.Equal(day(2025, time.March, 10)) {
+		t.Errorf("picked %s, want 2025-03-07 to 2025-03-10", r)
+	}
+}
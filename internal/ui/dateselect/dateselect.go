@@ -1,10 +1,15 @@
 package dateselect
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/keys"
 	"github.com/burritocatai/activitycat/internal/ui/styles"
 )
 
@@ -34,10 +39,20 @@ type Model struct {
 	startInput  textinput.Model
 	endInput    textinput.Model
 	inputError  string
+
+	// Calendar picking within custom mode, toggled with Tab
+	calendarMode bool
+	calendar     calendar
+
+	// dateFormat is the layout picked dates are shown in
+	dateFormat string
 }
 
-// New creates a new date selection model
-func New() Model {
+// New creates a new date selection model. defaultRange is a preset label or
+// range spec (see daterange.ParseSpec) to place the cursor on; specs that
+// aren't presets are added as an extra option. Picked dates are shown in
+// dateFormat, daterange.DefaultDateFormat if empty.
+func New(defaultRange, dateFormat string) Model {
 	si := textinput.New()
 	si.Placeholder = "YYYY-MM-DD"
 	si.CharLimit = 10
@@ -47,7 +62,7 @@ func New() Model {
 	ei.Placeholder = "YYYY-MM-DD"
 	ei.CharLimit = 10
 
-	return Model{
+	m := Model{
 		options: []Option{
 			{Label: "Last Week", Range: daterange.LastWeek()},
 			{Label: "Last Month", Range: daterange.LastMonth()},
@@ -58,7 +73,34 @@ func New() Model {
 		selected:   false,
 		startInput: si,
 		endInput:   ei,
+		dateFormat: dateFormat,
 	}
+	m.selectDefault(defaultRange)
+
+	return m
+}
+
+// selectDefault moves the cursor to the option matching spec, adding an
+// option before "Custom Range..." if no preset matches
+func (m *Model) selectDefault(spec string) {
+	if spec == "" {
+		return
+	}
+	for i, option := range m.options[:len(m.options)-1] {
+		if daterange.NormalizeSpec(option.Label) == daterange.NormalizeSpec(spec) {
+			m.cursor = i
+			return
+		}
+	}
+
+	r, err := daterange.ParseSpec(spec)
+	if err != nil {
+		return
+	}
+	custom := len(m.options) - 1
+	options := append([]Option{}, m.options[:custom]...)
+	m.options = append(options, Option{Label: spec, Range: r}, m.options[custom])
+	m.cursor = custom
 }
 
 // Init initializes the date selection model
@@ -78,16 +120,16 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 func (m Model) updatePreset(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "up", "k":
+		switch {
+		case key.Matches(msg, keys.Map.Up):
 			if m.cursor > 0 {
 				m.cursor--
 			}
-		case "down", "j":
+		case key.Matches(msg, keys.Map.Down):
 			if m.cursor < len(m.options)-1 {
 				m.cursor++
 			}
-		case "enter":
+		case key.Matches(msg, keys.Map.Select):
 			// Last option is "Custom Range..."
 			if m.cursor == len(m.options)-1 {
 				m.customMode = true
@@ -105,7 +147,7 @@ func (m Model) updatePreset(msg tea.Msg) (Model, tea.Cmd) {
 					Range: m.options[m.cursor].Range,
 				}
 			}
-		case "q", "ctrl+c":
+		case key.Matches(msg, keys.Map.Quit):
 			return m, tea.Quit
 		}
 	}
@@ -115,11 +157,25 @@ func (m Model) updatePreset(msg tea.Msg) (Model, tea.Cmd) {
 
 // updateCustom handles the custom date input mode
 func (m Model) updateCustom(msg tea.Msg) (Model, tea.Cmd) {
+	if m.calendarMode {
+		return m.updateCalendar(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
+		case "tab":
+			// Open the calendar on the typed start date, or today
+			day := time.Now()
+			if start, err := daterange.Parse(m.startInput.Value()); err == nil {
+				day = start
+			}
+			m.calendar = newCalendar(day)
+			m.calendarMode = true
+			m.inputError = ""
+			return m, nil
 		case "esc":
 			if m.activeField == fieldEnd {
 				m.activeField = fieldStart
@@ -170,6 +226,43 @@ func (m Model) updateCustom(msg tea.Msg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
+// updateCalendar handles the calendar picker within custom mode
+func (m Model) updateCalendar(msg tea.Msg) (Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "tab":
+		m.calendarMode = false
+		return m, m.startInput.Cursor.BlinkCmd()
+	case "esc":
+		// Esc un-picks the start day, then returns to typing dates
+		if m.calendar.start != nil {
+			m.calendar.start = nil
+			return m, nil
+		}
+		m.calendarMode = false
+		return m, m.startInput.Cursor.BlinkCmd()
+	}
+
+	var r daterange.Range
+	var done bool
+	m.calendar, r, done = m.calendar.update(keyMsg)
+	if !done {
+		return m, nil
+	}
+	m.selected = true
+	m.customMode = false
+	m.calendarMode = false
+	return m, func() tea.Msg {
+		return DateSelectedMsg{Range: r}
+	}
+}
+
 // View renders the date selection screen
 func (m Model) View() string {
 	if m.customMode {
@@ -194,13 +287,19 @@ func (m Model) viewPreset() string {
 		s += "\n"
 	}
 
-	s += "\n" + styles.FooterStyle.Render("↑/↓: Navigate • Enter: Select • q: Quit")
+	k := keys.Map
+	s += "\n" + styles.FooterStyle.Render(fmt.Sprintf("%s: Navigate • %s: Select • %s: Quit",
+		k.Nav(), k.Select.Help().Key, k.Quit.Help().Key))
 
 	return s
 }
 
 // viewCustom renders the custom date input form
 func (m Model) viewCustom() string {
+	if m.calendarMode {
+		return m.viewCalendar()
+	}
+
 	s := styles.TitleStyle.Render("Enter Custom Date Range")
 	s += "\n\n"
 
@@ -219,7 +318,24 @@ func (m Model) viewCustom() string {
 		s += "\n" + styles.ErrorStyle.Render(m.inputError)
 	}
 
-	s += "\n" + styles.FooterStyle.Render("Enter: Confirm • Esc: Back • ctrl+c: Quit")
+	s += "\n" + styles.FooterStyle.Render("Enter: Confirm • Tab: Calendar • Esc: Back • ctrl+c: Quit")
+
+	return s
+}
+
+// viewCalendar renders the calendar picker
+func (m Model) viewCalendar() string {
+	title := "Pick Start Date"
+	if m.calendar.start != nil {
+		title = "Pick End Date (start " + daterange.FormatDate(*m.calendar.start, m.dateFormat) + ")"
+	}
+	s := styles.TitleStyle.Render(title)
+	s += "\n\n"
+	s += m.calendar.view()
+
+	k := keys.Map
+	s += "\n" + styles.FooterStyle.Render(fmt.Sprintf("←/→: Day • %s: Week • %s/%s: Month • Enter: Pick • Tab: Type dates • Esc: Back • ctrl+c: Quit",
+		k.Nav(), k.PageUp.Help().Key, k.PageDown.Help().Key))
 
 	return s
 }
@@ -0,0 +1,49 @@
+package dateselect
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+)
+
+func TestDefaultRangeSelectsPreset(t *testing.T) {
+	tests := []struct {
+		defaultRange string
+		wantCursor   int
+		wantLabel    string
+	}{
+		{"", 0, "Last Week"},
+		{"last-month", 1, "Last Month"},
+		{"Last 3 Months", 2, "Last 3 Months"},
+		{"30d", 3, "30d"},
+		{"not a range", 0, "Last Week"},
+	}
+	for _, tt := range tests {
+		m := New(tt.defaultRange, "")
+		if m.cursor != tt.wantCursor || m.options[m.cursor].Label != tt.wantLabel {
+			t.Errorf("New(%q) selects %d (%q), want %d (%q)",
+				tt.defaultRange, m.cursor, m.options[m.cursor].Label, tt.wantCursor, tt.wantLabel)
+		}
+	}
+}
+
+func TestDefaultRangeIsSentOnEnter(t *testing.T) {
+	m := New("last-month", "")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Enter returned no command")
+	}
+	msg, ok := cmd().(DateSelectedMsg)
+	if !ok {
+		t.Fatalf("Enter sent %T, want DateSelectedMsg", cmd())
+	}
+	// The presets are computed from the current time, so compare the days
+	if want := daterange.LastMonth(); msg.Range.String() != want.String() {
+		t.Errorf("selected %v, want last month %v", msg.Range, want)
+	}
+	if !m.selected {
+		t.Error("model is not marked as selected")
+	}
+}
@@ -1,36 +1,117 @@
 package loading
 
 import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/burritocatai/activitycat/internal/config"
+	"github.com/burritocatai/activitycat/internal/ui/styles"
 )
 
+// rotateInterval is how long each fun loading message is shown
+const rotateInterval = 3 * time.Second
+
+// spinners maps config names to spinner styles
+var spinners = map[string]spinner.Spinner{
+	"dot":     spinner.Dot,
+	"line":    spinner.Line,
+	"minidot": spinner.MiniDot,
+	"jump":    spinner.Jump,
+	"points":  spinner.Points,
+}
+
+var lastID int64
+
 // Model represents the loading screen with a spinner
 type Model struct {
-	spinner spinner.Model
-	message string
+	spinner  spinner.Model
+	message  string
+	hint     string
+	extras   []string
+	extraIdx int
+	id       int64
+
+	// Elapsed time and progress, shown once TrackElapsed is called
+	started  time.Time
+	elapsed  time.Duration
+	progress float64 // 0-1, negative when unknown
+}
+
+// rotateMsg advances the fun loading message for the model with the same id
+type rotateMsg struct {
+	id int64
 }
 
-// New creates a new loading model
-func New(message string) Model {
+// elapsedMsg updates the elapsed timer for the model with the same id
+type elapsedMsg struct {
+	id  int64
+	now time.Time
+}
+
+// ProgressMsg reports approximate progress, e.g. tokens received vs the
+// token limit for streaming providers
+type ProgressMsg struct {
+	Fraction float64 // 0-1
+}
+
+// New creates a new loading model using the spinner settings from cfg
+func New(message string, cfg config.Config) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	if sp, ok := spinners[strings.ToLower(cfg.SpinnerStyle)]; ok {
+		s.Spinner = sp
+	}
+	color := cfg.SpinnerColor
+	if color == "" {
+		color = "205"
+	}
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color(color))
 
 	return Model{
-		spinner: s,
-		message: message,
+		spinner:  s,
+		message:  message,
+		extras:   cfg.LoadingMessages,
+		id:       atomic.AddInt64(&lastID, 1),
+		progress: -1,
 	}
 }
 
+// TrackElapsed shows a ticking elapsed-time counter next to the message
+func (m *Model) TrackElapsed() {
+	m.started = time.Now()
+}
+
 // Init initializes the loading model
 func (m Model) Init() tea.Cmd {
-	return m.spinner.Tick
+	return tea.Batch(m.spinner.Tick, m.rotate(), m.tick())
 }
 
 // Update handles messages for the loading screen
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case rotateMsg:
+		if msg.id != m.id {
+			return m, nil
+		}
+		m.extraIdx = (m.extraIdx + 1) % len(m.extras)
+		return m, m.rotate()
+	case elapsedMsg:
+		if msg.id != m.id {
+			return m, nil
+		}
+		m.elapsed = msg.now.Sub(m.started)
+		return m, m.tick()
+	case ProgressMsg:
+		m.progress = min(max(msg.Fraction, 0), 1)
+		return m, nil
+	}
+
 	var cmd tea.Cmd
 	m.spinner, cmd = m.spinner.Update(msg)
 	return m, cmd
@@ -38,15 +119,63 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 // View renders the loading screen
 func (m Model) View() string {
-	return lipgloss.JoinVertical(
-		lipgloss.Center,
-		"",
-		m.spinner.View()+" "+m.message,
-		"",
-	)
+	extra := ""
+	if len(m.extras) > 0 {
+		extra = styles.SubtleStyle.Render(m.extras[m.extraIdx])
+	}
+
+	status := ""
+	if !m.started.IsZero() {
+		status = " " + styles.SubtleStyle.Render(FormatElapsed(m.elapsed))
+		if m.progress >= 0 {
+			status += styles.SubtleStyle.Render(fmt.Sprintf(" (~%.0f%%)", m.progress*100))
+		}
+	}
+
+	lines := []string{"", m.spinner.View() + " " + m.message + status, extra}
+	if m.hint != "" {
+		lines = append(lines, "", styles.FooterStyle.Render(m.hint))
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, lines...)
+}
+
+// FormatElapsed formats a duration as "42s" or "3m05s"
+func FormatElapsed(d time.Duration) string {
+	secs := int(d.Seconds())
+	if secs < 60 {
+		return fmt.Sprintf("%ds", secs)
+	}
+	return fmt.Sprintf("%dm%02ds", secs/60, secs%60)
 }
 
 // SetMessage updates the loading message
 func (m *Model) SetMessage(message string) {
 	m.message = message
 }
+
+// SetHint shows a line of help below the message, e.g. how to cancel
+func (m *Model) SetHint(hint string) {
+	m.hint = hint
+}
+
+// rotate schedules the next fun message, if there is more than one to show
+func (m Model) rotate() tea.Cmd {
+	if len(m.extras) < 2 {
+		return nil
+	}
+	id := m.id
+	return tea.Tick(rotateInterval, func(time.Time) tea.Msg {
+		return rotateMsg{id: id}
+	})
+}
+
+// tick schedules the next elapsed-time update if the timer is running
+func (m Model) tick() tea.Cmd {
+	if m.started.IsZero() {
+		return nil
+	}
+	id := m.id
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return elapsedMsg{id: id, now: t}
+	})
+}
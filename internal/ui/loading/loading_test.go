@@ -0,0 +1,52 @@
+package loading
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/burritocatai/activitycat/internal/config"
+)
+
+func TestNewEachSpinnerStyle(t *testing.T) {
+	for _, style := range []string{"", "dot", "line", "minidot", "jump", "points", "Points", "unknown"} {
+		cfg := config.Config{SpinnerStyle: style, SpinnerColor: "#ff8800", LoadingMessages: []string{"Herding cats..."}}
+		m := New("Fetching...", cfg)
+		m, _ = m.Update(m.spinner.Tick())
+		if m.View() == "" {
+			t.Errorf("spinner style %q rendered nothing", style)
+		}
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{1500 * time.Millisecond, "1s"},
+		{59 * time.Second, "59s"},
+		{time.Minute, "1m00s"},
+		{3*time.Minute + 5*time.Second, "3m05s"},
+		{75 * time.Minute, "75m00s"},
+	}
+	for _, tt := range tests {
+		if got := FormatElapsed(tt.d); got != tt.want {
+			t.Errorf("FormatElapsed(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestProgressShown(t *testing.T) {
+	m := New("Generating...", config.Config{})
+	m.TrackElapsed()
+	if strings.Contains(m.View(), "%") {
+		t.Errorf("progress shown before any was reported:\n%s", m.View())
+	}
+
+	m, _ = m.Update(ProgressMsg{Fraction: 0.42})
+	if !strings.Contains(m.View(), "(~42%)") {
+		t.Errorf("view does not show the reported progress:\n%s", m.View())
+	}
+}
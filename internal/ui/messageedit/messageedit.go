@@ -0,0 +1,93 @@
+package messageedit
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/burritocatai/activitycat/internal/ui/styles"
+)
+
+// Model represents the screen for editing the assembled LLM message
+type Model struct {
+	textarea textarea.Model
+	original string
+}
+
+// New creates a new message editing model seeded with the generated message
+func New(message string, width, height int) Model {
+	ta := textarea.New()
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	// Enter submits, so newlines need a different binding
+	ta.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("ctrl+j", "alt+enter"))
+	ta.SetValue(message)
+	ta.Focus()
+
+	m := Model{
+		textarea: ta,
+		original: message,
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+// Init initializes the model
+func (m Model) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+// Update handles messages
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "enter":
+			message := m.textarea.Value()
+			return m, func() tea.Msg {
+				return SubmitMsg{Message: message}
+			}
+		case "esc":
+			// Revert edits first, and go back once there is nothing to revert
+			if m.textarea.Value() == m.original {
+				return m, func() tea.Msg {
+					return BackMsg{}
+				}
+			}
+			m.textarea.SetValue(m.original)
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.textarea, cmd = m.textarea.Update(msg)
+	return m, cmd
+}
+
+// View renders the screen
+func (m Model) View() string {
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		styles.TitleStyle.Render("Edit Message Before Generating"),
+		m.textarea.View(),
+		styles.FooterStyle.Render("Enter: Generate • ctrl+j: Newline • Esc: Revert/Back • ctrl+c: Quit"),
+	)
+}
+
+// SetSize updates the dimensions
+func (m *Model) SetSize(width, height int) {
+	if width > 0 && height > 0 {
+		m.textarea.SetWidth(width)
+		m.textarea.SetHeight(height - 5)
+	}
+}
+
+// SubmitMsg is sent with the edited message to generate the report from
+type SubmitMsg struct {
+	Message string
+}
+
+// BackMsg is sent when the user wants to go back
+type BackMsg struct{}
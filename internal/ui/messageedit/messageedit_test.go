@@ -0,0 +1,39 @@
+package messageedit
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSubmitEditedMessage(t *testing.T) {
+	m := New("Generated", 80, 24)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" and edited")})
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	submit, ok := cmd().(SubmitMsg)
+	if !ok {
+		t.Fatal("Enter didn't submit the message")
+	}
+	if submit.Message != "Generated and edited" {
+		t.Errorf("submitted %q, want %q", submit.Message, "Generated and edited")
+	}
+}
+
+func TestEscRevertsThenGoesBack(t *testing.T) {
+	m := New("Generated", 80, 24)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := m.textarea.Value(); got != "Generated" {
+		t.Errorf("after Esc the message is %q, want the generated one", got)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("Esc with nothing to revert did nothing")
+	}
+	if _, ok := cmd().(BackMsg); !ok {
+		t.Error("Esc with nothing to revert didn't go back")
+	}
+}
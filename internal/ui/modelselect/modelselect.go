@@ -0,0 +1,171 @@
+package modelselect
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/burritocatai/activitycat/internal/config"
+	"github.com/burritocatai/activitycat/internal/keys"
+	"github.com/burritocatai/activitycat/internal/ui/styles"
+)
+
+// Option is a provider and model that reports can be generated with
+type Option struct {
+	Name     string // [models] entry name, empty for the configured model
+	Provider string
+	Model    string
+}
+
+// label renders the option as "name: provider / model"
+func (o Option) label() string {
+	label := o.Provider + " / " + o.Model
+	if o.Name != "" {
+		label = o.Name + ": " + label
+	}
+	return label
+}
+
+// Options returns the configured provider and model followed by each
+// [models] entry, in name order, leaving out entries that resolve to the
+// configured model
+func Options(cfg config.Config) []Option {
+	configured := cfg.ResolveModel()
+	options := []Option{{Provider: configured.Provider, Model: configured.Model}}
+
+	names := make([]string, 0, len(cfg.Models))
+	for name := range cfg.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		selected, _ := cfg.SelectModel(name)
+		if selected.Provider == configured.Provider && selected.Model == configured.Model {
+			continue
+		}
+		options = append(options, Option{Name: name, Provider: selected.Provider, Model: selected.Model})
+	}
+	return options
+}
+
+// Model represents the screen for switching the provider and model
+type Model struct {
+	options  []Option
+	cursor   int
+	active   int // option currently generating reports
+	checking bool
+	err      string
+}
+
+// New creates a model switching screen with the cursor on the option
+// matching the active provider and model. A [models] name in active is
+// resolved first, so the entry it names is the one marked.
+func New(options []Option, active config.Config) Model {
+	active = active.ResolveModel()
+	m := Model{options: options, active: -1}
+	for i, o := range options {
+		if o.Provider == active.Provider && o.Model == active.Model {
+			m.cursor, m.active = i, i
+			break
+		}
+	}
+	return m
+}
+
+// SetError shows why the chosen option was not switched to
+func (m *Model) SetError(err error) {
+	m.checking = false
+	m.err = err.Error()
+}
+
+// Init initializes the model
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the model switching screen. While the chosen
+// option is being checked, esc or back abandons the check and other keys are
+// ignored.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.checking {
+			if msg.String() == "esc" || key.Matches(msg, keys.Map.Back) {
+				m.checking = false
+				return m, func() tea.Msg {
+					return BackMsg{}
+				}
+			}
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, keys.Map.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, keys.Map.Down):
+			if m.cursor < len(m.options)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, keys.Map.Select):
+			option := m.options[m.cursor]
+			m.checking = true
+			m.err = ""
+			return m, func() tea.Msg {
+				return SelectedMsg{Option: option}
+			}
+		case key.Matches(msg, keys.Map.Back, keys.Map.Settings):
+			return m, func() tea.Msg {
+				return BackMsg{}
+			}
+		case key.Matches(msg, keys.Map.Quit):
+			return m, tea.Quit
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the model switching screen
+func (m Model) View() string {
+	s := styles.TitleStyle.Render("Switch Provider and Model")
+	s += "\n\n"
+
+	for i, o := range m.options {
+		cursor := "  "
+		if m.cursor == i {
+			cursor = "> "
+			s += styles.SelectedStyle.Render(cursor + o.label())
+		} else {
+			s += styles.UnselectedStyle.Render(cursor + o.label())
+		}
+		if i == m.active {
+			s += styles.SubtleStyle.Render("  (active)")
+		}
+		s += "\n"
+	}
+
+	if m.checking {
+		s += "\n" + styles.SubtleStyle.Render("Checking credentials... Esc: Cancel")
+	}
+	if m.err != "" {
+		s += "\n" + styles.ErrorStyle.Render("✗ Error: "+m.err)
+	}
+
+	k := keys.Map
+	s += "\n" + styles.FooterStyle.Render(fmt.Sprintf("%s: Navigate • %s: Switch for the next report • %s: Back • %s: Quit",
+		k.Nav(), k.Select.Help().Key, k.Back.Help().Key, k.Quit.Help().Key))
+
+	return s
+}
+
+// SelectedMsg is sent when an option is chosen, to be checked before switching
+type SelectedMsg struct {
+	Option Option
+}
+
+// BackMsg is sent when the user leaves without switching
+type BackMsg struct{}
@@ -0,0 +1,74 @@
+package modelselect
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/burritocatai/activitycat/internal/config"
+	"github.com/burritocatai/activitycat/internal/keys"
+)
+
+// runes returns the key message for typing s
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestRemappedBackKey(t *testing.T) {
+	t.Cleanup(func() { keys.Map = keys.Default() })
+	if err := keys.Load(map[string]string{"back": "h"}); err != nil {
+		t.Fatal(err)
+	}
+	m := New([]Option{{Provider: "claude", Model: "claude-sonnet-4-5"}}, config.Config{Provider: "claude", Model: "claude-sonnet-4-5"})
+
+	if _, cmd := m.Update(runes("h")); cmd == nil || cmd() != (BackMsg{}) {
+		t.Error("the remapped back key didn't send BackMsg")
+	}
+	for _, msg := range []tea.KeyMsg{runes("b"), {Type: tea.KeyEsc}} {
+		if _, cmd := m.Update(msg); cmd != nil {
+			t.Errorf("%q still goes back after back was remapped", msg)
+		}
+	}
+}
+
+func TestActiveModelName(t *testing.T) {
+	cfg := config.Config{
+		Provider: "claude",
+		Model:    "claude-sonnet-4-5",
+		Models: map[string]config.ModelAlias{
+			"deep": {Model: "claude-opus-4-1"},
+			"fast": {Model: "claude-haiku-4-5"},
+		},
+	}
+	options := Options(cfg)
+
+	// The active model is given by its [models] name
+	active := cfg
+	active.Model = "fast"
+	m := New(options, active)
+	if m.active < 0 || options[m.active].Name != "fast" {
+		t.Fatalf("active = %d, want the fast entry of %v", m.active, options)
+	}
+	if m.cursor != m.active {
+		t.Errorf("cursor = %d, want it on the active entry %d", m.cursor, m.active)
+	}
+}
+
+func TestBackWhileChecking(t *testing.T) {
+	m := New([]Option{{Provider: "claude", Model: "claude-haiku-4-5"}}, config.Config{Provider: "claude", Model: "claude-sonnet-4-5"})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || !m.checking {
+		t.Fatal("enter didn't start checking the option")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyDown}); cmd != nil {
+		t.Error("a key other than back was handled while checking")
+	}
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil || cmd() != (BackMsg{}) {
+		t.Error("esc while checking didn't send BackMsg")
+	}
+	if m.checking {
+		t.Error("still checking after esc")
+	}
+}
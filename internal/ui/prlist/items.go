@@ -0,0 +1,134 @@
+package prlist
+
+import (
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/burritocatai/activitycat/internal/keys"
+	"github.com/burritocatai/activitycat/internal/ui/styles"
+)
+
+// noticeDuration is how long a notice replaces the key help
+const noticeDuration = 2 * time.Second
+
+// itemWriter writes the listed items into content, recording each one's
+// link and the line it starts on, and marks the item under cursor
+type itemWriter struct {
+	content *strings.Builder
+	cursor  int // -1 when no item is being chosen
+	urls    []string
+	lines   []int
+
+	// scanned and line track how far newlines have been counted, so each
+	// item's line is found without rescanning the content
+	scanned int
+	line    int
+}
+
+// write writes an item rendered as s, with a cursor mark beside it while
+// items are being chosen
+func (w *itemWriter) write(url, s string) {
+	text := w.content.String()
+	w.line += strings.Count(text[w.scanned:], "\n")
+	w.scanned = len(text)
+
+	i := len(w.urls)
+	w.urls = append(w.urls, url)
+	w.lines = append(w.lines, w.line)
+
+	switch {
+	case w.cursor < 0:
+	case i == w.cursor:
+		s = lipgloss.JoinHorizontal(lipgloss.Top, styles.SelectedStyle.PaddingLeft(0).Render("> "), s)
+	default:
+		s = lipgloss.JoinHorizontal(lipgloss.Top, "  ", s)
+	}
+	w.content.WriteString(s)
+}
+
+// firstItemFrom returns the first listed item starting at or below line, or
+// the last item if none do
+func (m Model) firstItemFrom(line int) int {
+	for i, l := range m.itemLines {
+		if l >= line {
+			return i
+		}
+	}
+	return len(m.itemLines) - 1
+}
+
+// scrollTo scrolls line to the top of the viewport if it is out of view
+func (m *Model) scrollTo(line int) {
+	if line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height-1 {
+		m.viewport.SetYOffset(line)
+	}
+}
+
+// updateItemSelect handles keys while choosing an item to copy the link of.
+// The items are those listed by the last refresh, so only a key that moves
+// the cursor renders the list again.
+func (m Model) updateItemSelect(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Map.Up):
+		if m.itemCursor > 0 {
+			m.itemCursor--
+		}
+	case key.Matches(msg, keys.Map.Down):
+		if m.itemCursor < len(m.itemURLs)-1 {
+			m.itemCursor++
+		}
+	case key.Matches(msg, keys.Map.Yank, keys.Map.Select):
+		m.selectingItem = false
+		m.refresh()
+		return m, copyLink(m.itemURLs[m.itemCursor])
+	case key.Matches(msg, keys.Map.Back):
+		m.selectingItem = false
+	case key.Matches(msg, keys.Map.Quit):
+		return m, tea.Quit
+	default:
+		return m, nil
+	}
+	m.refresh()
+	return m, nil
+}
+
+// copyLink copies url to the system clipboard
+func copyLink(url string) tea.Cmd {
+	return func() tea.Msg {
+		return copiedMsg{url: url, err: clipboard.WriteAll(url)}
+	}
+}
+
+// setNotice shows notice in place of the key help for noticeDuration
+func (m *Model) setNotice(notice string) tea.Cmd {
+	m.noticeID++
+	m.notice = notice
+	id := m.noticeID
+	return tea.Tick(noticeDuration, func(time.Time) tea.Msg {
+		return noticeExpiredMsg{id: id}
+	})
+}
+
+// copiedMsg reports whether an item's link was copied
+type copiedMsg struct {
+	url string
+	err error
+}
+
+// notice confirms the copy, or says why it failed
+func (msg copiedMsg) notice() string {
+	if msg.err != nil {
+		return styles.ErrorStyle.Render("✗ Could not copy the link: " + msg.err.Error())
+	}
+	return "✓ Copied " + msg.url
+}
+
+// noticeExpiredMsg clears the notice with the same id, if still shown
+type noticeExpiredMsg struct {
+	id int
+}
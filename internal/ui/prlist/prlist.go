@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/burritocatai/activitycat/internal/analytics"
+	"github.com/burritocatai/activitycat/internal/daterange"
 	"github.com/burritocatai/activitycat/internal/github"
+	"github.com/burritocatai/activitycat/internal/keys"
 	"github.com/burritocatai/activitycat/internal/ui/styles"
 )
 
@@ -21,20 +24,75 @@ type Model struct {
 	reviews        []github.Review
 	commits        []github.Commit
 	commentedItems []github.CommentedItem
+	discussions    []github.Discussion
 	metrics        *analytics.Metrics
+	warnings       []string
+	dateFormat     string
+	repoAliases    map[string]string
 	width          int
 	height         int
 	ready          bool
+
+	// Repo drill-down: selectingRepo moves a cursor over the breakdown rows,
+	// repoFilter scopes the list to a single repository when set
+	selectingRepo bool
+	repoCursor    int
+	repoFilter    string
+
+	// timeline shows all activity in one date-sorted list instead of a
+	// section per type
+	timeline bool
+
+	// Item selection: selectingItem moves itemCursor over the listed items,
+	// in the order they are shown, to copy one's link. itemURLs and
+	// itemLines are each listed item's link and first line, as last rendered.
+	selectingItem bool
+	itemCursor    int
+	itemURLs      []string
+	itemLines     []int
+
+	// anonymized is set when names are placeholders, so the links built
+	// from them lead nowhere and copying is refused
+	anonymized bool
+
+	// notice is a short-lived message like a copy confirmation, shown in
+	// place of the key help until the expiry for noticeID arrives
+	notice   string
+	noticeID int
+
+	// offsets remembers the scroll position of each layout and repo filter,
+	// so switching back returns to where that view was left
+	offsets map[view]int
+}
+
+// view identifies a layout and repo filter combination
+type view struct {
+	timeline bool
+	repo     string
+}
+
+// activity holds the activity slices currently shown
+type activity struct {
+	prs            []github.PullRequest
+	issues         []github.Issue
+	reviews        []github.Review
+	commits        []github.Commit
+	commentedItems []github.CommentedItem
+	discussions    []github.Discussion
 }
 
-// New creates a new activity list model
+// New creates a new activity list model, with dates shown in dateFormat and
+// repositories labelled from repoAliases
 func New(
 	prs []github.PullRequest,
 	issues []github.Issue,
 	reviews []github.Review,
 	commits []github.Commit,
 	commentedItems []github.CommentedItem,
+	discussions []github.Discussion,
 	metrics *analytics.Metrics,
+	dateFormat string,
+	repoAliases map[string]string,
 	width, height int,
 ) Model {
 	m := Model{
@@ -43,7 +101,10 @@ func New(
 		reviews:        reviews,
 		commits:        commits,
 		commentedItems: commentedItems,
+		discussions:    discussions,
 		metrics:        metrics,
+		dateFormat:     dateFormat,
+		repoAliases:    repoAliases,
 		width:          width,
 		height:         height,
 		ready:          false,
@@ -51,8 +112,9 @@ func New(
 
 	if width > 0 && height > 0 {
 		m.viewport = viewport.New(width, height-5)
-		m.viewport.SetContent(m.renderContent())
+		m.viewport.KeyMap = keys.ViewportKeyMap()
 		m.ready = true
+		m.refresh()
 	}
 
 	return m
@@ -69,31 +131,87 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
+		if m.selectingRepo {
+			return m.updateRepoSelect(msg)
+		}
+		if m.selectingItem {
+			return m.updateItemSelect(msg)
+		}
+		if keys.Page(&m.viewport, msg) {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, keys.Map.Quit):
 			return m, tea.Quit
-		case "b":
+		case key.Matches(msg, keys.Map.Sort):
+			if m.repoFilter == "" && m.metrics != nil && len(m.metrics.RepoStats) > 0 {
+				m.metrics.SortRepoStats(analytics.NextRepoSort(m.metrics.RepoSort))
+				m.refresh()
+				return m, nil
+			}
+		case key.Matches(msg, keys.Map.Timeline):
+			if !m.isEmpty() {
+				m.switchView(view{!m.timeline, m.repoFilter})
+				return m, nil
+			}
+		case key.Matches(msg, keys.Map.Yank):
+			if m.anonymized {
+				return m, m.setNotice("Links can't be copied while anonymizing")
+			}
+			if len(m.itemURLs) > 0 {
+				m.selectingItem = true
+				m.itemCursor = m.firstItemFrom(m.viewport.YOffset)
+				m.refresh()
+				return m, nil
+			}
+		case key.Matches(msg, keys.Map.Filter):
+			if m.repoFilter == "" && len(m.repoStats()) > 0 {
+				m.selectingRepo = true
+				m.refresh()
+				return m, nil
+			}
+		case key.Matches(msg, keys.Map.Back):
+			if m.repoFilter != "" {
+				m.switchView(view{m.timeline, ""})
+				return m, nil
+			}
 			return m, func() tea.Msg {
 				return BackMsg{}
 			}
-		case "enter":
+		case key.Matches(msg, keys.Map.Select):
+			// Nothing to report on, so go back to pick another range
+			if m.isEmpty() {
+				return m, func() tea.Msg {
+					return BackMsg{}
+				}
+			}
 			return m, func() tea.Msg {
 				return ContinueMsg{}
 			}
 		}
 
+	case copiedMsg:
+		return m, m.setNotice(msg.notice())
+
+	case noticeExpiredMsg:
+		if msg.id == m.noticeID {
+			m.notice = ""
+		}
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 
 		if !m.ready {
 			m.viewport = viewport.New(msg.Width, msg.Height-5)
-			m.viewport.SetContent(m.renderContent())
+			m.viewport.KeyMap = keys.ViewportKeyMap()
 			m.ready = true
+			m.refresh()
 		} else {
 			m.viewport.Width = msg.Width
 			m.viewport.Height = msg.Height - 5
-			m.viewport.SetContent(m.renderContent())
+			m.refresh()
 		}
 	}
 
@@ -101,17 +219,161 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
+// updateRepoSelect handles keys while the repo breakdown cursor is active
+func (m Model) updateRepoSelect(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Map.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Map.Up):
+		if m.repoCursor > 0 {
+			m.repoCursor--
+		}
+	case key.Matches(msg, keys.Map.Down):
+		if m.repoCursor < len(m.repoStats())-1 {
+			m.repoCursor++
+		}
+	case key.Matches(msg, keys.Map.Select):
+		m.selectingRepo = false
+		m.switchView(view{m.timeline, m.repoStats()[m.repoCursor].Repo})
+		return m, nil
+	case key.Matches(msg, keys.Map.Filter, keys.Map.Back):
+		m.selectingRepo = false
+	}
+	m.refresh()
+	return m, nil
+}
+
+// repoStats returns the repos shown in the breakdown
+func (m Model) repoStats() []analytics.RepoStats {
+	if m.metrics == nil {
+		return nil
+	}
+	shown, _ := m.metrics.ShownRepoStats()
+	return shown
+}
+
+// visible returns the activity shown in the current view
+func (m Model) visible() activity {
+	if m.repoFilter == "" {
+		return activity{m.prs, m.issues, m.reviews, m.commits, m.commentedItems, m.discussions}
+	}
+
+	var a activity
+	for _, pr := range m.prs {
+		if pr.Repository.NameWithOwner == m.repoFilter {
+			a.prs = append(a.prs, pr)
+		}
+	}
+	for _, issue := range m.issues {
+		if issue.Repository.NameWithOwner == m.repoFilter {
+			a.issues = append(a.issues, issue)
+		}
+	}
+	for _, r := range m.reviews {
+		if r.Repository.NameWithOwner == m.repoFilter {
+			a.reviews = append(a.reviews, r)
+		}
+	}
+	for _, c := range m.commits {
+		if c.Repository.FullName == m.repoFilter {
+			a.commits = append(a.commits, c)
+		}
+	}
+	for _, ci := range m.commentedItems {
+		if ci.Repository.NameWithOwner == m.repoFilter {
+			a.commentedItems = append(a.commentedItems, ci)
+		}
+	}
+	for _, d := range m.discussions {
+		if d.Repository.NameWithOwner == m.repoFilter {
+			a.discussions = append(a.discussions, d)
+		}
+	}
+	return a
+}
+
+// refresh re-renders the viewport content, keeping the scroll position
+// where the new content allows
+func (m *Model) refresh() {
+	if m.ready {
+		offset := m.viewport.YOffset
+		content, items := m.render()
+		m.viewport.SetContent(content)
+		m.viewport.SetYOffset(offset)
+		m.itemURLs, m.itemLines = items.urls, items.lines
+		if m.selectingItem {
+			m.scrollTo(items.lines[m.itemCursor])
+		}
+	}
+}
+
+// switchView changes the layout or repo filter. The list scrolls back to
+// where it was last left in the new view, or stays where it is the first
+// time the view is shown.
+func (m *Model) switchView(v view) {
+	if m.offsets == nil {
+		m.offsets = make(map[view]int)
+	}
+	m.offsets[view{m.timeline, m.repoFilter}] = m.viewport.YOffset
+
+	m.timeline, m.repoFilter = v.timeline, v.repo
+	if offset, ok := m.offsets[v]; ok {
+		m.viewport.YOffset = offset
+	}
+	m.refresh()
+}
+
 // View renders the screen
 func (m Model) View() string {
 	if !m.ready {
 		return "\n  Initializing..."
 	}
 
-	header := styles.TitleStyle.Render(fmt.Sprintf(
-		"Activity: %d PRs, %d Issues, %d Reviews, %d Commits",
-		len(m.prs), len(m.issues), len(m.reviews), len(m.commits),
-	))
-	footer := styles.FooterStyle.Render("↑/↓: Scroll • Enter: Continue • b: Back • q: Quit")
+	a := m.visible()
+	title := fmt.Sprintf(
+		"Activity: %d PRs, %d Issues, %d Reviews, %d Commits, %d Commented",
+		len(a.prs), len(a.issues), len(a.reviews), len(a.commits), len(a.commentedItems),
+	)
+	// Discussions are only fetched when enabled, so only counted when found
+	if len(m.discussions) > 0 {
+		title += fmt.Sprintf(", %d Discussions", len(a.discussions))
+	}
+	if m.repoFilter != "" {
+		title += " in " + github.RepoLabel(m.repoAliases, m.repoFilter)
+	}
+	// A single day has no momentum to show
+	if m.metrics != nil && len(m.metrics.DailyActivity) > 1 {
+		title += fmt.Sprintf("  %s last %d days", analytics.Sparkline(m.metrics.DailyActivity), len(m.metrics.DailyActivity))
+	}
+	// The legend sits in the title's bottom margin so the layout height is unchanged
+	header := lipgloss.JoinVertical(
+		lipgloss.Left,
+		styles.TitleStyle.MarginBottom(0).Render(title),
+		legend(),
+	)
+
+	k := keys.Map
+	var help string
+	switch {
+	case m.notice != "":
+		help = m.notice
+	case m.selectingItem:
+		help = fmt.Sprintf("%s: Select item • %s/%s: Copy link • %s: Cancel • %s: Quit",
+			k.Nav(), k.Yank.Help().Key, k.Select.Help().Key, k.Back.Help().Key, k.Quit.Help().Key)
+	case m.selectingRepo:
+		help = fmt.Sprintf("%s: Select repo • %s: Show repo • %s/%s: Cancel • %s: Quit",
+			k.Nav(), k.Select.Help().Key, k.Filter.Help().Key, k.Back.Help().Key, k.Quit.Help().Key)
+	case m.repoFilter != "":
+		help = fmt.Sprintf("%s: Scroll • %s: Page • %s: %s • %s: Copy link • %s: Continue • %s: All repos • %s: Quit",
+			k.Nav(), k.Paging(), k.Timeline.Help().Key, m.layoutName(), k.Yank.Help().Key, k.Select.Help().Key, k.Back.Help().Key, k.Quit.Help().Key)
+	case m.isEmpty():
+		help = fmt.Sprintf("%s/%s: Change date range • %s: Quit",
+			k.Select.Help().Key, k.Back.Help().Key, k.Quit.Help().Key)
+	default:
+		help = fmt.Sprintf("%s: Scroll • %s: Page • %s: Select repo • %s: Sort repos • %s: %s • %s: Copy link • %s: Continue • %s: Back • %s: Quit",
+			k.Nav(), k.Paging(), k.Filter.Help().Key, k.Sort.Help().Key, k.Timeline.Help().Key, m.layoutName(), k.Yank.Help().Key, k.Select.Help().Key, k.Back.Help().Key, k.Quit.Help().Key)
+	}
+	footer := styles.FooterStyle.Render(help)
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -121,94 +383,200 @@ func (m Model) View() string {
 	)
 }
 
+// layoutName names the layout the timeline key switches to
+func (m Model) layoutName() string {
+	if m.timeline {
+		return "By type"
+	}
+	return "Timeline"
+}
+
+// legend explains the state colors used on cards
+func legend() string {
+	return styles.SubtleStyle.Render("State: ") +
+		styles.MergedStyle.Render("MERGED") + "  " +
+		styles.OpenStyle.Render("OPEN") + "  " +
+		styles.ClosedStyle.Render("CLOSED")
+}
+
+// isEmpty reports whether no activity was found at all
+func (m Model) isEmpty() bool {
+	return len(m.prs) == 0 && len(m.issues) == 0 && len(m.reviews) == 0 &&
+		len(m.commits) == 0 && len(m.commentedItems) == 0 && len(m.discussions) == 0
+}
+
+// renderEmpty explains what to try when no activity was found
+func (m Model) renderEmpty() string {
+	var content strings.Builder
+
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("No activity found in this date range."))
+	content.WriteString("\n\n")
+
+	for _, w := range m.warnings {
+		content.WriteString(styles.WarningStyle.Render("⚠ " + w))
+		content.WriteString("\n")
+	}
+	if len(m.warnings) > 0 {
+		content.WriteString("\n")
+	}
+
+	content.WriteString("Things to try:\n")
+	for _, tip := range []string{
+		"Widen the date range, e.g. Last 3 Months or a custom range",
+		"Check gh is logged in as the account you expect: gh auth status",
+		"Make sure your gh token has the repo and read:org scopes: gh auth refresh -s repo,read:org",
+	} {
+		content.WriteString(styles.SubtleStyle.Render("  • " + tip))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+	content.WriteString(fmt.Sprintf("Press %s or %s to choose a different date range.",
+		keys.Map.Select.Help().Key, keys.Map.Back.Help().Key))
+
+	return content.String()
+}
+
 // renderContent formats all activity for display
 func (m Model) renderContent() string {
-	if len(m.prs) == 0 && len(m.issues) == 0 && len(m.reviews) == 0 &&
-		len(m.commits) == 0 && len(m.commentedItems) == 0 {
-		return styles.SubtleStyle.Render("No activity found in this date range.")
-	}
+	content, _ := m.render()
+	return content
+}
 
+// render formats all activity for display, along with the items listed
+func (m Model) render() (string, *itemWriter) {
+	a := m.visible()
 	var content strings.Builder
+	cursor := -1
+	if m.selectingItem {
+		cursor = m.itemCursor
+	}
+	items := &itemWriter{content: &content, cursor: cursor}
+	if m.isEmpty() {
+		return m.renderEmpty(), items
+	}
+
+	for _, w := range m.warnings {
+		content.WriteString(styles.WarningStyle.Render("⚠ " + w))
+		content.WriteString("\n")
+	}
+	if len(m.warnings) > 0 {
+		content.WriteString("\n")
+	}
 
 	// 1. Analytics summary box
-	if m.metrics != nil {
-		content.WriteString(styles.MetricsBoxStyle.Render(m.metrics.Format()))
+	if m.metrics != nil && m.repoFilter == "" {
+		content.WriteString(styles.MetricsBoxStyle.Render(m.metrics.FormatSummary()))
 		content.WriteString("\n\n")
 	}
 
 	// 2. Repo breakdown table
-	if m.metrics != nil && len(m.metrics.RepoStats) > 0 {
+	var hiddenRepos int
+	if m.metrics != nil {
+		_, hiddenRepos = m.metrics.ShownRepoStats()
+	}
+	if repos := m.repoStats(); (len(repos) > 0 || hiddenRepos > 0) && m.repoFilter == "" {
 		content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Repository Breakdown"))
+		content.WriteString(styles.SubtleStyle.Render("  sorted by " + m.metrics.RepoSort))
 		content.WriteString("\n\n")
-		for _, rs := range m.metrics.RepoStats {
-			line := fmt.Sprintf("  %-40s  PRs:%-3d  Issues:%-3d  Reviews:%-3d  Commits:%-3d  Comments:%-3d",
-				rs.Repo, rs.PRs, rs.Issues, rs.Reviews, rs.Commits, rs.CommentedItems)
-			content.WriteString(styles.SubtleStyle.Render(line))
+		for i, rs := range repos {
+			line := fmt.Sprintf("%-40s  PRs:%-3d  Issues:%-3d  Reviews:%-3d  Commits:%-3d  Comments:%-3d",
+				github.RepoLabel(m.repoAliases, rs.Repo), rs.PRs, rs.Issues, rs.Reviews, rs.Commits, rs.CommentedItems)
+			if m.selectingRepo && i == m.repoCursor {
+				content.WriteString(styles.SelectedStyle.PaddingLeft(0).Render("> " + line))
+			} else {
+				content.WriteString(styles.SubtleStyle.Render("  " + line))
+			}
+			content.WriteString("\n")
+		}
+		if hiddenRepos > 0 {
+			content.WriteString(styles.SubtleStyle.Render(fmt.Sprintf("  +%d more repos", hiddenRepos)))
 			content.WriteString("\n")
 		}
 		content.WriteString("\n")
 	}
 
+	// 3. Everything in date order, in place of the sections by type
+	if m.timeline {
+		content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("4")).Render("Timeline"))
+		content.WriteString("\n\n")
+		renderTimeline(items, timeline(a, m.repoAliases), m.dateFormat)
+		return content.String(), items
+	}
+
 	// 3. Pull Requests
-	if len(m.prs) > 0 {
+	if len(a.prs) > 0 {
 		content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("4")).Render("Pull Requests"))
 		content.WriteString("\n\n")
-		for _, pr := range m.prs {
-			content.WriteString(formatPR(pr))
+		for _, pr := range a.prs {
+			items.write(pr.URL(), m.formatPR(pr))
 			content.WriteString("\n")
 		}
 	}
 
 	// 4. Closed Issues
-	if len(m.issues) > 0 {
+	if len(a.issues) > 0 {
 		if content.Len() > 0 {
 			content.WriteString("\n")
 		}
 		content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("2")).Render("Closed Issues"))
 		content.WriteString("\n\n")
-		for _, issue := range m.issues {
-			content.WriteString(formatIssue(issue))
+		for _, issue := range a.issues {
+			items.write(issue.URL(), m.formatIssue(issue))
 			content.WriteString("\n")
 		}
 	}
 
 	// 5. Code Reviews Given
-	if len(m.reviews) > 0 {
+	if len(a.reviews) > 0 {
 		content.WriteString("\n")
 		content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("135")).Render("Code Reviews Given"))
+		if outcomes := github.ReviewOutcomes(a.reviews); outcomes != "" {
+			content.WriteString(styles.SubtleStyle.Render(" (" + outcomes + ")"))
+		}
 		content.WriteString("\n\n")
-		for _, r := range m.reviews {
-			content.WriteString(formatReview(r))
+		for _, r := range a.reviews {
+			items.write(r.URL(), m.formatReview(r))
 			content.WriteString("\n")
 		}
 	}
 
 	// 6. Commits
-	if len(m.commits) > 0 {
+	if len(a.commits) > 0 {
 		content.WriteString("\n")
 		content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("208")).Render("Commits"))
 		content.WriteString("\n\n")
-		for _, c := range m.commits {
-			content.WriteString(formatCommit(c))
+		for _, c := range a.commits {
+			items.write(c.URL(), m.formatCommit(c))
 			content.WriteString("\n")
 		}
 	}
 
 	// 7. Commented Items
-	if len(m.commentedItems) > 0 {
+	if len(a.commentedItems) > 0 {
 		content.WriteString("\n")
 		content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("241")).Render("Commented Items"))
 		content.WriteString("\n\n")
-		for _, ci := range m.commentedItems {
-			content.WriteString(formatCommentedItem(ci))
+		for _, ci := range a.commentedItems {
+			items.write(ci.URL(), m.formatCommentedItem(ci))
 			content.WriteString("\n")
 		}
 	}
 
-	return content.String()
+	// 8. Discussions Started
+	if len(a.discussions) > 0 {
+		content.WriteString("\n")
+		content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("37")).Render("Discussions Started"))
+		content.WriteString("\n\n")
+		for _, d := range a.discussions {
+			items.write(d.URL(), m.formatDiscussion(d))
+			content.WriteString("\n")
+		}
+	}
+
+	return content.String(), items
 }
 
-func formatPR(pr github.PullRequest) string {
+func (m Model) formatPR(pr github.PullRequest) string {
 	var stateStyle lipgloss.Style
 	var stateLabel string
 
@@ -225,14 +593,14 @@ func formatPR(pr github.PullRequest) string {
 
 	title := lipgloss.NewStyle().Bold(true).Render(pr.Title)
 	state := stateStyle.Render(fmt.Sprintf("[%s]", stateLabel))
-	repo := styles.SubtleStyle.Render(pr.Repository.NameWithOwner)
-	author := styles.SubtleStyle.Render(fmt.Sprintf("@%s", pr.Author.Login))
+	repo := styles.SubtleStyle.Render(github.RepoLabel(m.repoAliases, pr.Repository.NameWithOwner))
+	author := styles.SubtleStyle.Render(pr.Author.DisplayLogin())
 
-	dates := fmt.Sprintf("Created: %s", pr.CreatedAt.Format("2006-01-02"))
+	dates := fmt.Sprintf("Created: %s", daterange.FormatDate(pr.CreatedAt, m.dateFormat))
 	if mt := pr.MergeTime(); mt != nil {
-		dates += fmt.Sprintf(" • Merged: %s", mt.Format("2006-01-02"))
+		dates += fmt.Sprintf(" • Merged: %s", daterange.FormatDate(*mt, m.dateFormat))
 	} else if pr.ClosedAt != nil {
-		dates += fmt.Sprintf(" • Closed: %s", pr.ClosedAt.Format("2006-01-02"))
+		dates += fmt.Sprintf(" • Closed: %s", daterange.FormatDate(*pr.ClosedAt, m.dateFormat))
 	}
 
 	reviewers := ""
@@ -264,7 +632,7 @@ func formatPR(pr github.PullRequest) string {
 	return styles.PRCardStyle.Render(card.String())
 }
 
-func formatIssue(issue github.Issue) string {
+func (m Model) formatIssue(issue github.Issue) string {
 	var stateStyle lipgloss.Style
 	var stateLabel string
 
@@ -278,12 +646,12 @@ func formatIssue(issue github.Issue) string {
 
 	title := lipgloss.NewStyle().Bold(true).Render(issue.Title)
 	state := stateStyle.Render(fmt.Sprintf("[%s]", stateLabel))
-	repo := styles.SubtleStyle.Render(issue.Repository.NameWithOwner)
-	author := styles.SubtleStyle.Render(fmt.Sprintf("@%s", issue.Author.Login))
+	repo := styles.SubtleStyle.Render(github.RepoLabel(m.repoAliases, issue.Repository.NameWithOwner))
+	author := styles.SubtleStyle.Render(issue.Author.DisplayLogin())
 
-	dates := fmt.Sprintf("Created: %s", issue.CreatedAt.Format("2006-01-02"))
+	dates := fmt.Sprintf("Created: %s", daterange.FormatDate(issue.CreatedAt, m.dateFormat))
 	if issue.ClosedAt != nil {
-		dates += fmt.Sprintf(" • Closed: %s", issue.ClosedAt.Format("2006-01-02"))
+		dates += fmt.Sprintf(" • Closed: %s", daterange.FormatDate(*issue.ClosedAt, m.dateFormat))
 	}
 
 	body := ""
@@ -307,12 +675,12 @@ func formatIssue(issue github.Issue) string {
 	return styles.PRCardStyle.Render(card.String())
 }
 
-func formatReview(r github.Review) string {
+func (m Model) formatReview(r github.Review) string {
 	state := styles.ReviewStyle.Render(fmt.Sprintf("[%s]", strings.ToUpper(r.State)))
 	title := lipgloss.NewStyle().Bold(true).Render(r.Title)
-	repo := styles.SubtleStyle.Render(r.Repository.NameWithOwner)
-	author := styles.SubtleStyle.Render(fmt.Sprintf("by @%s", r.Author.Login))
-	date := r.CreatedAt.Format("2006-01-02")
+	repo := styles.SubtleStyle.Render(github.RepoLabel(m.repoAliases, r.Repository.NameWithOwner))
+	author := styles.SubtleStyle.Render("by " + r.Author.DisplayLogin())
+	date := daterange.FormatDate(r.CreatedAt, m.dateFormat)
 
 	var card strings.Builder
 	card.WriteString(fmt.Sprintf("%s %s\n", title, state))
@@ -321,7 +689,7 @@ func formatReview(r github.Review) string {
 	return styles.PRCardStyle.Render(card.String())
 }
 
-func formatCommit(c github.Commit) string {
+func (m Model) formatCommit(c github.Commit) string {
 	sha := styles.CommitStyle.Render(c.SHA[:min(7, len(c.SHA))])
 	msg := c.Commit.Message
 	if idx := strings.Index(msg, "\n"); idx != -1 {
@@ -330,25 +698,56 @@ func formatCommit(c github.Commit) string {
 	if len(msg) > 80 {
 		msg = msg[:80] + "..."
 	}
-	repo := styles.SubtleStyle.Render(c.Repository.FullName)
-	date := styles.SubtleStyle.Render(c.Commit.Author.Date.Format("2006-01-02"))
+	repo := styles.SubtleStyle.Render(github.RepoLabel(m.repoAliases, c.Repository.FullName))
+	date := styles.SubtleStyle.Render(daterange.FormatDate(c.Commit.Author.Date, m.dateFormat))
 
 	return fmt.Sprintf("  %s %s  %s  %s", sha, msg, repo, date)
 }
 
-func formatCommentedItem(ci github.CommentedItem) string {
+func (m Model) formatCommentedItem(ci github.CommentedItem) string {
 	kind := "Issue"
 	if ci.IsPR {
 		kind = "PR"
 	}
 	kindLabel := styles.SubtleStyle.Render(fmt.Sprintf("[%s]", kind))
 	title := ci.Title
-	repo := styles.SubtleStyle.Render(ci.Repository.NameWithOwner)
+	repo := styles.SubtleStyle.Render(github.RepoLabel(m.repoAliases, ci.Repository.NameWithOwner))
 	comments := styles.SubtleStyle.Render(fmt.Sprintf("%d comments", ci.Comments))
 
 	return fmt.Sprintf("  %s %s  %s  %s", kindLabel, title, repo, comments)
 }
 
+func (m Model) formatDiscussion(d github.Discussion) string {
+	title := lipgloss.NewStyle().Bold(true).Render(d.Title)
+	state := ""
+	if d.Answered {
+		state = " " + styles.MergedStyle.Render("[ANSWERED]")
+	}
+	meta := github.RepoLabel(m.repoAliases, d.Repository.NameWithOwner)
+	if d.Category != "" {
+		meta += " • " + d.Category
+	}
+	meta += fmt.Sprintf(" • %d comments • %s", d.Comments, daterange.FormatDate(d.CreatedAt, m.dateFormat))
+
+	var card strings.Builder
+	card.WriteString(fmt.Sprintf("%s%s\n", title, state))
+	card.WriteString(styles.SubtleStyle.Render(meta) + "\n")
+
+	return styles.PRCardStyle.Render(card.String())
+}
+
+// SetWarnings sets non-fatal fetch warnings shown above the activity
+func (m *Model) SetWarnings(warnings []string) {
+	m.warnings = warnings
+	m.refresh()
+}
+
+// SetAnonymized marks the activity's names as placeholders, which turns off
+// copying links
+func (m *Model) SetAnonymized(anonymized bool) {
+	m.anonymized = anonymized
+}
+
 // SetSize updates the dimensions
 func (m *Model) SetSize(width, height int) {
 	m.width = width
@@ -356,7 +755,7 @@ func (m *Model) SetSize(width, height int) {
 	if m.ready {
 		m.viewport.Width = width
 		m.viewport.Height = height - 5
-		m.viewport.SetContent(m.renderContent())
+		m.refresh()
 	}
 }
 
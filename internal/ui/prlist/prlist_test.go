@@ -0,0 +1,385 @@
+package prlist
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/burritocatai/activitycat/internal/analytics"
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/github"
+	"github.com/burritocatai/activitycat/internal/keys"
+)
+
+var (
+	webRepo = github.Repository{Name: "web", NameWithOwner: "acme/web"}
+	apiRepo = github.Repository{Name: "api", NameWithOwner: "acme/api"}
+)
+
+// testDay returns a time on the given day of March 2025
+func testDay(day int) time.Time {
+	return time.Date(2025, time.March, day, 12, 0, 0, 0, time.UTC)
+}
+
+// testModel returns a model listing PRs, issues, and commits in acme/web
+// and acme/api
+func testModel(t *testing.T) Model {
+	t.Helper()
+	prs := []github.PullRequest{
+		{Number: 1, Title: "Web PR one", State: "open", CreatedAt: testDay(3), Repository: webRepo},
+		{Number: 2, Title: "Web PR two", State: "merged", CreatedAt: testDay(4), Repository: webRepo},
+		{Number: 3, Title: "API PR", State: "open", CreatedAt: testDay(5), Repository: apiRepo},
+	}
+	issues := []github.Issue{
+		{Number: 4, Title: "Web issue", State: "closed", CreatedAt: testDay(6), Repository: webRepo},
+		{Number: 5, Title: "API issue", State: "closed", CreatedAt: testDay(7), Repository: apiRepo},
+	}
+	commits := []github.Commit{
+		{SHA: "aaaaaaa1", Commit: github.CommitDetail{Message: "Web commit", Author: github.CommitAuthor{Date: testDay(8)}},
+			Repository: github.CommitRepository{FullName: "acme/web"}},
+		{SHA: "bbbbbbb2", Commit: github.CommitDetail{Message: "API commit", Author: github.CommitAuthor{Date: testDay(9)}},
+			Repository: github.CommitRepository{FullName: "acme/api"}},
+	}
+	dr := daterange.Range{Start: testDay(1), End: testDay(31)}
+	metrics := analytics.Compute(prs, issues, nil, commits, nil, nil, dr, analytics.Options{})
+	return New(prs, issues, nil, commits, nil, nil, metrics, "", nil, 120, 60)
+}
+
+func press(m Model, msg tea.KeyMsg) Model {
+	m, _ = m.Update(msg)
+	return m
+}
+
+func TestDrillIntoRepo(t *testing.T) {
+	m := testModel(t)
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	if !m.selectingRepo {
+		t.Fatal("Tab didn't start selecting a repo")
+	}
+	for m.repoStats()[m.repoCursor].Repo != "acme/web" {
+		m = press(m, tea.KeyMsg{Type: tea.KeyDown})
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.repoFilter != "acme/web" {
+		t.Fatalf("repoFilter = %q, want acme/web", m.repoFilter)
+	}
+	a := m.visible()
+	if len(a.prs) != 2 || len(a.issues) != 1 || len(a.commits) != 1 {
+		t.Errorf("visible activity = %d PRs, %d issues, %d commits, want 2, 1, 1", len(a.prs), len(a.issues), len(a.commits))
+	}
+	content := m.renderContent()
+	for _, title := range []string{"Web PR one", "Web PR two", "Web issue", "Web commit"} {
+		if !strings.Contains(content, title) {
+			t.Errorf("drilled-in view is missing %q", title)
+		}
+	}
+	for _, title := range []string{"API PR", "API issue", "API commit"} {
+		if strings.Contains(content, title) {
+			t.Errorf("drilled-in view shows %q from another repo", title)
+		}
+	}
+	if view := m.View(); !strings.Contains(view, "in acme/web") {
+		t.Errorf("title doesn't name the repo:\n%s", view)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if m.repoFilter != "" {
+		t.Errorf("b left the repo filter at %q, want the full view", m.repoFilter)
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	dr := daterange.Range{Start: testDay(1), End: testDay(31)}
+	metrics := analytics.Compute(nil, nil, nil, nil, nil, nil, dr, analytics.Options{})
+
+	clean := New(nil, nil, nil, nil, nil, nil, metrics, "", nil, 120, 60)
+	content := clean.renderContent()
+	for _, want := range []string{"No activity found in this date range.", "Things to try:", "Widen the date range", "gh auth status"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("empty view is missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "⚠") {
+		t.Errorf("empty view without warnings shows one:\n%s", content)
+	}
+
+	warned := New(nil, nil, nil, nil, nil, nil, metrics, "", nil, 120, 60)
+	warned.SetWarnings([]string{"Rate limited fetching reviews"})
+	content = warned.renderContent()
+	if !strings.Contains(content, "⚠ Rate limited fetching reviews") {
+		t.Errorf("empty view is missing the warning:\n%s", content)
+	}
+	if strings.Index(content, "Rate limited") > strings.Index(content, "Things to try:") {
+		t.Errorf("the warning should come before the suggestions:\n%s", content)
+	}
+}
+
+func TestEnterOnEmptyGoesBack(t *testing.T) {
+	dr := daterange.Range{Start: testDay(1), End: testDay(31)}
+	m := New(nil, nil, nil, nil, nil, nil, analytics.Compute(nil, nil, nil, nil, nil, nil, dr, analytics.Options{}), "", nil, 120, 60)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Enter returned no command")
+	}
+	if msg := cmd(); msg != (BackMsg{}) {
+		t.Errorf("Enter on an empty list sent %T, want BackMsg", msg)
+	}
+}
+
+func TestRemappedBackCancelsSelecting(t *testing.T) {
+	t.Cleanup(func() { keys.Map = keys.Default() })
+	if err := keys.Load(map[string]string{"back": "h"}); err != nil {
+		t.Fatal(err)
+	}
+	h := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")}
+
+	m := press(testModel(t), tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if !m.selectingItem {
+		t.Fatal("y didn't start selecting an item")
+	}
+	if m = press(m, h); m.selectingItem {
+		t.Error("the remapped back key didn't cancel selecting an item")
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	if !m.selectingRepo {
+		t.Fatal("Tab didn't start selecting a repo")
+	}
+	if m = press(m, h); m.selectingRepo {
+		t.Error("the remapped back key didn't cancel selecting a repo")
+	}
+}
+
+func TestFormatPRDateFormat(t *testing.T) {
+	merged := testDay(10)
+	pr := github.PullRequest{Number: 1, Title: "Web PR", State: "merged", CreatedAt: testDay(4), MergedAt: &merged, Repository: webRepo}
+
+	tests := []struct {
+		layout  string
+		created string
+		merged  string
+	}{
+		{"02 Jan 2006", "Created: 04 Mar 2025", "Merged: 10 Mar 2025"},
+		{"01/02/2006", "Created: 03/04/2025", "Merged: 03/10/2025"},
+	}
+	for _, tt := range tests {
+		if err := daterange.ValidateDateFormat(tt.layout); err != nil {
+			t.Fatalf("ValidateDateFormat(%q): %v", tt.layout, err)
+		}
+		m := New([]github.PullRequest{pr}, nil, nil, nil, nil, nil, nil, tt.layout, nil, 160, 60)
+		got := m.renderContent()
+		for _, want := range []string{tt.created, tt.merged} {
+			if !strings.Contains(got, want) {
+				t.Errorf("with layout %q, PR is missing %q:\n%s", tt.layout, want, got)
+			}
+		}
+	}
+}
+
+func TestRepoAliases(t *testing.T) {
+	prs := []github.PullRequest{{Number: 1, Title: "Web PR", State: "open", CreatedAt: testDay(3), Repository: webRepo}}
+	m := New(prs, nil, nil, nil, nil, nil, nil, "", map[string]string{"Acme/Web": "Web app"}, 160, 60)
+
+	for _, timeline := range []bool{false, true} {
+		m.timeline = timeline
+		got := m.renderContent()
+		if !strings.Contains(got, "Web app") || strings.Contains(got, "acme/web") {
+			t.Errorf("timeline %v: activity should name acme/web by its alias:\n%s", timeline, got)
+		}
+	}
+}
+
+func TestHeaderCounts(t *testing.T) {
+	prs := []github.PullRequest{
+		{Number: 1, Title: "PR one", State: "open", CreatedAt: testDay(3), Repository: webRepo},
+		{Number: 2, Title: "PR two", State: "merged", CreatedAt: testDay(4), Repository: webRepo},
+	}
+	issues := []github.Issue{
+		{Number: 3, Title: "Issue one", State: "open", CreatedAt: testDay(5), Repository: webRepo},
+		{Number: 4, Title: "Issue two", State: "open", CreatedAt: testDay(5), Repository: webRepo},
+		{Number: 5, Title: "Issue three", State: "closed", CreatedAt: testDay(6), Repository: webRepo},
+	}
+	reviews := []github.Review{
+		{Number: 6, Title: "Reviewed", State: "open", CreatedAt: testDay(7), Repository: apiRepo},
+	}
+	commits := []github.Commit{
+		{SHA: "aaaaaaa1", Commit: github.CommitDetail{Message: "One", Author: github.CommitAuthor{Date: testDay(8)}},
+			Repository: github.CommitRepository{FullName: "acme/web"}},
+		{SHA: "bbbbbbb2", Commit: github.CommitDetail{Message: "Two", Author: github.CommitAuthor{Date: testDay(8)}},
+			Repository: github.CommitRepository{FullName: "acme/web"}},
+		{SHA: "ccccccc3", Commit: github.CommitDetail{Message: "Three", Author: github.CommitAuthor{Date: testDay(9)}},
+			Repository: github.CommitRepository{FullName: "acme/web"}},
+		{SHA: "ddddddd4", Commit: github.CommitDetail{Message: "Four", Author: github.CommitAuthor{Date: testDay(9)}},
+			Repository: github.CommitRepository{FullName: "acme/web"}},
+	}
+	commented := []github.CommentedItem{
+		{Number: 7, Title: "Discussed", State: "open", UpdatedAt: testDay(10), Repository: apiRepo, Comments: 2},
+		{Number: 8, Title: "Also discussed", State: "closed", UpdatedAt: testDay(11), Repository: apiRepo, Comments: 1, IsPR: true},
+		{Number: 9, Title: "Chatted", State: "open", UpdatedAt: testDay(12), Repository: apiRepo, Comments: 4},
+		{Number: 10, Title: "Chatted more", State: "open", UpdatedAt: testDay(12), Repository: apiRepo, Comments: 3},
+		{Number: 11, Title: "Last word", State: "open", UpdatedAt: testDay(13), Repository: apiRepo, Comments: 1},
+	}
+	dr := daterange.Range{Start: testDay(1), End: testDay(31)}
+	metrics := analytics.Compute(prs, issues, reviews, commits, commented, nil, dr, analytics.Options{})
+	m := New(prs, issues, reviews, commits, commented, nil, metrics, "", nil, 160, 60)
+
+	view := m.View()
+	for _, want := range []string{"2 PRs", "3 Issues", "1 Reviews", "4 Commits", "5 Commented"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("header is missing %q", want)
+		}
+	}
+	if !strings.Contains(view, legend()) {
+		t.Error("header is missing the state legend")
+	}
+}
+
+func TestEndScrollsToBottom(t *testing.T) {
+	m := testModel(t)
+	m.SetSize(120, 12)
+	if m.viewport.AtBottom() {
+		t.Fatal("the activity fits on screen, so there is nothing to scroll")
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnd})
+	if !m.viewport.AtBottom() {
+		t.Errorf("End left the viewport at offset %d, not the bottom", m.viewport.YOffset)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyHome})
+	if !m.viewport.AtTop() {
+		t.Errorf("Home left the viewport at offset %d, not the top", m.viewport.YOffset)
+	}
+}
+
+func TestTimelineInterleaves(t *testing.T) {
+	closed := testDay(6)
+	a := activity{
+		prs:    []github.PullRequest{{Number: 1, Title: "Add caching", CreatedAt: testDay(3), Repository: webRepo}},
+		issues: []github.Issue{{Number: 2, Title: "Slow page", CreatedAt: testDay(1), ClosedAt: &closed, Repository: webRepo}},
+		reviews: []github.Review{
+			{Number: 3, Title: "Fix login", CreatedAt: testDay(5), Repository: apiRepo},
+			{Number: 4, Title: "Old export", Repository: apiRepo},
+		},
+		commits: []github.Commit{{SHA: "aaaaaaa1", Commit: github.CommitDetail{Message: "Tune cache\n\nDetails", Author: github.CommitAuthor{Date: testDay(2)}},
+			Repository: github.CommitRepository{FullName: "acme/web"}}},
+		commentedItems: []github.CommentedItem{{Number: 5, Title: "Flaky test", UpdatedAt: testDay(4), Repository: apiRepo}},
+	}
+
+	var got []string
+	for _, e := range timeline(a, nil) {
+		got = append(got, e.summary)
+	}
+	want := []string{
+		"Commit acme/web Tune cache",
+		"PR acme/web#1 Add caching",
+		"Commented on acme/api#5 Flaky test",
+		"Reviewed acme/api#3 Fix login",
+		"Closed acme/web#2 Slow page",
+		"Reviewed acme/api#4 Old export",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("timeline =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestScrollKeptAcrossViews(t *testing.T) {
+	var prs []github.PullRequest
+	for i := 1; i <= 60; i++ {
+		prs = append(prs, github.PullRequest{Number: i, Title: "PR", State: "open", CreatedAt: testDay(i%28 + 1), Repository: webRepo})
+	}
+	dr := daterange.Range{Start: testDay(1), End: testDay(31)}
+	m := New(prs, nil, nil, nil, nil, nil, analytics.Compute(prs, nil, nil, nil, nil, nil, dr, analytics.Options{}), "", nil, 120, 30)
+	timelineKey := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")}
+
+	m.viewport.SetYOffset(40)
+	m = press(m, timelineKey)
+	if !m.timeline || m.viewport.YOffset != 40 {
+		t.Fatalf("timeline = %v, YOffset = %d after switching, want the timeline at 40", m.timeline, m.viewport.YOffset)
+	}
+
+	m.viewport.SetYOffset(10)
+	m = press(m, timelineKey)
+	if m.timeline || m.viewport.YOffset != 40 {
+		t.Errorf("YOffset = %d back in the cards, want 40 where they were left", m.viewport.YOffset)
+	}
+	m = press(m, timelineKey)
+	if m.viewport.YOffset != 10 {
+		t.Errorf("YOffset = %d back in the timeline, want 10 where it was left", m.viewport.YOffset)
+	}
+
+	// An offset past the end of shorter content is clamped
+	m.viewport.YOffset = 1 << 20
+	m.refresh()
+	if !m.viewport.AtBottom() || m.viewport.YOffset >= 1<<20 {
+		t.Errorf("YOffset = %d, want it clamped to the bottom", m.viewport.YOffset)
+	}
+}
+
+func TestDiscussionsSection(t *testing.T) {
+	discussions := []github.Discussion{
+		{Number: 5, Title: "Roadmap", Category: "Ideas", Comments: 3, Answered: true, CreatedAt: testDay(8), Repository: webRepo},
+	}
+	dr := daterange.Range{Start: testDay(1), End: testDay(31)}
+	metrics := analytics.Compute(nil, nil, nil, nil, nil, discussions, dr, analytics.Options{})
+	view := New(nil, nil, nil, nil, nil, discussions, metrics, "", nil, 160, 60).View()
+	for _, want := range []string{"1 Discussions", "Discussions Started", "Roadmap", "[ANSWERED]", "Ideas"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view is missing %q", want)
+		}
+	}
+
+	if view := testModel(t).View(); strings.Contains(view, "Discussions") {
+		t.Error("view has a discussions section with none fetched")
+	}
+}
+
+func TestCopyLinkTargetsSelectedItem(t *testing.T) {
+	t.Setenv("GH_HOST", "")
+	m := testModel(t)
+	yank := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}
+
+	m = press(m, yank)
+	if !m.selectingItem || m.itemCursor != 0 {
+		t.Fatalf("selectingItem = %v, itemCursor = %d after y, want the first item highlighted", m.selectingItem, m.itemCursor)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyDown})
+
+	m, cmd := m.Update(yank)
+	if m.selectingItem || cmd == nil {
+		t.Fatalf("selectingItem = %v after copying, want selection ended with a copy command", m.selectingItem)
+	}
+	copied, ok := cmd().(copiedMsg)
+	if want := "https://github.com/acme/web/pull/2"; !ok || copied.url != want {
+		t.Errorf("copied %+v, want the second item's link %s", copied, want)
+	}
+}
+
+func TestCopyLinkAnonymized(t *testing.T) {
+	m := testModel(t)
+	m.SetAnonymized(true)
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if m.selectingItem {
+		t.Error("y started selecting an item to copy while anonymized")
+	}
+	if cmd == nil || !strings.Contains(m.notice, "anonymizing") {
+		t.Errorf("notice = %q after y, want copying refused while anonymizing", m.notice)
+	}
+}
+
+func TestHeaderSparkline(t *testing.T) {
+	m := testModel(t)
+	if want := analytics.Sparkline(m.metrics.DailyActivity) + " last 30 days"; !strings.Contains(m.View(), want) {
+		t.Errorf("header is missing the sparkline %q:\n%s", want, m.View())
+	}
+
+	// A single day has no sparkline
+	m.metrics.DailyActivity = m.metrics.DailyActivity[:1]
+	if strings.Contains(m.View(), "last 1 days") {
+		t.Errorf("header shows a sparkline for a single day:\n%s", m.View())
+	}
+}
@@ -0,0 +1,101 @@
+package prlist
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/burritocatai/activitycat/internal/daterange"
+	"github.com/burritocatai/activitycat/internal/github"
+)
+
+// timelineEntry is one item in the chronological view
+type timelineEntry struct {
+	at      time.Time
+	glyph   string
+	summary string
+	url     string
+}
+
+// Glyphs mark each type of activity, colored like its section heading
+var (
+	prGlyph         = lipgloss.NewStyle().Foreground(lipgloss.Color("4")).Render("⇡")
+	issueGlyph      = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render("✓")
+	reviewGlyph     = lipgloss.NewStyle().Foreground(lipgloss.Color("135")).Render("◆")
+	commitGlyph     = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Render("•")
+	commentGlyph    = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("✎")
+	discussionGlyph = lipgloss.NewStyle().Foreground(lipgloss.Color("37")).Render("◎")
+)
+
+// timeline merges all activity into one list, oldest first. Each item is
+// placed at the time it happened in the range: when an issue was closed, a
+// commit authored, or a commented item last updated, and when PRs, reviewed
+// PRs, and discussions were opened. Repositories are labelled from
+// repoAliases.
+func timeline(a activity, repoAliases map[string]string) []timelineEntry {
+	var entries []timelineEntry
+	for _, pr := range a.prs {
+		entries = append(entries, timelineEntry{pr.CreatedAt, prGlyph,
+			fmt.Sprintf("PR %s#%d %s", github.RepoLabel(repoAliases, pr.Repository.NameWithOwner), pr.Number, pr.Title), pr.URL()})
+	}
+	for _, issue := range a.issues {
+		at := issue.CreatedAt
+		if issue.ClosedAt != nil {
+			at = *issue.ClosedAt
+		}
+		entries = append(entries, timelineEntry{at, issueGlyph,
+			fmt.Sprintf("Closed %s#%d %s", github.RepoLabel(repoAliases, issue.Repository.NameWithOwner), issue.Number, issue.Title), issue.URL()})
+	}
+	for _, r := range a.reviews {
+		entries = append(entries, timelineEntry{r.CreatedAt, reviewGlyph,
+			fmt.Sprintf("Reviewed %s#%d %s", github.RepoLabel(repoAliases, r.Repository.NameWithOwner), r.Number, r.Title), r.URL()})
+	}
+	for _, c := range a.commits {
+		msg, _, _ := strings.Cut(c.Commit.Message, "\n")
+		entries = append(entries, timelineEntry{c.Commit.Author.Date, commitGlyph,
+			fmt.Sprintf("Commit %s %s", github.RepoLabel(repoAliases, c.Repository.FullName), msg), c.URL()})
+	}
+	for _, ci := range a.commentedItems {
+		entries = append(entries, timelineEntry{ci.UpdatedAt, commentGlyph,
+			fmt.Sprintf("Commented on %s#%d %s", github.RepoLabel(repoAliases, ci.Repository.NameWithOwner), ci.Number, ci.Title), ci.URL()})
+	}
+	for _, d := range a.discussions {
+		entries = append(entries, timelineEntry{d.CreatedAt, discussionGlyph,
+			fmt.Sprintf("Discussion %s#%d %s", github.RepoLabel(repoAliases, d.Repository.NameWithOwner), d.Number, d.Title), d.URL()})
+	}
+
+	// Undated items, e.g. from an older export, go last
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].at.IsZero() != entries[j].at.IsZero() {
+			return entries[j].at.IsZero()
+		}
+		return entries[i].at.Before(entries[j].at)
+	})
+	return entries
+}
+
+// renderTimeline lists the entries under a heading for each day, in
+// dateFormat
+func renderTimeline(items *itemWriter, entries []timelineEntry, dateFormat string) {
+	sb := items.content
+	day := ""
+	for _, e := range entries {
+		heading := "Undated"
+		if !e.at.IsZero() {
+			heading = daterange.FormatDate(e.at.Local(), dateFormat)
+		}
+		if heading != day {
+			if day != "" {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(lipgloss.NewStyle().Bold(true).Render(heading))
+			sb.WriteString("\n")
+			day = heading
+		}
+		items.write(e.url, fmt.Sprintf("  %s %s", e.glyph, e.summary))
+		sb.WriteString("\n")
+	}
+}
@@ -1,89 +1,299 @@
 package promptselect
 
 import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/burritocatai/activitycat/internal/config"
+	"github.com/burritocatai/activitycat/internal/keys"
 	"github.com/burritocatai/activitycat/internal/ui/styles"
 )
 
+// item is a prompt in the list, with its index in the full prompt list
+type item struct {
+	prompt   config.Prompt
+	index    int
+	chosen   bool
+	toggling bool // some prompt is toggled, so every item shows a checkbox
+	estimate string
+}
+
+// Title returns the prompt name, with a checkbox while toggling prompts
+func (i item) Title() string {
+	title := i.prompt.Name
+	if i.prompt.BuiltIn {
+		title += " (built-in)"
+	}
+	if !i.toggling {
+		return title
+	}
+	if i.chosen {
+		return "[x] " + title
+	}
+	return "[ ] " + title
+}
+
+// Description returns the prompt's description and any cost estimate
+func (i item) Description() string {
+	desc := describe(i.prompt)
+	if i.estimate != "" {
+		desc = i.estimate + " estimated • " + desc
+	}
+	return desc
+}
+
+// FilterValue matches filter text against the name and description
+func (i item) FilterValue() string {
+	return i.prompt.Name + " " + describe(i.prompt)
+}
+
+// describe returns a one-line description of p: its "description"
+// frontmatter, or else the first non-blank line of its content
+func describe(p config.Prompt) string {
+	if desc := p.Frontmatter["description"]; desc != "" {
+		return desc
+	}
+	if p.MetricsOnly {
+		return "The computed metrics and repository breakdown, without calling a model"
+	}
+	for _, line := range strings.Split(p.Content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
 // Model represents the prompt selection screen
 type Model struct {
 	prompts  []config.Prompt
-	cursor   int
+	list     list.Model
 	selected bool
+	chosen   map[int]bool // prompts toggled for a combined report, by index
+	err      string
+	warnings []string
+
+	estimates map[string]string // estimated cost by prompt name
 }
 
 // New creates a new prompt selection model
-func New(prompts []config.Prompt) Model {
-	return Model{
+func New(prompts []config.Prompt, width, height int) Model {
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(lipgloss.Color("170")).BorderForeground(lipgloss.Color("170"))
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
+		Foreground(lipgloss.Color("241")).BorderForeground(lipgloss.Color("170"))
+
+	l := list.New(nil, delegate, 0, 0)
+	l.Title = "Select Report Prompt"
+	l.Styles.Title = styles.TitleStyle
+	l.SetShowHelp(false)
+	l.SetStatusBarItemName("prompt", "prompts")
+	l.DisableQuitKeybindings()
+	l.KeyMap.CursorUp = keys.Map.Up
+	l.KeyMap.CursorDown = keys.Map.Down
+	l.KeyMap.PrevPage = keys.Map.PageUp
+	l.KeyMap.NextPage = keys.Map.PageDown
+	l.KeyMap.GoToStart = keys.Map.Top
+	l.KeyMap.GoToEnd = keys.Map.Bottom
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
+
+	m := Model{
 		prompts:  prompts,
-		cursor:   0,
+		list:     l,
 		selected: false,
+		chosen:   make(map[int]bool),
+	}
+	m.list.SetItems(m.items())
+	m.SetSize(width, height)
+	return m
+}
+
+// items builds the list items from the prompts and their toggled state
+func (m Model) items() []list.Item {
+	items := make([]list.Item, len(m.prompts))
+	for i, p := range m.prompts {
+		items[i] = item{
+			prompt:   p,
+			index:    i,
+			chosen:   m.chosen[i],
+			toggling: len(m.chosen) > 0,
+			estimate: m.estimates[p.Name],
+		}
+	}
+	return items
+}
+
+// current returns the highlighted item, if any prompt matches the filter
+func (m Model) current() (item, bool) {
+	it, ok := m.list.SelectedItem().(item)
+	return it, ok
+}
+
+// SetSize fits the list to the screen, leaving room for the footer
+func (m *Model) SetSize(width, height int) {
+	m.list.SetSize(width, max(height-4, 0))
+}
+
+// Select moves the cursor to the prompt with the given name, if present
+func (m *Model) Select(name string) {
+	for i, p := range m.prompts {
+		if p.Name == name {
+			m.list.Select(i)
+			return
+		}
 	}
 }
 
+// SetEstimates shows an estimated cost, e.g. "~$0.03", next to each named prompt
+func (m *Model) SetEstimates(estimates map[string]string) {
+	m.estimates = estimates
+	m.list.SetItems(m.items())
+}
+
 // Init initializes the prompt selection model
 func (m Model) Init() tea.Cmd {
 	return nil
 }
 
-// Update handles messages for the prompt selection screen
+// Update handles messages for the prompt selection screen. While filter text
+// is being typed, every key goes to the list.
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "up", "k":
-			if m.cursor > 0 {
-				m.cursor--
+	if msg, ok := msg.(tea.KeyMsg); ok && !m.list.SettingFilter() {
+		current, ok := m.current()
+		switch {
+		case !ok:
+			// Nothing matches the filter, so only the list's keys apply
+		case key.Matches(msg, keys.Map.Toggle):
+			if m.chosen[current.index] {
+				delete(m.chosen, current.index)
+			} else {
+				m.chosen[current.index] = true
 			}
-		case "down", "j":
-			if m.cursor < len(m.prompts)-1 {
-				m.cursor++
+			return m, m.list.SetItems(m.items())
+		case key.Matches(msg, keys.Map.Select) && len(m.chosen) > 0:
+			m.selected = true
+			var prompts []config.Prompt
+			for i, p := range m.prompts {
+				if m.chosen[i] {
+					prompts = append(prompts, p)
+				}
 			}
-		case "enter":
+			return m, func() tea.Msg {
+				return PromptsSelectedMsg{Prompts: prompts}
+			}
+		case key.Matches(msg, keys.Map.Select, keys.Map.Edit):
 			m.selected = true
+			edit := key.Matches(msg, keys.Map.Edit)
 			return m, func() tea.Msg {
 				return PromptSelectedMsg{
-					Prompt: m.prompts[m.cursor],
+					Prompt: current.prompt,
+					Edit:   edit,
 				}
 			}
-		case "b":
+		case key.Matches(msg, keys.Map.Duplicate):
+			dup, err := config.DuplicatePrompt(current.prompt)
+			if err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			m.err = ""
+			return m, editPrompt(dup)
+		}
+
+		switch {
+		case key.Matches(msg, keys.Map.Back):
 			return m, func() tea.Msg {
 				return BackMsg{}
 			}
-		case "q", "ctrl+c":
+		case key.Matches(msg, keys.Map.Quit):
 			return m, tea.Quit
 		}
 	}
 
-	return m, nil
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
 }
 
 // View renders the prompt selection screen
 func (m Model) View() string {
-	s := styles.TitleStyle.Render("Select Report Prompt")
-	s += "\n\n"
-
-	for i, prompt := range m.prompts {
-		cursor := "  "
-		if m.cursor == i {
-			cursor = "> "
-			s += styles.SelectedStyle.Render(cursor + prompt.Name)
-		} else {
-			s += styles.UnselectedStyle.Render(cursor + prompt.Name)
-		}
-		s += "\n"
+	s := m.list.View() + "\n"
+
+	for _, w := range m.warnings {
+		s += "\n" + styles.WarningStyle.Render("⚠ "+w)
+	}
+	if m.err != "" {
+		s += "\n" + styles.ErrorStyle.Render("✗ Error: "+m.err)
 	}
 
-	s += "\n" + styles.FooterStyle.Render("↑/↓: Navigate • Enter: Select • b: Back • q: Quit")
+	k := keys.Map
+	switch {
+	case m.list.SettingFilter():
+		s += "\n" + styles.FooterStyle.Render("Type to filter • Enter: Apply filter • Esc: Cancel")
+	case len(m.chosen) > 0:
+		s += "\n" + styles.FooterStyle.Render(fmt.Sprintf("%s: Navigate • %s: Toggle • %s: Generate %d sections • %s: Back • %s: Quit",
+			k.Nav(), k.Toggle.Help().Key, k.Select.Help().Key, len(m.chosen), k.Back.Help().Key, k.Quit.Help().Key))
+	default:
+		s += "\n" + styles.FooterStyle.Render(fmt.Sprintf("%s: Navigate • %s: Select • %s: Toggle • %s: Edit & Select • %s: Duplicate • /: Filter • %s: Back • %s: Quit",
+			k.Nav(), k.Select.Help().Key, k.Toggle.Help().Key, k.Edit.Help().Key, k.Duplicate.Help().Key, k.Back.Help().Key, k.Quit.Help().Key))
+	}
 
 	return s
 }
 
+// SetError shows an error below the prompt list
+func (m *Model) SetError(err error) {
+	m.err = err.Error()
+}
+
+// SetWarnings shows warnings below the prompt list, such as prompt files
+// skipped because another has the same name
+func (m *Model) SetWarnings(warnings []string) {
+	m.warnings = warnings
+}
+
+// editPrompt opens the prompt file in the user's editor and reports back once
+// the editor exits so the prompt list can be reloaded
+func editPrompt(p config.Prompt) tea.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := append(strings.Fields(editor), p.Path)
+
+	return tea.ExecProcess(exec.Command(args[0], args[1:]...), func(err error) tea.Msg {
+		return PromptsChangedMsg{Select: p.Name, Error: err}
+	})
+}
+
 // PromptSelectedMsg is sent when a prompt is selected
 type PromptSelectedMsg struct {
 	Prompt config.Prompt
+	Edit   bool // review and edit the assembled message before generating
+}
+
+// PromptsSelectedMsg is sent when several prompts are chosen for a combined
+// report, in list order
+type PromptsSelectedMsg struct {
+	Prompts []config.Prompt
 }
 
 // BackMsg is sent when the user wants to go back
 type BackMsg struct{}
+
+// PromptsChangedMsg is sent when prompt files have changed on disk
+type PromptsChangedMsg struct {
+	Select string // name of the prompt to highlight after reloading
+	Error  error
+}
@@ -0,0 +1,79 @@
+package promptselect
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/burritocatai/activitycat/internal/config"
+)
+
+// testPrompts are the prompts listed in the tests
+var testPrompts = []config.Prompt{
+	{Name: "weekly", Content: "Summarize my week."},
+	{Name: "standup", Content: "\nWhat did I do yesterday?", Frontmatter: map[string]string{}},
+	{Name: "release-notes", Content: "Write release notes.", Frontmatter: map[string]string{"description": "Notes for a release"}},
+}
+
+// press sends msg to m and returns the updated model with the message its
+// command sends, if any
+func press(m Model, msg tea.Msg) (Model, tea.Msg) {
+	m, cmd := m.Update(msg)
+	if cmd == nil {
+		return m, nil
+	}
+	return m, cmd()
+}
+
+// runes returns the key message for typing s
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestSelectPrompt(t *testing.T) {
+	m := New(testPrompts, 80, 30)
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyDown})
+
+	_, sent := press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := sent.(PromptSelectedMsg)
+	if !ok || msg.Prompt.Name != "standup" || msg.Edit {
+		t.Errorf("Enter sent %#v, want the standup prompt selected", sent)
+	}
+
+	_, sent = press(m, runes("e"))
+	if msg, ok := sent.(PromptSelectedMsg); !ok || msg.Prompt.Name != "standup" || !msg.Edit {
+		t.Errorf("e sent %#v, want the standup prompt selected for editing", sent)
+	}
+}
+
+func TestSelectFilteredPrompt(t *testing.T) {
+	m := New(testPrompts, 80, 30)
+	m.list.SetFilterText("release")
+
+	_, sent := press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := sent.(PromptSelectedMsg); !ok || msg.Prompt.Name != "release-notes" {
+		t.Errorf("Enter after filtering sent %#v, want the release-notes prompt selected", sent)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	for i, want := range []string{"Summarize my week.", "What did I do yesterday?", "Notes for a release"} {
+		if got := describe(testPrompts[i]); got != want {
+			t.Errorf("describe(%s) = %q, want %q", testPrompts[i].Name, got, want)
+		}
+	}
+}
+
+func TestSelectCombinedPrompts(t *testing.T) {
+	m := New(testPrompts, 80, 30)
+	m, _ = press(m, runes(" "))
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = press(m, runes(" "))
+
+	_, sent := press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := sent.(PromptsSelectedMsg)
+	if !ok || len(msg.Prompts) != 2 || msg.Prompts[0].Name != "weekly" || msg.Prompts[1].Name != "release-notes" {
+		t.Errorf("Enter sent %#v, want the toggled weekly and release-notes prompts", sent)
+	}
+}
@@ -0,0 +1,52 @@
+package report
+
+import "strings"
+
+// NormalizeHeadings shifts every ATX heading by the same amount so the
+// highest one is at level base, keeping their relative nesting. Headings that
+// would go past ###### stay at ######. Headings inside fenced code blocks are
+// left alone, and a base of 0 or a document without headings is returned
+// unchanged.
+func NormalizeHeadings(markdown string, base int) string {
+	if base <= 0 {
+		return markdown
+	}
+	lines := strings.Split(markdown, "\n")
+
+	top := 0
+	forEachHeading(lines, func(_ int, level int) {
+		if top == 0 || level < top {
+			top = level
+		}
+	})
+	if top == 0 || top == base {
+		return markdown
+	}
+
+	shift := base - top
+	forEachHeading(lines, func(i int, level int) {
+		line := lines[i]
+		start := strings.Index(line, "#")
+		lines[i] = line[:start] + strings.Repeat("#", min(level+shift, 6)) + line[start+level:]
+	})
+	return strings.Join(lines, "\n")
+}
+
+// forEachHeading calls fn with the index and level of each ATX heading line
+// outside fenced code blocks
+func forEachHeading(lines []string, fn func(i, level int)) {
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if level, _ := heading(trimmed); level > 0 {
+			fn(i, level)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package report
+
+import "testing"
+
+func TestNormalizeHeadings(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		base     int
+		want     string
+	}{
+		{
+			"from #",
+			"# Report\nIntro\n## Wins\n### Details",
+			2,
+			"## Report\nIntro\n### Wins\n#### Details",
+		},
+		{
+			"from ###",
+			"### Report\n#### Wins\n### Next",
+			2,
+			"## Report\n### Wins\n## Next",
+		},
+		{
+			"capped at ######",
+			"# Report\n##### Deep",
+			3,
+			"### Report\n###### Deep",
+		},
+		{
+			"code blocks left alone",
+			"# Report\n```\n# comment\n```\n## Wins",
+			2,
+			"## Report\n```\n# comment\n```\n### Wins",
+		},
+		{
+			"already at the base",
+			"## Report\n### Wins",
+			2,
+			"## Report\n### Wins",
+		},
+		{
+			"no base",
+			"# Report",
+			0,
+			"# Report",
+		},
+		{
+			"no headings",
+			"Just text\n#hashtag",
+			2,
+			"Just text\n#hashtag",
+		},
+	}
+	for _, tt := range tests {
+		if got := NormalizeHeadings(tt.markdown, tt.base); got != tt.want {
+			t.Errorf("%s: NormalizeHeadings = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -6,10 +6,14 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/burritocatai/activitycat/internal/keys"
 	"github.com/burritocatai/activitycat/internal/ui/styles"
 )
 
@@ -24,6 +28,26 @@ type Model struct {
 	saveMode  bool
 	saveError string
 	saved     bool
+	savedPath string
+	badge     string
+	bundle    string
+	note      string
+	outputDir string
+	// partial marks a report cut short by cancelling generation, which can
+	// be regenerated
+	partial bool
+
+	// metrics is saved above the report when withMetrics is set
+	metrics     string
+	withMetrics bool
+	// withTOC adds a table of contents to saved Markdown
+	withTOC bool
+	// quitAfterSave quits once the file being named is saved
+	quitAfterSave bool
+	// rendered shows the report styled by glamour instead of as raw
+	// Markdown, in its dark or light style
+	rendered bool
+	dark     bool
 }
 
 // New creates a new report model
@@ -46,7 +70,8 @@ func New(report string, width, height int) Model {
 	// Initialize viewport immediately if dimensions are provided
 	if width > 0 && height > 0 {
 		m.viewport = viewport.New(width, height-4)
-		m.viewport.SetContent(styles.ReportStyle.Render(report))
+		m.viewport.KeyMap = keys.ViewportKeyMap()
+		m.setContent()
 		m.ready = true
 	}
 
@@ -66,25 +91,23 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case tea.KeyMsg:
 		// If in save mode, handle text input
 		if m.saveMode {
+			if key.Matches(msg, keys.Map.SaveQuit) {
+				m.quitAfterSave = true
+				return m.submitSave()
+			}
 			switch msg.String() {
 			case "enter":
-				// Save the file
-				filename := strings.TrimSpace(m.textInput.Value())
-				if filename == "" {
-					filename = "report.md"
-				}
-				if err := m.saveReport(filename); err != nil {
-					m.saveError = err.Error()
-				} else {
-					m.saved = true
-					m.saveError = ""
-				}
-				m.saveMode = false
-				m.textInput.SetValue("")
+				return m.submitSave()
+			case "tab":
+				m.withMetrics = !m.withMetrics && m.metrics != ""
+				return m, nil
+			case "ctrl+t":
+				m.withTOC = !m.withTOC
 				return m, nil
 			case "esc":
 				// Cancel save
 				m.saveMode = false
+				m.quitAfterSave = false
 				m.saveError = ""
 				m.textInput.SetValue("")
 				return m, nil
@@ -96,16 +119,28 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 
 		// Normal mode key handling
-		switch msg.String() {
-		case "q", "ctrl+c":
+		if keys.Page(&m.viewport, msg) {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, keys.Map.Quit):
 			return m, tea.Quit
-		case "b":
+		case key.Matches(msg, keys.Map.Back):
 			return m, func() tea.Msg {
 				return BackMsg{}
 			}
-		case "s":
-			// Enter save mode
+		case m.partial && key.Matches(msg, keys.Map.Retry):
+			return m, func() tea.Msg {
+				return RegenerateMsg{}
+			}
+		case key.Matches(msg, keys.Map.Render):
+			m.rendered = !m.rendered
+			m.setContent()
+			return m, nil
+		case key.Matches(msg, keys.Map.Save, keys.Map.SaveQuit):
+			// Enter save mode, quitting after the save for SaveQuit
 			m.saveMode = true
+			m.quitAfterSave = key.Matches(msg, keys.Map.SaveQuit)
 			m.saved = false
 			m.saveError = ""
 			m.textInput.Focus()
@@ -118,12 +153,13 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 		if !m.ready {
 			m.viewport = viewport.New(msg.Width, msg.Height-4)
-			m.viewport.SetContent(styles.ReportStyle.Render(m.report))
+			m.viewport.KeyMap = keys.ViewportKeyMap()
+			m.setContent()
 			m.ready = true
 		} else {
 			m.viewport.Width = msg.Width
 			m.viewport.Height = msg.Height - 4
-			m.viewport.SetContent(styles.ReportStyle.Render(m.report))
+			m.setContent()
 		}
 	}
 
@@ -134,13 +170,44 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
+// submitSave saves to the entered filename, or report.md if none was given.
+// After a successful save and quit it quits; on failure the error is shown
+// and the screen stays open.
+func (m Model) submitSave() (Model, tea.Cmd) {
+	filename := strings.TrimSpace(m.textInput.Value())
+	if filename == "" {
+		filename = "report.md"
+	}
+	quit := m.quitAfterSave
+	m.saveMode = false
+	m.quitAfterSave = false
+	m.textInput.SetValue("")
+	if err := m.saveReport(filename); err != nil {
+		m.saveError = err.Error()
+		return m, nil
+	}
+	m.saved = true
+	m.saveError = ""
+	if quit {
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
 // View renders the report screen
 func (m Model) View() string {
 	if !m.ready {
 		return "\n  Initializing..."
 	}
 
-	header := styles.TitleStyle.Render("Generated Report")
+	title := "Generated Report"
+	if m.partial {
+		title += " (partial)"
+	}
+	header := styles.TitleStyle.Render(title)
+	if m.note != "" {
+		header = lipgloss.JoinHorizontal(lipgloss.Top, header, styles.SubtleStyle.Render("  "+m.note))
+	}
 
 	var footer string
 	if m.saveMode {
@@ -148,25 +215,25 @@ func (m Model) View() string {
 		footer = lipgloss.JoinVertical(
 			lipgloss.Left,
 			"",
-			"Save report to file:",
+			m.savePrompt(),
 			m.textInput.View(),
 			"",
-			styles.SubtleStyle.Render("Enter: Save • Esc: Cancel"),
+			styles.SubtleStyle.Render(m.saveHelp()),
 		)
 	} else if m.saved {
 		footer = lipgloss.JoinVertical(
 			lipgloss.Left,
-			styles.MergedStyle.Render("✓ Report saved successfully!"),
-			styles.FooterStyle.Render("↑/↓: Scroll • s: Save • b: Back • q: Quit"),
+			styles.MergedStyle.Render("✓ Saved to "+m.savedPath),
+			styles.FooterStyle.Render(m.helpText()),
 		)
 	} else if m.saveError != "" {
 		footer = lipgloss.JoinVertical(
 			lipgloss.Left,
 			styles.ErrorStyle.Render("✗ Error: "+m.saveError),
-			styles.FooterStyle.Render("↑/↓: Scroll • s: Save • b: Back • q: Quit"),
+			styles.FooterStyle.Render(m.helpText()),
 		)
 	} else {
-		footer = styles.FooterStyle.Render("↑/↓: Scroll • s: Save • b: Back • q: Quit")
+		footer = styles.FooterStyle.Render(m.helpText())
 	}
 
 	return lipgloss.JoinVertical(
@@ -177,6 +244,33 @@ func (m Model) View() string {
 	)
 }
 
+// savePrompt asks for a filename, saying where relative names are saved
+func (m Model) savePrompt() string {
+	if m.outputDir != "" {
+		return "Save report to file in " + m.outputDir + " (.svg saves a metrics badge, .json a bundle with metadata):"
+	}
+	return "Save report to file (.svg saves a metrics badge, .json a bundle with metadata):"
+}
+
+// saveHelp lists the save prompt's keys, with the metrics toggle when there
+// are metrics to include and the table of contents toggle
+func (m Model) saveHelp() string {
+	check := func(on bool) string {
+		if on {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	help := "Enter: Save • "
+	if m.quitAfterSave {
+		help = "Enter: Save and quit • "
+	}
+	if m.metrics != "" {
+		help += "Tab: " + check(m.withMetrics) + " Include metrics • "
+	}
+	return help + "Ctrl+T: " + check(m.withTOC) + " Table of contents • Esc: Cancel"
+}
+
 // SetSize updates the dimensions
 func (m *Model) SetSize(width, height int) {
 	m.width = width
@@ -184,49 +278,194 @@ func (m *Model) SetSize(width, height int) {
 	if m.ready {
 		m.viewport.Width = width
 		m.viewport.Height = height - 4
-		m.viewport.SetContent(styles.ReportStyle.Render(m.report))
+		m.setContent()
 	}
 }
 
-// saveReport saves the report to a file
-func (m *Model) saveReport(filename string) error {
-	// Expand home directory if needed
-	if strings.HasPrefix(filename, "~") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("could not get home directory: %w", err)
+// setContent shows the report in the viewport, wrapped to fit inside the
+// report's padding so long lines aren't cut off, or rendered by glamour to
+// the viewport's width
+func (m *Model) setContent() {
+	if m.rendered {
+		if rendered, err := renderMarkdown(m.report, m.viewport.Width, m.dark); err == nil {
+			m.viewport.SetContent(rendered)
+			return
 		}
-		filename = filepath.Join(home, filename[1:])
 	}
+	width := m.viewport.Width - styles.ReportStyle.GetHorizontalFrameSize()
+	m.viewport.SetContent(styles.ReportStyle.Render(wrapReport(m.report, width)))
+}
 
-	// Make sure the filename has an extension
-	if filepath.Ext(filename) == "" {
-		filename += ".md"
+// renderMarkdown styles markdown for the terminal with glamour's dark or
+// light style, wrapped to width. Words too long to wrap are broken so
+// nothing is cut off.
+func renderMarkdown(markdown string, width int, dark bool) (string, error) {
+	style := "light"
+	if dark {
+		style = "dark"
+	}
+	r, err := glamour.NewTermRenderer(glamour.WithStandardStyle(style), glamour.WithWordWrap(width))
+	if err != nil {
+		return "", err
+	}
+	rendered, err := r.Render(markdown)
+	if err != nil {
+		return "", err
 	}
+	return ansi.Hardwrap(rendered, width, true), nil
+}
 
-	// Create parent directories if needed
-	dir := filepath.Dir(filename)
-	if dir != "." && dir != "" {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("could not create directory: %w", err)
+// helpText lists the report screen's keys, with regenerating for a partial report
+func (m Model) helpText() string {
+	k := keys.Map
+	regenerate := ""
+	if m.partial {
+		regenerate = k.Retry.Help().Key + ": Regenerate • "
+	}
+	view := "Rendered"
+	if m.rendered {
+		view = "Raw"
+	}
+	return fmt.Sprintf("%s: Scroll • %s: Page • %s: %s • %s: Save • %s: Save and quit • %s%s: Back • %s: Quit",
+		k.Nav(), k.Paging(), k.Render.Help().Key, view, k.Save.Help().Key, k.SaveQuit.Help().Key, regenerate, k.Back.Help().Key, k.Quit.Help().Key)
+}
+
+// SetPartial marks the report as cut short by cancelling generation, and
+// offers to regenerate it
+func (m *Model) SetPartial(partial bool) {
+	m.partial = partial
+}
+
+// SetOutputDir sets the directory relative filenames are saved in
+func (m *Model) SetOutputDir(dir string) {
+	m.outputDir = dir
+}
+
+// SetNote shows a short note next to the report title
+func (m *Model) SetNote(note string) {
+	m.note = note
+}
+
+// SetMetrics sets a Markdown metrics section that can be saved above the
+// report, included by default if include is set
+func (m *Model) SetMetrics(section string, include bool) {
+	m.metrics = section
+	m.withMetrics = include && section != ""
+}
+
+// SetRendered sets whether the report is shown rendered by glamour rather
+// than as raw Markdown, and whether to use its style for dark backgrounds
+func (m *Model) SetRendered(rendered, dark bool) {
+	m.rendered, m.dark = rendered, dark
+	if m.ready {
+		m.setContent()
+	}
+}
+
+// SetTOC sets whether saved Markdown gets a table of contents by default
+func (m *Model) SetTOC(include bool) {
+	m.withTOC = include
+}
+
+// SetBundle sets the JSON written instead of the report when saving to a
+// .json file
+func (m *Model) SetBundle(bundle string) {
+	m.bundle = bundle
+}
+
+// SetBadge sets the SVG written instead of the report when saving to a .svg file
+func (m *Model) SetBadge(svg string) {
+	m.badge = svg
+}
+
+// saveReport saves the report to a file, or the badge if the file is an SVG
+func (m *Model) saveReport(filename string) error {
+	path, err := resolvePath(filename, m.outputDir)
+	if err != nil {
+		return err
+	}
+
+	content := m.report
+	switch ext := filepath.Ext(path); {
+	case strings.EqualFold(ext, ".svg"):
+		if m.badge == "" {
+			return fmt.Errorf("no metrics available for an SVG badge")
+		}
+		content = m.badge
+	case strings.EqualFold(ext, ".json"):
+		if m.bundle == "" {
+			return fmt.Errorf("no report bundle available")
+		}
+		content = m.bundle
+	default:
+		if m.withMetrics {
+			content = m.metrics + "\n---\n\n" + content
+		}
+		if m.withTOC {
+			content = WithTOC(content)
 		}
 	}
 
+	// Create parent directories if needed
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create directory: %w", err)
+	}
+
 	// Write the file
-	if err := os.WriteFile(filename, []byte(m.report), 0644); err != nil {
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 		return fmt.Errorf("could not write file: %w", err)
 	}
 
-	// Get absolute path for display
-	absPath, _ := filepath.Abs(filename)
 	m.saveError = "" // Clear any previous error
 	m.saved = true
-
-	// Store the saved path for display (optional enhancement)
-	_ = absPath
+	m.savedPath = path
 
 	return nil
 }
 
+// resolvePath expands a leading ~, places relative filenames in outputDir if
+// set, adds a .md extension if there is none, and returns the absolute path
+func resolvePath(filename, outputDir string) (string, error) {
+	relative := !filepath.IsAbs(filename) && filename != "~" && !strings.HasPrefix(filename, "~/")
+
+	filename, err := expandHome(filename)
+	if err != nil {
+		return "", err
+	}
+	if relative && outputDir != "" {
+		dir, err := expandHome(outputDir)
+		if err != nil {
+			return "", err
+		}
+		filename = filepath.Join(dir, filename)
+	}
+
+	// Make sure the filename has an extension
+	if filepath.Ext(filename) == "" {
+		filename += ".md"
+	}
+
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return "", fmt.Errorf("could not resolve path: %w", err)
+	}
+	return absPath, nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get home directory: %w", err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
 // BackMsg is sent when the user wants to go back
 type BackMsg struct{}
+
+// RegenerateMsg is sent when the user wants to generate a partial report again
+type RegenerateMsg struct{}
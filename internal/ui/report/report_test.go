@@ -0,0 +1,212 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestSaveReportRecordsAbsolutePath(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	m := New("# Report", 80, 24)
+	if err := m.saveReport("notes/weekly"); err != nil {
+		t.Fatalf("saveReport: %v", err)
+	}
+
+	want, err := filepath.Abs(filepath.Join("notes", "weekly.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.savedPath != want {
+		t.Errorf("savedPath = %q, want %q", m.savedPath, want)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("report was not written to %s: %v", want, err)
+	}
+}
+
+func TestSaveReportExpandsHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	m := New("# Report", 80, 24)
+	if err := m.saveReport("~/report.md"); err != nil {
+		t.Fatalf("saveReport: %v", err)
+	}
+	if want := filepath.Join(home, "report.md"); m.savedPath != want {
+		t.Errorf("savedPath = %q, want %q", m.savedPath, want)
+	}
+}
+
+func TestEndScrollsToBottom(t *testing.T) {
+	m := New("# Report\n\n"+strings.Repeat("- An accomplishment\n", 100), 80, 24)
+	if m.viewport.AtBottom() {
+		t.Fatal("the report fits on screen, so there is nothing to scroll")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	if !m.viewport.AtBottom() {
+		t.Errorf("End left the viewport at offset %d, not the bottom", m.viewport.YOffset)
+	}
+}
+
+func TestSaveReportInOutputDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"weekly", filepath.Join(home, "reports", "weekly.md")},
+		{"2025/march.md", filepath.Join(home, "reports", "2025", "march.md")},
+		{filepath.Join(home, "elsewhere.md"), filepath.Join(home, "elsewhere.md")},
+		{"~/notes.md", filepath.Join(home, "notes.md")},
+	}
+	for _, tt := range tests {
+		m := New("# Report", 80, 24)
+		m.SetOutputDir("~/reports")
+		if err := m.saveReport(tt.filename); err != nil {
+			t.Fatalf("saveReport(%q): %v", tt.filename, err)
+		}
+		if m.savedPath != tt.want {
+			t.Errorf("saveReport(%q) saved to %q, want %q", tt.filename, m.savedPath, tt.want)
+		}
+		if _, err := os.Stat(tt.want); err != nil {
+			t.Errorf("report was not written to %s: %v", tt.want, err)
+		}
+	}
+}
+
+func TestSaveReportWithMetrics(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	m := New("# Report\n\nShipped caching.", 80, 24)
+	m.SetMetrics("## Metrics\n\nPRs: 3 opened\n", true)
+	if err := m.saveReport("with.md"); err != nil {
+		t.Fatalf("saveReport: %v", err)
+	}
+	content, err := os.ReadFile("with.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := strings.Index(string(content), "## Metrics")
+	narrative := strings.Index(string(content), "Shipped caching.")
+	if metrics < 0 || narrative < metrics {
+		t.Errorf("the metrics section doesn't precede the narrative:\n%s", content)
+	}
+
+	// Tab in the save prompt leaves the metrics out
+	m.saveMode = true
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if err := m.saveReport("without.md"); err != nil {
+		t.Fatalf("saveReport: %v", err)
+	}
+	if content, err = os.ReadFile("without.md"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "## Metrics") {
+		t.Errorf("the metrics were saved after being toggled off:\n%s", content)
+	}
+}
+
+// typeText types s into the model one key at a time
+func typeText(m Model, s string) Model {
+	for _, r := range s {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+func TestSaveAndQuit(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctrlS := tea.KeyMsg{Type: tea.KeyCtrlS}
+
+	for _, submit := range []tea.KeyMsg{{Type: tea.KeyEnter}, ctrlS} {
+		m := New("# Report", 80, 24)
+		m, _ = m.Update(ctrlS)
+		if !m.saveMode {
+			t.Fatal("ctrl+s didn't ask for a filename")
+		}
+		m = typeText(m, "weekly.md")
+
+		m, cmd := m.Update(submit)
+		if cmd == nil || cmd() != (tea.QuitMsg{}) {
+			t.Errorf("%s after ctrl+s didn't quit", submit)
+		}
+		if content, err := os.ReadFile("weekly.md"); err != nil || string(content) != "# Report" {
+			t.Errorf("%s after ctrl+s saved %q (%v), want the report", submit, content, err)
+		}
+		os.Remove("weekly.md")
+	}
+
+	// A failed save stays open to show the error
+	if err := os.WriteFile("blocker", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := New("# Report", 80, 24)
+	m, _ = m.Update(ctrlS)
+	m = typeText(m, "blocker/report.md")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || m.saveError == "" {
+		t.Errorf("failed save returned %v with error %q, want the error shown without quitting", cmd, m.saveError)
+	}
+
+	// s saves without quitting
+	m = New("# Report", 80, 24)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = typeText(m, "stay.md")
+	if m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || !m.saved {
+		t.Errorf("s then Enter returned %v, saved = %v, want the report saved without quitting", cmd, m.saved)
+	}
+}
+
+func TestRenderToggle(t *testing.T) {
+	t.Chdir(t.TempDir())
+	const markdown = "# Report\n\nShipped **caching**."
+	render := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")}
+
+	m := New(markdown, 80, 24)
+	raw := ansi.Strip(m.viewport.View())
+	if !strings.Contains(raw, "# Report") || !strings.Contains(raw, "**caching**") {
+		t.Fatalf("raw view is missing the Markdown:\n%s", raw)
+	}
+	if !strings.Contains(m.helpText(), "m: Rendered") {
+		t.Errorf("help = %q, want m offering the rendered view", m.helpText())
+	}
+
+	m, _ = m.Update(render)
+	rendered := ansi.Strip(m.viewport.View())
+	if !strings.Contains(rendered, "Shipped caching.") || strings.Contains(rendered, "**") {
+		t.Errorf("rendered view still shows the Markdown:\n%s", rendered)
+	}
+	if !strings.Contains(m.helpText(), "m: Raw") {
+		t.Errorf("help = %q, want m offering the raw view", m.helpText())
+	}
+
+	// Saving while rendered still writes the raw Markdown
+	if err := m.saveReport("weekly.md"); err != nil {
+		t.Fatalf("saveReport: %v", err)
+	}
+	if content, err := os.ReadFile("weekly.md"); err != nil || string(content) != markdown {
+		t.Errorf("saved %q (%v), want the raw Markdown", content, err)
+	}
+
+	m, _ = m.Update(render)
+	if got := ansi.Strip(m.viewport.View()); got != raw {
+		t.Errorf("toggling back shows:\n%s\nwant the raw view:\n%s", got, raw)
+	}
+
+	// SetRendered starts in the rendered view
+	m = New(markdown, 80, 24)
+	m.SetRendered(true, true)
+	if got := ansi.Strip(m.viewport.View()); got != rendered {
+		t.Errorf("SetRendered shows:\n%s\nwant the rendered view:\n%s", got, rendered)
+	}
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tocTitle heads the table of contents inserted by WithTOC
+const tocTitle = "Table of Contents"
+
+// WithTOC inserts a table of contents linking to each ## and ### heading,
+// after the document's # title if it starts with one. Anchors follow
+// GitHub's rules, so repeated headings link to "name", "name-1", and so on.
+// Headings inside fenced code blocks are ignored.
+func WithTOC(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+
+	seen := map[string]int{}
+	anchor := func(text string) string {
+		slug := slugify(text)
+		n := seen[slug]
+		seen[slug] = n + 1
+		if n > 0 {
+			return fmt.Sprintf("%s-%d", slug, n)
+		}
+		return slug
+	}
+	// The table of contents is a heading too, so it takes its anchor first
+	anchor(tocTitle)
+
+	var entries []string
+	insertAt := 0
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		level, text := heading(trimmed)
+		if level == 0 {
+			continue
+		}
+		a := anchor(text)
+		switch {
+		case level == 1 && len(entries) == 0 && insertAt == 0:
+			insertAt = i + 1
+		case level == 2 || level == 3:
+			indent := strings.Repeat("  ", level-2)
+			entries = append(entries, fmt.Sprintf("%s- [%s](#%s)", indent, text, a))
+		}
+	}
+	if len(entries) == 0 {
+		return markdown
+	}
+
+	toc := append([]string{"", "## " + tocTitle, ""}, entries...)
+	if insertAt == len(lines) || strings.TrimSpace(lines[insertAt]) != "" {
+		toc = append(toc, "")
+	}
+	out := append(append(append([]string(nil), lines[:insertAt]...), toc...), lines[insertAt:]...)
+	return strings.TrimLeft(strings.Join(out, "\n"), "\n")
+}
+
+// heading returns the level and text of an ATX heading line like "## Title",
+// or zero if the line isn't one
+func heading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level == len(line) || line[level] != ' ' {
+		return 0, ""
+	}
+	text := strings.TrimSpace(line[level:])
+	// A closing run of #s is only part of the syntax after a space, so
+	// "## C#" keeps its #
+	if closed := strings.TrimRight(text, "#"); closed != text && (closed == "" || strings.HasSuffix(closed, " ")) {
+		text = strings.TrimSpace(closed)
+	}
+	return level, text
+}
+
+// slugify turns heading text into an anchor the way GitHub does: lowercase,
+// punctuation dropped, and spaces replaced with hyphens
+func slugify(text string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			sb.WriteRune(r)
+		case r == ' ':
+			sb.WriteRune('-')
+		}
+	}
+	return sb.String()
+}
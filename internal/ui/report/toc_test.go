@@ -0,0 +1,57 @@
+package report
+
+import "testing"
+
+func TestWithTOC(t *testing.T) {
+	markdown := `# Weekly Report
+
+## Summary
+Shipped caching.
+
+### C# & Go Work
+
+` + "```" + `
+## Not a heading
+` + "```" + `
+
+## Summary
+
+## Table of Contents`
+
+	want := `# Weekly Report
+
+## Table of Contents
+
+- [Summary](#summary)
+  - [C# & Go Work](#c--go-work)
+- [Summary](#summary-1)
+- [Table of Contents](#table-of-contents-1)
+
+## Summary
+Shipped caching.
+
+### C# & Go Work
+
+` + "```" + `
+## Not a heading
+` + "```" + `
+
+## Summary
+
+## Table of Contents`
+
+	if got := WithTOC(markdown); got != want {
+		t.Errorf("WithTOC =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWithTOCWithoutTitle(t *testing.T) {
+	got := WithTOC("## Highlights\n- Shipped caching.")
+	want := "## Table of Contents\n\n- [Highlights](#highlights)\n\n## Highlights\n- Shipped caching."
+	if got != want {
+		t.Errorf("WithTOC =\n%q\nwant\n%q", got, want)
+	}
+	if got := WithTOC("Just a paragraph."); got != "Just a paragraph." {
+		t.Errorf("WithTOC without headings = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,71 @@
+package report
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// linePrefix matches the markers that continue onto a wrapped line's
+// following lines: indentation, blockquote markers, and list markers
+var linePrefix = regexp.MustCompile(`^[ \t]*(?:>[ \t]?)*(?:(?:[-*+]|\d+[.)])[ \t]+)?`)
+
+// wrapReport soft-wraps each line of a Markdown report to width. Wrapped
+// list items continue under their text, quotes keep their > markers, and
+// lines in fenced code blocks are broken without rewrapping words. A width
+// of zero or less leaves the report unchanged.
+func wrapReport(markdown string, width int) string {
+	if width <= 0 {
+		return markdown
+	}
+
+	lines := strings.Split(markdown, "\n")
+	var out []string
+	inFence := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if ansi.StringWidth(line) <= width {
+			out = append(out, line)
+			continue
+		}
+		if inFence {
+			out = append(out, strings.Split(ansi.Hardwrap(line, width, true), "\n")...)
+			continue
+		}
+
+		prefix := linePrefix.FindString(line)
+		continuation := continuationPrefix(prefix)
+		// Too deep to indent, so wrap the whole line flush left
+		if ansi.StringWidth(prefix) >= width/2 {
+			prefix, continuation = "", ""
+		}
+		wrapped := strings.Split(ansi.Wrap(line[len(prefix):], width-ansi.StringWidth(prefix), ""), "\n")
+		for i, w := range wrapped {
+			lead := continuation
+			if i == 0 {
+				lead = prefix
+			}
+			out = append(out, strings.TrimRight(lead+w, " "))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// continuationPrefix returns what starts the lines a prefixed line wraps
+// onto: its quote markers, with list markers turned into matching spaces
+func continuationPrefix(prefix string) string {
+	var sb strings.Builder
+	for _, r := range prefix {
+		switch r {
+		case '>', ' ', '\t':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(' ')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,90 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// longText returns n characters of words
+func longText(n int) string {
+	return strings.Repeat("word ", n/5)[:n-1] + "."
+}
+
+func TestWrapReport(t *testing.T) {
+	long := longText(500)
+	tests := []struct {
+		name   string
+		line   string
+		prefix string
+		next   string
+	}{
+		{"paragraph", long, "", ""},
+		{"list item", "- " + long, "- ", "  "},
+		{"numbered item", "  12. " + long, "  12. ", "      "},
+		{"quote", "> " + long, "> ", "> "},
+	}
+	for _, tt := range tests {
+		lines := strings.Split(wrapReport(tt.line, 76), "\n")
+		if len(lines) < 500/76 {
+			t.Errorf("%s: wrapped to %d lines, want at least %d", tt.name, len(lines), 500/76)
+		}
+		var words []string
+		for i, line := range lines {
+			if w := ansi.StringWidth(line); w > 76 {
+				t.Errorf("%s: line %d is %d wide, want at most 76: %q", tt.name, i, w, line)
+			}
+			lead := tt.next
+			if i == 0 {
+				lead = tt.prefix
+			}
+			if !strings.HasPrefix(line, lead) {
+				t.Errorf("%s: line %d = %q, want it to start with %q", tt.name, i, line, lead)
+			}
+			words = append(words, strings.Fields(strings.TrimPrefix(line, lead))...)
+		}
+		if got := strings.Join(words, " "); got != long {
+			t.Errorf("%s: wrapping changed the text to %q", tt.name, got)
+		}
+	}
+}
+
+func TestWrapReportCodeBlock(t *testing.T) {
+	code := strings.Repeat("x", 500)
+	lines := strings.Split(wrapReport("```\n"+code+"\n```", 76), "\n")
+	if lines[0] != "```" || lines[len(lines)-1] != "```" {
+		t.Fatalf("fences were changed: %q", lines)
+	}
+	if got := strings.Join(lines[1:len(lines)-1], ""); got != code {
+		t.Errorf("code was rewrapped rather than broken: %q", lines)
+	}
+	for i, line := range lines {
+		if len(line) > 76 {
+			t.Errorf("line %d is %d wide, want at most 76", i, len(line))
+		}
+	}
+}
+
+func TestWrapReportUnchanged(t *testing.T) {
+	short := "# Report\n\n- Shipped caching"
+	if got := wrapReport(short, 76); got != short {
+		t.Errorf("wrapReport of short lines = %q, want them unchanged", got)
+	}
+	long := longText(500)
+	if got := wrapReport(long, 0); got != long {
+		t.Error("wrapReport with no width changed the report")
+	}
+}
+
+func TestReportViewWrapsLongLines(t *testing.T) {
+	m := New("# Report\n\n"+longText(500), 80, 40)
+	for i, line := range strings.Split(m.viewport.View(), "\n") {
+		if w := ansi.StringWidth(line); w > 80 {
+			t.Errorf("viewport line %d is %d wide, want at most 80", i, w)
+		}
+	}
+	if !strings.Contains(ansi.Strip(m.viewport.View()), "word.") {
+		t.Errorf("the end of the long line is cut off:\n%s", m.viewport.View())
+	}
+}
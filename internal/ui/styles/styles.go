@@ -48,6 +48,10 @@ var (
 			Bold(true).
 			Padding(1, 2)
 
+	// WarningStyle is used for non-fatal warnings
+	WarningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")) // Amber
+
 	// SubtleStyle is used for less important text
 	SubtleStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("241"))
@@ -1,46 +1,395 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/burritocatai/activitycat/internal/app"
 	"github.com/burritocatai/activitycat/internal/config"
+	"github.com/burritocatai/activitycat/internal/daterange"
 	"github.com/burritocatai/activitycat/internal/github"
+	"github.com/burritocatai/activitycat/internal/keys"
 	"github.com/burritocatai/activitycat/internal/llm"
+	"golang.org/x/term"
 )
 
 func main() {
-	// Check prerequisites before starting TUI
-	if err := github.CheckAuth(); err != nil {
-		fmt.Fprintf(os.Stderr, "GitHub authentication error: %v\n", err)
-		fmt.Fprintf(os.Stderr, "\nPlease install and authenticate the GitHub CLI:\n")
-		fmt.Fprintf(os.Stderr, "  1. Install: https://cli.github.com/\n")
-		fmt.Fprintf(os.Stderr, "  2. Authenticate: gh auth login\n")
-		os.Exit(1)
+	promptName := flag.String("prompt", "", "name of a prompt to pre-select")
+	promptFile := flag.String("prompt-file", "", "path to a prompt file to use (overrides --prompt)")
+	rangeSpec := flag.String("range", "", "date range: last-week, last-month, last-3-months, 30d, or YYYY-MM-DD..YYYY-MM-DD (default: default_range from config, or last-week)")
+	var skipDate bool
+	flag.BoolVar(&skipDate, "yes", false, "skip date selection and fetch the --range or default_range immediately")
+	flag.BoolVar(&skipDate, "no-prompt", false, "alias for --yes")
+	exportJSONL := flag.String("export-jsonl", "", "export activity as JSON Lines to a file (\"-\" for stdout) and exit")
+	importFile := flag.String("import", "", "load activity from a JSON Lines export instead of fetching from GitHub")
+	anonymize := flag.Bool("anonymize", false, "replace repository names and logins with placeholders like repo-1 and user-1")
+	team := flag.String("team", "", "fetch the activity of every member of a GitHub team, given as org/team (overrides team in config)")
+	members := flag.String("members", "", "comma-separated logins to fetch and merge activity for (overrides team_members in config)")
+	watch := flag.Duration("watch", 0, "re-fetch and show only the analytics every interval, e.g. 5m, with no LLM")
+	modelName := flag.String("model", "", "logical model name from the [models] table to generate with, e.g. fast")
+	summary := flag.Bool("summary", false, "use Metrics Only, which reports the computed metrics without calling an LLM, as the only prompt")
+	stdinPrompt := flag.Bool("stdin-prompt", false, "read a one-off prompt from stdin (overrides --prompt-file and --prompt)")
+	toStdout := flag.Bool("stdout", false, "generate the report without the TUI and print it to stdout")
+	output := flag.String("output", "", "generate the report without the TUI and write it to a file")
+	confirm := flag.Bool("confirm", false, "with --stdout or --output, ask on the terminal before generating a report estimated to cost more than confirm_cost_above")
+	serve := flag.String("serve", "", "serve the report as HTML, and its metrics at /metrics.json, on an address like :8080 instead of the TUI")
+	flag.Parse()
+
+	// Read the piped prompt before anything else can touch stdin
+	var piped *config.Prompt
+	if *stdinPrompt {
+		p, err := readStdinPrompt()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Prompt error: %v\n", err)
+			os.Exit(1)
+		}
+		piped = &p
+	}
+	headless := *toStdout || *output != "" || *serve != ""
+
+	// Check prerequisites before starting TUI. Imported activity needs no GitHub access.
+	if *importFile == "" {
+		if err := github.CheckAuth(); err != nil {
+			fmt.Fprintf(os.Stderr, "GitHub authentication error: %v\n", err)
+			var authErr *github.ErrAuthFailed
+			if errors.As(err, &authErr) && !authErr.NotInstalled {
+				fmt.Fprintf(os.Stderr, "\nPlease authenticate the GitHub CLI: gh auth login\n")
+			} else {
+				fmt.Fprintf(os.Stderr, "\nPlease install and authenticate the GitHub CLI:\n")
+				fmt.Fprintf(os.Stderr, "  1. Install: https://cli.github.com/\n")
+				fmt.Fprintf(os.Stderr, "  2. Authenticate: gh auth login\n")
+			}
+			os.Exit(1)
+		}
 	}
 
 	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Config error: %v\n", err)
+		os.Exit(1)
+	}
+	if *members != "" {
+		cfg.TeamMembers = splitLogins(*members)
+	} else if *team != "" {
+		cfg.TeamMembers, cfg.Team = nil, *team
+	}
+	if len(cfg.TeamMembers) == 0 && cfg.Team != "" && *importFile == "" {
+		logins, err := github.FetchTeamMembers(context.Background(), cfg.Team)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Team error: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.TeamMembers = logins
+	}
+	if *modelName != "" {
+		selected, err := cfg.SelectModel(*modelName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Model error: %v\n", err)
+			os.Exit(1)
+		}
+		cfg = selected
+	}
+	cfg = cfg.ResolveModel()
+	if err := keys.Load(cfg.Keys); err != nil {
+		fmt.Fprintf(os.Stderr, "Config error: invalid keys: %v\n", err)
+		os.Exit(1)
+	}
+
+	explicitRange := *rangeSpec != ""
+
+	// An explicit --range wins over the configured default
+	if *rangeSpec == "" {
+		*rangeSpec = cfg.DefaultRange
+	}
+	if *rangeSpec == "" {
+		*rangeSpec = "last-week"
+	}
+
+	if *exportJSONL != "" {
+		if err := runExport(cfg, *rangeSpec, *exportJSONL, *anonymize); err != nil {
+			fmt.Fprintf(os.Stderr, "Export error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The TUI needs a terminal to draw on, so point scripts and CI at the
+	// non-interactive flags instead
+	if !headless && !isTerminal(os.Stdout) {
+		fmt.Fprintf(os.Stderr, "activitycat needs a terminal for its interactive UI, but stdout is not one.\n")
+		fmt.Fprintf(os.Stderr, "\nFor scripts and CI, generate the report without the UI:\n")
+		fmt.Fprintf(os.Stderr, "  activitycat --range last-week --stdout\n")
+		fmt.Fprintf(os.Stderr, "  activitycat --range last-week --output report.md\n")
+		os.Exit(1)
+	}
+
+	if *watch != 0 {
+		if *watch < app.MinWatchInterval {
+			fmt.Fprintf(os.Stderr, "Watch error: interval must be at least %s\n", app.MinWatchInterval)
+			os.Exit(1)
+		}
+		if _, err := daterange.ParseSpec(*rangeSpec); err != nil {
+			fmt.Fprintf(os.Stderr, "Range error: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := tea.NewProgram(app.NewWatch(cfg, *rangeSpec, *watch, *anonymize), tea.WithAltScreen()).Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running application: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	if cfg.Provider == "claude" {
-		if err := llm.CheckAPIKey(); err != nil {
+	// Metrics Only never calls the provider, so it needs no key or model
+	if cfg.Provider == "claude" && !*summary {
+		if err := llm.CheckAPIKey(cfg.AnthropicAPIKeyFile); err != nil {
 			fmt.Fprintf(os.Stderr, "Claude API error: %v\n", err)
 			fmt.Fprintf(os.Stderr, "\nPlease set your Anthropic API key:\n")
 			fmt.Fprintf(os.Stderr, "  export ANTHROPIC_API_KEY=your_key_here\n")
+			fmt.Fprintf(os.Stderr, "\nor point anthropic_api_key_file in the config (or ANTHROPIC_API_KEY_FILE) at a file holding it\n")
 			fmt.Fprintf(os.Stderr, "\nGet your API key at: https://console.anthropic.com/\n")
 			os.Exit(1)
 		}
 	}
 
-	// Start the TUI application
-	p := tea.NewProgram(
-		app.New(cfg),
-		tea.WithAltScreen(),
-	)
+	if !*summary {
+		if err := llm.ValidateModel(context.Background(), cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Model error: %v\n", err)
+			configFile := "config.toml"
+			if dir, err := config.ConfigDir(); err == nil {
+				configFile = filepath.Join(dir, configFile)
+			}
+			fmt.Fprintf(os.Stderr, "\nCheck the model setting in %s, or ACTIVITYCAT_MODEL\n", configFile)
+			os.Exit(1)
+		}
+	}
+
+	opts := app.Options{Anonymize: *anonymize}
+
+	// Prompt files sharing a name are reported before the list is shown. The
+	// prompts are kept for --prompt-name and the headless default prompt.
+	var prompts []config.Prompt
+	prompts, opts.Warnings = config.LoadPrompts()
+
+	if *importFile == "" {
+		if warning := github.CheckVersion(); warning != "" {
+			opts.Warnings = append(opts.Warnings, warning)
+		}
+	}
+
+	if skipDate || headless || *importFile != "" {
+		r, err := daterange.ParseSpec(*rangeSpec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Range error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Range = &r
+	}
+
+	if *importFile != "" {
+		activity, span, err := app.ImportJSONLines(*importFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Import error: %v\n", err)
+			os.Exit(1)
+		}
+		// Without an explicit --range, use the span of the imported activity
+		if !explicitRange {
+			opts.Range = &span
+		}
+		github.SortActivity(&activity, cfg.ActivityOrder == "oldest")
+		opts.Activity = &activity
+	}
+
+	// --summary wins, then a piped prompt, then a prompt file over a named prompt
+	switch {
+	case *summary:
+		opts.Prompt = &config.MetricsOnlyPrompt
+	case piped != nil:
+		opts.Prompt = piped
+	case *promptFile != "":
+		p, err := config.LoadPromptFile(*promptFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Prompt error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Prompt = &p
+	case *promptName != "":
+		p, err := config.FindPrompt(prompts, *promptName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Prompt error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Prompt = &p
+	}
+
+	if *serve != "" {
+		// The span of imported activity stays fixed, while a range spec is
+		// parsed again for each report
+		spec := *rangeSpec
+		if *importFile != "" && !explicitRange {
+			spec = ""
+		}
+		if err := runServe(cfg, opts, prompts, spec, *serve); err != nil {
+			fmt.Fprintf(os.Stderr, "Serve error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if headless {
+		if *confirm {
+			opts.ConfirmCost = askToGenerate
+		}
+		if err := runHeadless(cfg, opts, prompts, *toStdout, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Start the TUI application. With the prompt piped in, keyboard input
+	// comes from the terminal instead of stdin.
+	programOpts := []tea.ProgramOption{tea.WithAltScreen()}
+	if piped != nil {
+		programOpts = append(programOpts, tea.WithInputTTY())
+	}
+	p := tea.NewProgram(app.New(cfg, opts), programOpts...)
 
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running application: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// splitLogins splits a comma-separated list of logins, dropping blanks and
+// any leading @
+func splitLogins(list string) []string {
+	var logins []string
+	for _, login := range strings.Split(list, ",") {
+		if login = strings.TrimPrefix(strings.TrimSpace(login), "@"); login != "" {
+			logins = append(logins, login)
+		}
+	}
+	return logins
+}
+
+// readStdinPrompt reads a one-off prompt piped to stdin
+func readStdinPrompt() (config.Prompt, error) {
+	if isTerminal(os.Stdin) {
+		return config.Prompt{}, fmt.Errorf("--stdin-prompt needs a prompt piped to stdin, e.g. echo \"Summarize...\" | activitycat --stdin-prompt")
+	}
+	return config.ReadPrompt(os.Stdin, "stdin")
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe, file, or
+// other device like /dev/null
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// runHeadless generates a report without the TUI, using the first of prompts
+// unless one was chosen, and prints it to stdout and/or writes it to output
+func runHeadless(cfg config.Config, opts app.Options, prompts []config.Prompt, toStdout bool, output string) error {
+	opts.Prompt = headlessPrompt(opts, prompts)
+
+	report, warnings, err := app.GenerateReport(cfg, opts)
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	if err != nil {
+		return err
+	}
+
+	if output != "" {
+		if err := os.WriteFile(output, []byte(report), 0644); err != nil {
+			return fmt.Errorf("could not write report: %w", err)
+		}
+	}
+	if toStdout {
+		fmt.Println(report)
+	}
+	return nil
+}
+
+// askToGenerate asks on the terminal whether to generate a report estimated
+// to cost cost. The answer is read from the terminal itself, as stdin may
+// hold a piped prompt, and no terminal means no.
+func askToGenerate(cost float64) bool {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+	defer tty.Close()
+
+	fmt.Fprintf(os.Stderr, "This report is estimated to cost ~%s. Generate it? [y/N] ", llm.FormatCost(cost))
+	answer, _ := bufio.NewReader(tty).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// headlessPrompt returns the chosen prompt, or the first of prompts if none
+// was chosen
+func headlessPrompt(opts app.Options, prompts []config.Prompt) *config.Prompt {
+	if opts.Prompt != nil {
+		return opts.Prompt
+	}
+	return &prompts[0]
+}
+
+// runServe serves the report for rangeSpec, or opts.Range if it is empty,
+// generated with the chosen prompt or the first of prompts, on addr until the
+// server fails
+func runServe(cfg config.Config, opts app.Options, prompts []config.Prompt, rangeSpec, addr string) error {
+	opts.Prompt = headlessPrompt(opts, prompts)
+	fmt.Fprintf(os.Stderr, "Serving the report on %s (add ?refresh=1 to regenerate it, Ctrl+C to stop)\n", addr)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           app.NewServer(cfg, opts, rangeSpec),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
+
+// runExport fetches activity for the range and writes it as JSON Lines to path
+func runExport(cfg config.Config, rangeSpec, path string, anonymize bool) error {
+	r, err := daterange.ParseSpec(rangeSpec)
+	if err != nil {
+		return err
+	}
+
+	if path == "-" {
+		return app.ExportJSONLines(cfg, r, os.Stdout, anonymize)
+	}
+
+	// Write beside the destination and rename once the fetch succeeded, so
+	// a failed export never leaves a partial file behind
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("could not create export file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	// CreateTemp makes the file private, unlike os.Create
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		return fmt.Errorf("could not create export file: %w", err)
+	}
+	if err := app.ExportJSONLines(cfg, r, f, anonymize); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not write export file: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("could not write export file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNoTerminalGuidance(t *testing.T) {
+	// Run as the program itself when re-executed below
+	if args := os.Getenv("ACTIVITYCAT_TEST_ARGS"); args != "" {
+		os.Args = append([]string{"activitycat"}, strings.Fields(args)...)
+		main()
+		return
+	}
+
+	dir := t.TempDir()
+	activity := filepath.Join(dir, "activity.jsonl")
+	if err := os.WriteFile(activity, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestNoTerminalGuidance$")
+	// Imported activity needs no gh. Stdout is left as /dev/null, a device
+	// but not a terminal.
+	cmd.Env = append(os.Environ(), "ACTIVITYCAT_TEST_ARGS=--import "+activity, "XDG_CONFIG_HOME="+dir)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("activitycat without a terminal: error = %v, want exit status 1\n%s", err, stderr.String())
+	}
+	for _, want := range []string{"needs a terminal", "--stdout", "--output report.md"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("guidance is missing %q:\n%s", want, stderr.String())
+		}
+	}
+}